@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	demoAdminEmail    = "admin@example.com"
+	demoAdminPassword = "admin"
+
+	demoStudentEmail    = "student@example.com"
+	demoStudentPassword = "student"
+)
+
+// seedDemo fills empty repositories with a small catalog and an admin
+// account so the memory storage is usable right away.
+func seedDemo(ctx context.Context, courseRepo repository.CourseRepository, userRepo repository.UserRepository) error {
+	programming, err := courseRepo.StoreCategory(ctx, model.Category{Name: "Programming"})
+	if err != nil {
+		return err
+	}
+
+	golang, err := courseRepo.StoreCategory(ctx, model.Category{Name: "Go", ParentId: programming})
+	if err != nil {
+		return err
+	}
+
+	design, err := courseRepo.StoreCategory(ctx, model.Category{Name: "Design"})
+	if err != nil {
+		return err
+	}
+
+	courseIDs := []int{}
+
+	for _, course := range []model.Course{
+		{CategoryId: golang, Name: "Go Fundamentals", Price: 0},
+		{CategoryId: golang, Name: "Concurrency in Go", Price: 150000},
+		{CategoryId: programming, Name: "Clean Architecture", Price: 200000},
+		{CategoryId: design, Name: "UI Design Basics", Price: 0},
+	} {
+		id, err := courseRepo.Store(ctx, course)
+		if err != nil {
+			return err
+		}
+
+		courseIDs = append(courseIDs, id)
+	}
+
+	if err := seedCurriculum(ctx, courseRepo, courseIDs[0]); err != nil {
+		return err
+	}
+
+	for _, user := range []model.User{
+		{Name: "Admin", Email: demoAdminEmail, Password: demoAdminPassword, Role: constant.RoleAdmin},
+		{Name: "Student", Email: demoStudentEmail, Password: demoStudentPassword, Role: 2},
+	} {
+		if err := userRepo.Store(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("demo data loaded, log in as %s / %s or %s / %s", demoAdminEmail, demoAdminPassword, demoStudentEmail, demoStudentPassword)
+
+	return courseRepo.RecomputeCount(ctx)
+}
+
+// seedCurriculum gives the course two sections, the first lesson being a
+// free preview.
+func seedCurriculum(ctx context.Context, courseRepo repository.CourseRepository, courseID int) error {
+	curriculum := []struct {
+		title   string
+		lessons []model.Lesson
+	}{
+		{"Getting started", []model.Lesson{
+			{Title: "Welcome", Type: constant.LessonVideo, Content: "https://example.com/video/welcome.mp4", Duration: 120, FreePreview: true},
+			{Title: "Installing Go", Type: constant.LessonText, Content: "# Installing Go\n\nDownload Go from https://go.dev/dl/."},
+		}},
+		{"Language basics", []model.Lesson{
+			{Title: "A Tour of Go", Type: constant.LessonLink, Content: "https://go.dev/tour/"},
+			{Title: "Cheat sheet", Type: constant.LessonFile, Content: "https://example.com/files/go-cheat-sheet.pdf"},
+		}},
+	}
+
+	for _, s := range curriculum {
+		sectionID, err := courseRepo.StoreSection(ctx, model.Section{CourseId: courseID, Title: s.title})
+		if err != nil {
+			return err
+		}
+
+		for _, l := range s.lessons {
+			l.SectionId = sectionID
+			if _, err := courseRepo.StoreLesson(ctx, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+type closer struct {
+	name string
+	fn   func() error
+}
+
+// lifecycle runs the HTTP server and the background workers until SIGINT or
+// SIGTERM, then stops them in order: the server drains in-flight requests
+// within the shutdown timeout, the workers are cancelled and awaited, and
+// the closers run in reverse registration order.
+type lifecycle struct {
+	server          *echo.Echo
+	address         string
+	shutdownTimeout time.Duration
+
+	workers []func(context.Context)
+	closers []closer
+}
+
+func newLifecycle(server *echo.Echo, address string, shutdownTimeout time.Duration) *lifecycle {
+	return &lifecycle{
+		server:          server,
+		address:         address,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+// Go registers a worker. Its context is cancelled on shutdown, after the
+// server drained, and the worker must return then.
+func (l *lifecycle) Go(worker func(context.Context)) {
+	l.workers = append(l.workers, worker)
+}
+
+// OnClose registers fn to run after the workers stopped, e.g. closing the
+// database.
+func (l *lifecycle) OnClose(name string, fn func() error) {
+	l.closers = append(l.closers, closer{name: name, fn: fn})
+}
+
+// Run blocks until a signal arrives or the server fails, and returns the
+// first error met while serving or shutting down.
+func (l *lifecycle) Run() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+
+	for _, worker := range l.workers {
+		workers.Add(1)
+
+		go func(worker func(context.Context)) {
+			defer workers.Done()
+			worker(workerCtx)
+		}(worker)
+	}
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- l.server.Start(l.address)
+	}()
+
+	var result error
+
+	select {
+	case sig := <-signals:
+		log.Infof("received %s, shutting down", sig)
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			result = err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	if err := l.server.Shutdown(ctx); err != nil {
+		log.Errorf("drain http server: %v", err)
+
+		if result == nil {
+			result = err
+		}
+	}
+
+	stopWorkers()
+
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Error("background workers did not stop within the shutdown timeout")
+	}
+
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		if err := l.closers[i].fn(); err != nil {
+			log.Errorf("close %s: %v", l.closers[i].name, err)
+
+			if result == nil {
+				result = err
+			}
+		}
+	}
+
+	log.Info("shutdown complete")
+
+	return result
+}
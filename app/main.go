@@ -1,50 +1,255 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
+	"github.com/egaevan/online-learning/certificate"
 	"github.com/egaevan/online-learning/config"
+	"github.com/egaevan/online-learning/constant"
 	"github.com/egaevan/online-learning/delivery/rest"
+	"github.com/egaevan/online-learning/migration"
+	"github.com/egaevan/online-learning/payment"
 	"github.com/egaevan/online-learning/repository"
+	"github.com/egaevan/online-learning/repository/memory"
+	"github.com/egaevan/online-learning/storage"
 	"github.com/egaevan/online-learning/usecase"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	storageSQL    = "sql"
+	storageMemory = "memory"
+)
+
 func main() {
 	// Init config
-	cfg, err := config.GetConfig()
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err := config.SetupLog(cfg.Log); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 && args[0] == "config" {
+		dump, err := config.Dump(cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(dump))
+
+		return
+	}
+
 	// Init echo framework
 	e := echo.New()
+	e.Server.ReadTimeout = time.Duration(cfg.HTTP.ReadTimeout)
+	e.Server.WriteTimeout = time.Duration(cfg.HTTP.WriteTimeout)
 
-	// Init DB
-	mysqlInfo := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", cfg.Database.User, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+	// Init lifecycle
+	app := newLifecycle(e, cfg.HTTP.Address, time.Duration(cfg.HTTP.ShutdownTimeout))
 
-	db, err := sql.Open("mysql", mysqlInfo)
+	// Init tracing
+	shutdownTracing, err := config.SetupTracing(context.Background(), cfg.Tracing)
 	if err != nil {
-		fmt.Println("Error connect to db")
 		log.Fatal(err)
 	}
 
-	defer db.Close()
+	app.OnClose("tracing", func() error {
+		return shutdownTracing(context.Background())
+	})
+	app.Go(func(ctx context.Context) {
+		reloadSecrets(ctx, cfg)
+	})
+
+	var (
+		courseRepo     repository.CourseRepository
+		userRepo       repository.UserRepository
+		enrollmentRepo repository.EnrollmentRepository
+		progressRepo   repository.ProgressRepository
+		quizRepo       repository.QuizRepository
+		assignmentRepo repository.AssignmentRepository
+		notifyRepo     repository.NotificationRepository
+		certRepo       repository.CertificateRepository
+		reviewRepo     repository.ReviewRepository
+		cartRepo       repository.CartRepository
+		orderRepo      repository.OrderRepository
+		paymentRepo    repository.PaymentRepository
+		couponRepo     repository.CouponRepository
+		statsRepo      repository.StatsRepository
+		transactor     repository.Transactor
+		// checks are the readiness checks of the storage.
+		checks = map[string]usecase.HealthChecker{}
+	)
+
+	switch cfg.Feature.Storage {
+	case storageMemory:
+		if len(args) > 0 && args[0] == "migrate" {
+			log.Fatal("the memory storage has no migrations")
+		}
+
+		// Init repository
+		store := memory.NewStore()
+		courseRepo = memory.NewCourseRepository(store)
+		userRepo = memory.NewUserRepository(store)
+		enrollmentRepo = memory.NewEnrollmentRepository(store)
+		progressRepo = memory.NewProgressRepository(store)
+		quizRepo = memory.NewQuizRepository(store)
+		assignmentRepo = memory.NewAssignmentRepository(store)
+		notifyRepo = memory.NewNotificationRepository(store)
+		certRepo = memory.NewCertificateRepository(store)
+		reviewRepo = memory.NewReviewRepository(store)
+		cartRepo = memory.NewCartRepository(store)
+		orderRepo = memory.NewOrderRepository(store)
+		paymentRepo = memory.NewPaymentRepository(store)
+		couponRepo = memory.NewCouponRepository(store)
+		statsRepo = memory.NewStatsRepository(store)
+		transactor = memory.NewTransactor(store)
+
+		if err := seedDemo(context.Background(), courseRepo, userRepo); err != nil {
+			log.Fatal(err)
+		}
+	case storageSQL:
+		// Init DB
+		db, err := config.GetDatabase(cfg.Database)
+		if err != nil {
+			log.Fatalf("connect to db: %v", err)
+		}
+
+		app.OnClose("database", db.Close)
+
+		err = db.PingRetry(context.Background(), cfg.Database.ConnectAttempts, time.Duration(cfg.Database.ConnectBackoff), time.Duration(cfg.Database.ConnectMaxBackoff), func(attempt int, err error) {
+			log.Printf("database not ready (attempt %d/%d): %v", attempt, cfg.Database.ConnectAttempts, err)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Init migration
+		migrator, err := migration.NewMigrator(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(args) > 0 && args[0] == "migrate" {
+			err := runMigrate(context.Background(), migrator, args[1:])
+			db.Close()
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			return
+		}
+
+		if err := migrator.Init(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := migrator.Check(context.Background()); err != nil {
+			log.Fatal(err)
+		}
 
-	// Init repository
-	courseRepo := repository.NewCourseRepository(db)
-	userRepo := repository.NewUserRepository(db)
+		checks["database"] = usecase.HealthCheckerFunc(db.PingContext)
+		checks["migrations"] = usecase.HealthCheckerFunc(migrator.Check)
+
+		// Migrations above may run long, the query timeout is for requests.
+		db.QueryTimeout = time.Duration(cfg.Database.QueryTimeout)
+
+		// Init repository
+		courseRepo = repository.NewCourseRepository(db)
+		statsRepo = repository.NewStatsRepository(db)
+		repository.RegisterPoolMetrics(db)
+		userRepo = repository.NewUserRepository(db)
+		enrollmentRepo = repository.NewEnrollmentRepository(db)
+		progressRepo = repository.NewProgressRepository(db)
+		quizRepo = repository.NewQuizRepository(db)
+		assignmentRepo = repository.NewAssignmentRepository(db)
+		notifyRepo = repository.NewNotificationRepository(db)
+		certRepo = repository.NewCertificateRepository(db)
+		reviewRepo = repository.NewReviewRepository(db)
+		cartRepo = repository.NewCartRepository(db)
+		orderRepo = repository.NewOrderRepository(db)
+		paymentRepo = repository.NewPaymentRepository(db)
+		couponRepo = repository.NewCouponRepository(db)
+		transactor = repository.NewTransactor(db, cfg.Database.TransactionRetry, constant.TransactionRetryDelay)
+	}
+
+	// Init file storage
+	files, err := storage.NewLocal(cfg.Upload.Directory)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Init certificate template
+	template := certificate.Default()
+	if cfg.Certificate.Template != "" {
+		template, err = certificate.Load(cfg.Certificate.Template)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Init payment gateway
+	gateway, err := payment.NewFake(cfg.Payment.WebhookSecret.Value())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gateway.AutoConfirm = cfg.Payment.AutoConfirm
+
+	// Registered after the database, so webhook events still being
+	// delivered are applied before it closes.
+	app.OnClose("payment", func() error {
+		gateway.Wait()
+		return nil
+	})
 
 	// Init usecase
-	courseUsecae := usecase.NewCourse(courseRepo)
-	userUsecae := usecase.NewUser(userRepo)
+	courseUsecae := usecase.NewCourse(courseRepo, enrollmentRepo, reviewRepo, transactor)
+	userUsecae := usecase.NewUser(userRepo, cfg.JWT)
+	// Paid courses are enrolled in once an order with them is paid.
+	orderUsecae := usecase.NewOrder(cartRepo, orderRepo, paymentRepo, couponRepo, courseRepo, enrollmentRepo, transactor, gateway, cfg.Commerce.Currency)
+	enrollmentUsecae := usecase.NewEnrollment(enrollmentRepo, progressRepo, courseRepo, transactor, orderUsecae)
+	quizUsecae := usecase.NewQuiz(quizRepo, courseRepo, enrollmentRepo, transactor, enrollmentUsecae)
+	notificationUsecae := usecase.NewNotification(notifyRepo)
+	assignmentUsecae := usecase.NewAssignment(assignmentRepo, courseRepo, enrollmentRepo, transactor, files, cfg.Upload.MaxSize, enrollmentUsecae, notificationUsecae)
+	certificateUsecae := usecase.NewCertificate(certRepo, userRepo, courseRepo, enrollmentRepo, files, certificate.NewRenderer(template, cfg.Certificate.BaseURL), notificationUsecae)
+	reviewUsecae := usecase.NewReview(reviewRepo, courseRepo, enrollmentRepo, transactor, notificationUsecae)
+	couponUsecae := usecase.NewCoupon(couponRepo, courseRepo, transactor)
+	monitorUsecae := usecase.NewMonitor(statsRepo)
+
+	enrollmentUsecae.OnCourseCompleted(certificateUsecae.CourseCompleted)
+
+	// The fake gateway calls the payment webhook in process.
+	gateway.Deliver = orderUsecae.HandleWebhook
+
+	for name, checker := range checks {
+		monitorUsecae.RegisterCheck(name, checker)
+	}
+
+	// Init background job
+	app.Go(func(ctx context.Context) {
+		courseUsecae.RunCountRecompute(ctx, time.Duration(cfg.Feature.CountRecomputeInterval))
+	})
 
 	// Init handler
-	rest.NewHandler(e, courseUsecae, userUsecae)
+	rest.NewHandler(e, courseUsecae, userUsecae, enrollmentUsecae, quizUsecae, assignmentUsecae, notificationUsecae, certificateUsecae, reviewUsecae, orderUsecae, couponUsecae, monitorUsecae, cfg)
 
-	e.Logger.Fatal(e.Start(":8080"))
+	if err := app.Run(); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/egaevan/online-learning/config"
+	"github.com/egaevan/online-learning/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// reloadSecrets resolves the secret references of cfg again on every SIGHUP
+// until ctx ends. New database connections and JWT signing use the new
+// values right away.
+func reloadSecrets(ctx context.Context, cfg *model.Config) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		if err := config.ResolveSecrets(ctx, cfg); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		log.Info("secrets reloaded")
+	}
+}
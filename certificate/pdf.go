@@ -0,0 +1,187 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Fonts are the standard PDF fonts every viewer has, so none is embedded.
+// Oblique faces share the widths of their upright face.
+const (
+	Helvetica            = "Helvetica"
+	HelveticaBold        = "Helvetica-Bold"
+	HelveticaOblique     = "Helvetica-Oblique"
+	HelveticaBoldOblique = "Helvetica-BoldOblique"
+)
+
+var fonts = []string{Helvetica, HelveticaBold, HelveticaOblique, HelveticaBoldOblique}
+
+// Advance widths of the printable ASCII characters, space to tilde, in
+// thousandths of the font size. From the Adobe font metrics.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// textWidth returns the width of s set in font at size, in points. Letters
+// outside ASCII are taken as wide as a digit.
+func textWidth(font string, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == HelveticaBold || font == HelveticaBoldOblique {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			total += widths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+
+	return float64(total) * size / 1000
+}
+
+// winAnsiPunctuation are the letters outside Latin-1 that WinAnsiEncoding
+// has, typographic punctuation mostly.
+var winAnsiPunctuation = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// winAnsi encodes s for a font with the WinAnsiEncoding, which matches
+// Latin-1 apart from some punctuation. Other letters become a question
+// mark.
+func winAnsi(s string) []byte {
+	result := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		switch {
+		case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+			result = append(result, byte(r))
+		case winAnsiPunctuation[r] != 0:
+			result = append(result, winAnsiPunctuation[r])
+		default:
+			result = append(result, '?')
+		}
+	}
+
+	return result
+}
+
+// pdfString writes b as a PDF literal string.
+func pdfString(b []byte) string {
+	var sb strings.Builder
+
+	sb.WriteByte('(')
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			if c < ' ' || c > '~' {
+				fmt.Fprintf(&sb, "\\%03o", c)
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	sb.WriteByte(')')
+
+	return sb.String()
+}
+
+// number formats f to a thousandth of a point, more than any printer can
+// tell apart.
+func number(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1000)/1000, 'f', -1, 64)
+}
+
+// page is a single PDF page drawn with text and rectangles. Coordinates
+// are in points from the bottom left corner.
+type page struct {
+	width, height float64
+	content       bytes.Buffer
+}
+
+func (p *page) rect(x, y, width, height, lineWidth float64, color [3]float64) {
+	fmt.Fprintf(&p.content, "%s %s %s RG %s w %s %s %s %s re S\n",
+		number(color[0]), number(color[1]), number(color[2]), number(lineWidth),
+		number(x), number(y), number(width), number(height))
+}
+
+func (p *page) text(x, y float64, font string, size float64, color [3]float64, s string) {
+	index := 0
+	for i, f := range fonts {
+		if f == font {
+			index = i
+		}
+	}
+
+	fmt.Fprintf(&p.content, "BT %s %s %s rg /F%d %s Tf %s %s Td %s Tj ET\n",
+		number(color[0]), number(color[1]), number(color[2]), index+1, number(size),
+		number(x), number(y), pdfString(winAnsi(s)))
+}
+
+// writeTo writes the page as a complete PDF document. The output only
+// depends on what was drawn, so the same page gives the same bytes.
+func (p *page) writeTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// A binary comment line tells transfer tools the file is not text.
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+
+	// Fonts follow the page, the content stream follows the fonts.
+	var resources strings.Builder
+	for i := range fonts {
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, i+4)
+	}
+
+	object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font <<%s >> >> /Contents %d 0 R >>",
+		number(p.width), number(p.height), resources.String(), len(fonts)+4))
+
+	for _, f := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f))
+	}
+
+	object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
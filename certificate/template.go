@@ -0,0 +1,232 @@
+// Package certificate renders completion certificates as PDF documents.
+// A Template lays out the page, its lines are text/template strings
+// executed with the certificate, and the PDF is written without any
+// external library.
+package certificate
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+//go:embed default.json
+var defaultTemplate []byte
+
+// Template is the layout of a certificate page, in points.
+type Template struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	// Margin is kept free left and right of every line. Lines too wide
+	// for the space between are set smaller.
+	Margin float64 `json:"margin"`
+	Frames []Frame `json:"frames"`
+	Lines  []Line  `json:"lines"`
+
+	texts []*template.Template
+}
+
+// Frame is a rectangle drawn Inset points inside the page edge.
+type Frame struct {
+	Inset     float64 `json:"inset"`
+	LineWidth float64 `json:"line_width"`
+	Color     string  `json:"color"`
+}
+
+// Line is a line of text centered on the page with its baseline at Y.
+// Text is executed with Data, the date function formats a time.
+type Line struct {
+	Text  string  `json:"text"`
+	Font  string  `json:"font"`
+	Size  float64 `json:"size"`
+	Y     float64 `json:"y"`
+	Color string  `json:"color"`
+}
+
+// Data is what the lines of a template are executed with.
+type Data struct {
+	model.Certificate
+	// VerifyURL is where the certificate can be checked.
+	VerifyURL string
+}
+
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("January 2, 2006")
+	},
+}
+
+// Default returns the built in template.
+func Default() *Template {
+	t, err := Parse(defaultTemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Load reads a template from a JSON file.
+func Load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+// Parse reads a template from JSON and checks it.
+func Parse(data []byte) (*Template, error) {
+	t := &Template{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+
+	if t.Width <= 0 || t.Height <= 0 {
+		return nil, fmt.Errorf("page size %vx%v must be positive", t.Width, t.Height)
+	}
+
+	if t.Margin < 0 || 2*t.Margin >= t.Width {
+		return nil, fmt.Errorf("margin %v does not fit the page", t.Margin)
+	}
+
+	for i, f := range t.Frames {
+		if _, err := parseColor(f.Color); err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+
+	for i, l := range t.Lines {
+		if l.Font == "" {
+			t.Lines[i].Font = Helvetica
+		} else if !knownFont(l.Font) {
+			return nil, fmt.Errorf("line %d: unknown font %q", i, l.Font)
+		}
+
+		if l.Size <= 0 {
+			return nil, fmt.Errorf("line %d: size must be positive", i)
+		}
+
+		if _, err := parseColor(l.Color); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i, err)
+		}
+
+		text, err := template.New(strconv.Itoa(i)).Funcs(funcs).Option("missingkey=error").Parse(l.Text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i, err)
+		}
+
+		t.texts = append(t.texts, text)
+	}
+
+	return t, nil
+}
+
+// Render writes the certificate page for data as a PDF document.
+func (t *Template) Render(w io.Writer, data Data) error {
+	p := &page{width: t.Width, height: t.Height}
+
+	for _, f := range t.Frames {
+		color, _ := parseColor(f.Color)
+		p.rect(f.Inset, f.Inset, t.Width-2*f.Inset, t.Height-2*f.Inset, f.LineWidth, color)
+	}
+
+	available := t.Width - 2*t.Margin
+
+	for i, l := range t.Lines {
+		var text bytes.Buffer
+		if err := t.texts[i].Execute(&text, data); err != nil {
+			return err
+		}
+
+		s := strings.TrimSpace(text.String())
+		if s == "" {
+			continue
+		}
+
+		size := l.Size
+		if width := textWidth(l.Font, size, s); width > available {
+			size = size * available / width
+		}
+
+		color, _ := parseColor(l.Color)
+		p.text((t.Width-textWidth(l.Font, size, s))/2, l.Y, l.Font, size, color, s)
+	}
+
+	_, err := p.writeTo(w)
+
+	return err
+}
+
+func knownFont(font string) bool {
+	for _, f := range fonts {
+		if f == font {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseColor reads a #rrggbb color into its components between 0 and 1.
+// The empty string is black.
+func parseColor(s string) ([3]float64, error) {
+	var result [3]float64
+
+	if s == "" {
+		return result, nil
+	}
+
+	if len(s) != 7 || s[0] != '#' {
+		return result, fmt.Errorf("color %q is not #rrggbb", s)
+	}
+
+	for i := range result {
+		v, err := strconv.ParseUint(s[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return result, fmt.Errorf("color %q is not #rrggbb", s)
+		}
+
+		result[i] = math.Round(float64(v)/255*1000) / 1000
+	}
+
+	return result, nil
+}
+
+// Renderer renders certificates with a template. Each certificate links to
+// its verify endpoint under BaseURL.
+type Renderer struct {
+	Template *Template
+	BaseURL  string
+}
+
+func NewRenderer(t *Template, baseURL string) *Renderer {
+	return &Renderer{
+		Template: t,
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Render writes the certificate as a PDF document.
+func (r *Renderer) Render(w io.Writer, certificate model.Certificate) error {
+	return r.Template.Render(w, Data{
+		Certificate: certificate,
+		VerifyURL:   r.BaseURL + "/certificates/" + certificate.Id + "/verify",
+	})
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// GetDatabase opens the database selected by cfg.Driver. For SQLite the
+// Database field is the path of the database file.
+func GetDatabase(cfg model.DatabaseConfig) (*repository.Database, error) {
+	dialect, err := repository.NewDialect(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	// The password is read on every new connection to follow secret reloads.
+	dsn := func() string {
+		switch dialect {
+		case repository.MySQL:
+			my := mysql.NewConfig()
+			my.User = cfg.User
+			my.Passwd = cfg.Password.Value()
+			my.Net = "tcp"
+			my.Addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+			my.DBName = cfg.Database
+			my.ParseTime = true
+
+			return my.FormatDSN()
+		case repository.PostgreSQL:
+			sslMode := cfg.SSLMode
+			if sslMode == "" {
+				sslMode = "disable"
+			}
+
+			return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", cfg.Host, cfg.Port, cfg.User, quotePostgres(cfg.Password.Value()), cfg.Database, sslMode)
+		default:
+			return fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", cfg.Database)
+		}
+	}
+
+	db, err := repository.OpenDatabaseFunc(dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite keeps the single connection set by OpenDatabase.
+	if dialect != repository.SQLite {
+		db.SetMaxOpenConns(cfg.Pool.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.Pool.MaxIdleConns)
+	}
+
+	db.SetConnMaxLifetime(time.Duration(cfg.Pool.ConnMaxLifetime))
+	db.SetConnMaxIdleTime(time.Duration(cfg.Pool.ConnMaxIdleTime))
+
+	return db, nil
+}
+
+// quotePostgres quotes a value of a key=value connection string.
+func quotePostgres(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+
+	return "'" + v + "'"
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+// field is one leaf setting of model.Config. Its key is the dotted path of
+// json names, e.g. database.pool.max_open_conns.
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+// EnvName is the environment variable of the setting,
+// e.g. ONLINE_LEARNING_DATABASE_POOL_MAX_OPEN_CONNS.
+func (f field) EnvName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+}
+
+func (f field) set(raw string) error {
+	if u, ok := f.value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		f.value.SetInt(v)
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		f.value.SetFloat(v)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		f.value.SetBool(v)
+	default:
+		return fmt.Errorf("unsupported setting type %s", f.value.Type())
+	}
+
+	return nil
+}
+
+func (f field) String() string {
+	if s, ok := f.value.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprint(f.value.Interface())
+}
+
+// fields lists every leaf setting of cfg in declaration order.
+func fields(cfg *model.Config) []field {
+	return walk(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func walk(v reflect.Value, prefix string) []field {
+	result := []field{}
+
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		value := v.Field(i)
+		_, isText := value.Addr().Interface().(encoding.TextUnmarshaler)
+
+		if value.Kind() == reflect.Struct && !isText {
+			result = append(result, walk(value, key)...)
+			continue
+		}
+
+		result = append(result, field{key: key, value: value})
+	}
+
+	return result
+}
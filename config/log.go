@@ -0,0 +1,12 @@
+package config
+
+import (
+	"github.com/egaevan/online-learning/logging"
+	"github.com/egaevan/online-learning/model"
+)
+
+// SetupLog applies the logging settings to the standard logrus logger and
+// the package loggers.
+func SetupLog(cfg model.LogConfig) error {
+	return logging.Configure(cfg.Level, cfg.Format, cfg.Packages)
+}
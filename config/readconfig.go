@@ -1,22 +1,193 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/egaevan/online-learning/constant"
 	"github.com/egaevan/online-learning/model"
+	"gopkg.in/yaml.v3"
 )
 
-func GetConfig() (*model.Config, error) {
-	cfg := &model.Config{}
+// EnvPrefix starts every environment variable read by Load.
+const EnvPrefix = "ONLINE_LEARNING_"
 
-	jsonFile, err := ioutil.ReadFile(constant.ConfigProjectFilepath)
+// Default returns the settings used when nothing overrides them.
+func Default() *model.Config {
+	return &model.Config{
+		HTTP: model.HTTPConfig{
+			Address:         ":8080",
+			ReadTimeout:     model.Duration(15 * time.Second),
+			WriteTimeout:    model.Duration(15 * time.Second),
+			ShutdownTimeout: model.Duration(10 * time.Second),
+		},
+		Database: model.DatabaseConfig{
+			Driver:            "mysql",
+			Host:              "localhost",
+			Port:              3306,
+			TransactionRetry:  3,
+			ConnectAttempts:   10,
+			ConnectBackoff:    model.Duration(500 * time.Millisecond),
+			ConnectMaxBackoff: model.Duration(10 * time.Second),
+			QueryTimeout:      model.Duration(10 * time.Second),
+			Pool: model.PoolConfig{
+				MaxOpenConns:    25,
+				MaxIdleConns:    25,
+				ConnMaxLifetime: model.Duration(5 * time.Minute),
+				ConnMaxIdleTime: model.Duration(5 * time.Minute),
+			},
+		},
+		JWT: model.JWTConfig{
+			Expiration: model.Duration(100000 * time.Minute),
+		},
+		Log: model.LogConfig{
+			Level:  "info",
+			Format: "text",
+			Access: true,
+		},
+		Tracing: model.TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			ServiceName: "online-learning",
+			SampleRatio: 1,
+		},
+		Feature: model.FeatureConfig{
+			Storage:                "sql",
+			Registration:           true,
+			CountRecomputeInterval: model.Duration(constant.CountRecomputeInterval),
+		},
+		Upload: model.UploadConfig{
+			Directory: "data/uploads",
+			MaxSize:   20 << 20,
+		},
+		Certificate: model.CertificateConfig{
+			BaseURL: "http://localhost:8080",
+		},
+		Commerce: model.CommerceConfig{
+			Currency: "USD",
+		},
+		Payment: model.PaymentConfig{
+			Gateway: constant.GatewayFake,
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, the config file, ONLINE_LEARNING_* environment variables and the
+// command line flags in args, then resolves the secret references. The file
+// is chosen by --config, then ONLINE_LEARNING_CONFIG, then config/config.json
+// if it exists. Load returns the arguments left after the flags, e.g. a
+// subcommand.
+func Load(args []string) (*model.Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("online-learning", flag.ContinueOnError)
+	path := fs.String("config", "", "path of a JSON or YAML config file")
+
+	settings := fields(cfg)
+	values := make(map[string]*string, len(settings))
+
+	for _, f := range settings {
+		values[f.key] = fs.String(f.key, f.String(), "environment variable "+f.EnvName())
+	}
+
+	// --storage predates the layered configuration.
+	storage := fs.String("storage", "", "alias of --feature.storage")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *path == "" {
+		*path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+
+	if *path == "" {
+		if _, err := os.Stat(constant.ConfigProjectFilepath); err == nil {
+			*path = constant.ConfigProjectFilepath
+		}
+	}
+
+	if *path != "" {
+		if err := readFile(*path, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, f := range settings {
+		raw, ok := os.LookupEnv(f.EnvName())
+		if !ok {
+			continue
+		}
+
+		if err := f.set(raw); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", f.EnvName(), err)
+		}
+	}
+
+	visited := map[string]bool{}
+	fs.Visit(func(v *flag.Flag) {
+		visited[v.Name] = true
+	})
+
+	if visited["storage"] && !visited["feature.storage"] {
+		visited["feature.storage"] = true
+		*values["feature.storage"] = *storage
+	}
+
+	for _, f := range settings {
+		if !visited[f.key] {
+			continue
+		}
+
+		if err := f.set(*values[f.key]); err != nil {
+			return nil, nil, fmt.Errorf("--%s: %w", f.key, err)
+		}
+	}
+
+	if err := ResolveSecrets(context.Background(), cfg); err != nil {
+		return nil, nil, err
+	}
+
+	if err := Validate(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// readFile decodes the file at path over cfg. Files ending in .yaml or .yml
+// are YAML, anything else is JSON. Unknown keys are an error.
+func readFile(path string, cfg *model.Config) error {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
 	}
 
-	err = json.Unmarshal(jsonFile, &cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
 
-	return cfg, nil
+	return nil
 }
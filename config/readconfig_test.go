@@ -0,0 +1,113 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return path
+	}
+
+	jsonFile := write("config.json", `{"http": {"address": ":9000", "read_timeout": "30s"}, "log": {"level": "debug"}}`)
+	yamlFile := write("config.yaml", "http:\n  address: \":9100\"\nlog:\n  level: warn\n")
+
+	type settings struct {
+		Address     string
+		ReadTimeout model.Duration
+		Level       string
+		Storage     string
+	}
+
+	defaults := settings{":8080", model.Duration(15 * time.Second), "info", "memory"}
+
+	for _, c := range []struct {
+		name string
+		env  map[string]string
+		args []string
+		want settings
+	}{
+		{"defaults", nil, nil, defaults},
+		{"file over defaults", nil, []string{"--config", jsonFile}, settings{":9000", model.Duration(30 * time.Second), "debug", "memory"}},
+		{"yaml file", nil, []string{"--config", yamlFile}, settings{":9100", defaults.ReadTimeout, "warn", "memory"}},
+		{"file from env", map[string]string{"ONLINE_LEARNING_CONFIG": yamlFile}, nil, settings{":9100", defaults.ReadTimeout, "warn", "memory"}},
+		{"--config over env", map[string]string{"ONLINE_LEARNING_CONFIG": yamlFile}, []string{"--config", jsonFile}, settings{":9000", model.Duration(30 * time.Second), "debug", "memory"}},
+		{"env over file", map[string]string{"ONLINE_LEARNING_LOG_LEVEL": "error"}, []string{"--config", jsonFile}, settings{":9000", model.Duration(30 * time.Second), "error", "memory"}},
+		{"env over defaults", map[string]string{"ONLINE_LEARNING_HTTP_READ_TIMEOUT": "1m"}, nil, settings{":8080", model.Duration(time.Minute), "info", "memory"}},
+		{"flag over env", map[string]string{"ONLINE_LEARNING_LOG_LEVEL": "error"}, []string{"--config", jsonFile, "--log.level", "trace"}, settings{":9000", model.Duration(30 * time.Second), "trace", "memory"}},
+		{"flag over file", nil, []string{"--config", jsonFile, "--http.address", ":9200"}, settings{":9200", model.Duration(30 * time.Second), "debug", "memory"}},
+		{"storage alias", map[string]string{"ONLINE_LEARNING_FEATURE_STORAGE": "sql"}, []string{"--storage", "memory"}, defaults},
+		{"feature.storage over its alias", nil, []string{"--storage", "sql", "--feature.storage", "memory"}, defaults},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("ONLINE_LEARNING_JWT_SECRET", "secret")
+			t.Setenv("ONLINE_LEARNING_FEATURE_STORAGE", "memory")
+
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, rest, err := Load(append(c.args, "migrate", "up"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := settings{cfg.HTTP.Address, cfg.HTTP.ReadTimeout, cfg.Log.Level, cfg.Feature.Storage}
+			if got != c.want {
+				t.Errorf("Load = %+v, want %+v", got, c.want)
+			}
+
+			if !reflect.DeepEqual(rest, []string{"migrate", "up"}) {
+				t.Errorf("Load left %v, want the subcommand", rest)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	unknown := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(unknown, []byte(`{"http": {"adress": ":9000"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name string
+		env  map[string]string
+		args []string
+	}{
+		{"unknown key in file", nil, []string{"--config", unknown}},
+		{"missing file", nil, []string{"--config", filepath.Join(dir, "missing.json")}},
+		{"bad env value", map[string]string{"ONLINE_LEARNING_HTTP_READ_TIMEOUT": "soon"}, nil},
+		{"bad flag value", nil, []string{"--database.port", "many"}},
+		{"unknown flag", nil, []string{"--no-such-flag"}},
+		{"invalid setting", map[string]string{"ONLINE_LEARNING_LOG_FORMAT": "xml"}, nil},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("ONLINE_LEARNING_JWT_SECRET", "secret")
+			t.Setenv("ONLINE_LEARNING_FEATURE_STORAGE", "memory")
+
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+
+			if _, _, err := Load(c.args); err == nil {
+				t.Error("Load accepted the configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+// SecretProvider looks up a secret by the part of a reference after its
+// scheme, e.g. /run/secrets/db for file:/run/secrets/db.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to SecretProvider.
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+func (f SecretProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+var (
+	secretProviderMu sync.RWMutex
+	secretProviders  = map[string]SecretProvider{
+		"file": SecretProviderFunc(fileSecret),
+		"env":  SecretProviderFunc(envSecret),
+	}
+)
+
+// RegisterSecretProvider makes references starting with scheme: resolve
+// through p, e.g. a vault client registered as "vault".
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+
+	secretProviders[scheme] = p
+}
+
+func secretProvider(ref string) (SecretProvider, string, bool) {
+	scheme := strings.SplitN(ref, ":", 2)
+	if len(scheme) != 2 {
+		return nil, "", false
+	}
+
+	secretProviderMu.RLock()
+	defer secretProviderMu.RUnlock()
+
+	p, ok := secretProviders[scheme[0]]
+
+	return p, scheme[1], ok
+}
+
+// ResolveSecrets looks up every model.Secret in cfg. A reference without a
+// registered scheme is the secret itself. Calling it again re-reads the
+// secrets, which is how rotated secrets are picked up without a restart.
+// Either every secret is updated or, on error, none is.
+func ResolveSecrets(ctx context.Context, cfg *model.Config) error {
+	secrets := []model.Secret{}
+	values := []string{}
+
+	for _, f := range fields(cfg) {
+		s, ok := f.value.Interface().(model.Secret)
+		if !ok || s.Ref() == "" {
+			continue
+		}
+
+		value := s.Ref()
+
+		if p, name, ok := secretProvider(s.Ref()); ok {
+			v, err := p.Secret(ctx, name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.key, err)
+			}
+
+			value = v
+		}
+
+		secrets = append(secrets, s)
+		values = append(values, value)
+	}
+
+	for i, s := range secrets {
+		s.Set(values[i])
+	}
+
+	return nil
+}
+
+// Dump returns cfg as indented JSON with the secrets redacted.
+func Dump(cfg *model.Config) ([]byte, error) {
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+func fileSecret(_ context.Context, path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Secret files usually end with a newline that is not part of the secret.
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func envSecret(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/logging"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// ValidationError lists every invalid setting found by Validate.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+// currencyCode matches ISO 4217 alphabetic codes.
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Validate checks cfg and reports all problems at once.
+func Validate(cfg *model.Config) error {
+	var errs ValidationError
+
+	fail := func(key, format string, args ...interface{}) {
+		errs = append(errs, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if cfg.HTTP.Address == "" {
+		fail("http.address", "must not be empty")
+	}
+
+	for key, d := range map[string]model.Duration{
+		"http.read_timeout":                cfg.HTTP.ReadTimeout,
+		"http.write_timeout":               cfg.HTTP.WriteTimeout,
+		"http.shutdown_timeout":            cfg.HTTP.ShutdownTimeout,
+		"database.connect_backoff":         cfg.Database.ConnectBackoff,
+		"database.connect_max_backoff":     cfg.Database.ConnectMaxBackoff,
+		"database.query_timeout":           cfg.Database.QueryTimeout,
+		"database.pool.conn_max_lifetime":  cfg.Database.Pool.ConnMaxLifetime,
+		"database.pool.conn_max_idle_time": cfg.Database.Pool.ConnMaxIdleTime,
+	} {
+		if d < 0 {
+			fail(key, "must not be negative")
+		}
+	}
+
+	if cfg.Feature.Storage == "sql" {
+		validateDatabase(cfg.Database, fail)
+	}
+
+	if cfg.JWT.Secret.Value() == "" {
+		fail("jwt.secret", "must be set, e.g. with %sJWT_SECRET", EnvPrefix)
+	}
+
+	if cfg.JWT.Expiration <= 0 {
+		fail("jwt.expiration", "must be positive")
+	}
+
+	if _, err := log.ParseLevel(cfg.Log.Level); err != nil {
+		fail("log.level", "unknown level %q", cfg.Log.Level)
+	}
+
+	if _, err := logging.ParseLevels(cfg.Log.Packages); err != nil {
+		fail("log.packages", "%v", err)
+	}
+
+	if cfg.Log.Format != "text" && cfg.Log.Format != "json" {
+		fail("log.format", "must be text or json, got %q", cfg.Log.Format)
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if cfg.Tracing.Endpoint == "" {
+			fail("tracing.endpoint", "must not be empty with the otlp exporter")
+		}
+	default:
+		fail("tracing.exporter", "must be none, stdout or otlp, got %q", cfg.Tracing.Exporter)
+	}
+
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be between 0 and 1, got %v", cfg.Tracing.SampleRatio)
+	}
+
+	if cfg.Feature.Storage != "sql" && cfg.Feature.Storage != "memory" {
+		fail("feature.storage", "must be sql or memory, got %q", cfg.Feature.Storage)
+	}
+
+	if cfg.Feature.CountRecomputeInterval <= 0 {
+		fail("feature.count_recompute_interval", "must be positive")
+	}
+
+	if cfg.Upload.Directory == "" {
+		fail("upload.directory", "must not be empty")
+	}
+
+	if cfg.Upload.MaxSize <= 0 {
+		fail("upload.max_size", "must be positive")
+	}
+
+	if u, err := url.Parse(cfg.Certificate.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("certificate.base_url", "must be an absolute http or https URL, got %q", cfg.Certificate.BaseURL)
+	}
+
+	if !currencyCode.MatchString(cfg.Commerce.Currency) {
+		fail("commerce.currency", "must be an ISO 4217 code such as USD, got %q", cfg.Commerce.Currency)
+	}
+
+	if cfg.Payment.Gateway != constant.GatewayFake {
+		fail("payment.gateway", "must be fake, got %q", cfg.Payment.Gateway)
+	}
+
+	if len(errs) > 0 {
+		// Map iteration above is unordered, keep the output stable.
+		sort.Strings(errs)
+		return errs
+	}
+
+	return nil
+}
+
+func validateDatabase(cfg model.DatabaseConfig, fail func(key, format string, args ...interface{})) {
+	dialect, err := repository.NewDialect(cfg.Driver)
+	if err != nil {
+		fail("database.driver", "must be mysql, postgres or sqlite3, got %q", cfg.Driver)
+	}
+
+	if cfg.Database == "" {
+		fail("database.database", "must not be empty")
+	}
+
+	if err == nil && dialect != repository.SQLite {
+		if cfg.Host == "" {
+			fail("database.host", "must not be empty")
+		}
+
+		if cfg.Port <= 0 || cfg.Port > 65535 {
+			fail("database.port", "must be between 1 and 65535, got %d", cfg.Port)
+		}
+	}
+
+	if cfg.TransactionRetry < 0 {
+		fail("database.transaction_retry", "must not be negative")
+	}
+
+	if cfg.ConnectAttempts < 1 {
+		fail("database.connect_attempts", "must be at least 1")
+	}
+
+	if cfg.Pool.MaxOpenConns < 0 {
+		fail("database.pool.max_open_conns", "must not be negative")
+	}
+
+	if cfg.Pool.MaxIdleConns < 0 {
+		fail("database.pool.max_idle_conns", "must not be negative")
+	}
+}
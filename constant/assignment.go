@@ -0,0 +1,15 @@
+package constant
+
+// Gradebook statuses of a student for an assignment.
+const (
+	// SubmissionOpen means nothing is submitted and the due date is ahead.
+	SubmissionOpen = "open"
+	// SubmissionMissing means nothing is submitted and the due date passed.
+	SubmissionMissing   = "missing"
+	SubmissionSubmitted = "submitted"
+	SubmissionLate      = "late"
+	SubmissionGraded    = "graded"
+)
+
+// MaxSubmissionFiles is the most files one submission may hold.
+const MaxSubmissionFiles = 10
@@ -1,5 +1,11 @@
 package constant
 
+import "time"
+
 const (
 	ConfigProjectFilepath = "config/config.json"
+
+	// TransactionRetryDelay is the wait before the first retry of a
+	// deadlocked transaction. Every following retry waits one step longer.
+	TransactionRetryDelay = 50 * time.Millisecond
 )
@@ -0,0 +1,7 @@
+package constant
+
+// Coupon types, taking a percentage or a fixed amount off.
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
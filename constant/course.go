@@ -0,0 +1,31 @@
+package constant
+
+import "time"
+
+const (
+	// PopularCategoryWindowDays is the default enrollment window used to rank
+	// popular categories.
+	PopularCategoryWindowDays = 30
+
+	CountRecomputeInterval = 5 * time.Minute
+)
+
+// Course sort keys accepted by /course-sort.
+const (
+	SortHighPrice = "high"
+	SortLowPrice  = "low"
+	SortFree      = "free"
+	// SortRating orders by average rating, then by number of ratings.
+	SortRating = "rating"
+)
+
+// Lesson types.
+const (
+	LessonVideo = "video"
+	LessonText  = "text"
+	LessonFile  = "file"
+	LessonLink  = "link"
+	LessonQuiz  = "quiz"
+	// LessonAssignment is completed once the submission is graded.
+	LessonAssignment = "assignment"
+)
@@ -0,0 +1,6 @@
+package constant
+
+import "time"
+
+// HealthCheckTimeout bounds every readiness check.
+const HealthCheckTimeout = 2 * time.Second
@@ -0,0 +1,8 @@
+package constant
+
+// Notification types.
+const (
+	NotificationGrade       = "grade"
+	NotificationCertificate = "certificate"
+	NotificationReviewReply = "review_reply"
+)
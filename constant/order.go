@@ -0,0 +1,10 @@
+package constant
+
+// Order statuses. An order starts pending, then its payment either succeeds
+// or fails. Paid orders can be refunded.
+const (
+	OrderPending  = "pending"
+	OrderPaid     = "paid"
+	OrderFailed   = "failed"
+	OrderRefunded = "refunded"
+)
@@ -0,0 +1,25 @@
+package constant
+
+// Payment statuses. A payment is authorized when the customer confirmed
+// it, and succeeds once it is captured.
+const (
+	PaymentCreated    = "created"
+	PaymentAuthorized = "authorized"
+	PaymentSucceeded  = "succeeded"
+	PaymentFailed     = "failed"
+	PaymentRefunded   = "refunded"
+)
+
+// Payment event types, each reports the payment reached a status.
+const (
+	EventPaymentAuthorized = "payment.authorized"
+	EventPaymentSucceeded  = "payment.succeeded"
+	EventPaymentFailed     = "payment.failed"
+	EventPaymentRefunded   = "payment.refunded"
+)
+
+// GatewayFake is the in-process payment provider.
+const GatewayFake = "fake"
+
+// PaymentSignatureHeader carries the signature of payment webhooks.
+const PaymentSignatureHeader = "Payment-Signature"
@@ -0,0 +1,22 @@
+package constant
+
+import "time"
+
+// Quiz question types.
+const (
+	QuestionSingleChoice   = "single_choice"
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionTrueFalse      = "true_false"
+	QuestionShortAnswer    = "short_answer"
+	QuestionNumeric        = "numeric"
+)
+
+// Quiz score policies, the attempt whose score counts.
+const (
+	ScoreBest   = "best"
+	ScoreLatest = "latest"
+)
+
+// QuizSubmitGrace is added to the time limit of an attempt before a
+// submission counts as late, to make up for slow networks.
+const QuizSubmitGrace = 30 * time.Second
@@ -0,0 +1,10 @@
+package constant
+
+// Ratings are whole stars in this range.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// MaxReviewLength is the longest review or reply text, in characters.
+const MaxReviewLength = 5000
@@ -0,0 +1,4 @@
+package constant
+
+// RoleAdmin is the role of the users managing the catalog.
+const RoleAdmin = 1
@@ -0,0 +1,218 @@
+package rest
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetAssignment serves the assignment to admins and the users allowed to
+// follow the lesson.
+func (h *Handler) GetAssignment(c echo.Context) error {
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	userInfo, _ := c.Get("user").(*model.Token)
+
+	res, err := h.AssignmentUsecae.GetAssignment(c.Request().Context(), lessonID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SaveAssignment sets the due date, rubric and late penalty rules of an
+// assignment lesson.
+func (h *Handler) SaveAssignment(c echo.Context) error {
+	dataReq := model.Assignment{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.AssignmentUsecae.SaveAssignment(c.Request().Context(), lessonID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Submit takes a multipart form with one or more file fields.
+func (h *Handler) Submit(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	defer form.RemoveAll()
+
+	uploads := make([]model.Upload, 0, len(form.File["file"]))
+
+	for _, header := range form.File["file"] {
+		f, err := header.Open()
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		defer func(f multipart.File) {
+			f.Close()
+		}(f)
+
+		uploads = append(uploads, model.Upload{
+			Name:        header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Body:        f,
+		})
+	}
+
+	res, err := h.AssignmentUsecae.Submit(c.Request().Context(), userInfo.UserID, lessonID, uploads)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *Handler) GetMySubmission(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.AssignmentUsecae.GetMySubmission(c.Request().Context(), userInfo.UserID, lessonID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetSubmission(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	submissionID, err := pathID(c, "submissionID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.AssignmentUsecae.GetSubmission(c.Request().Context(), submissionID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// DownloadFile sends a file of a submission as an attachment.
+func (h *Handler) DownloadFile(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	submissionID, err := pathID(c, "submissionID")
+	if err != nil {
+		return err
+	}
+
+	fileID, err := pathID(c, "fileID")
+	if err != nil {
+		return err
+	}
+
+	file, content, err := h.AssignmentUsecae.OpenFile(c.Request().Context(), submissionID, fileID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	defer content.Close()
+
+	c.Response().Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+
+	return c.Stream(http.StatusOK, file.ContentType, content)
+}
+
+// GradeSubmission takes a score for every criterion of the rubric and a
+// comment.
+func (h *Handler) GradeSubmission(c echo.Context) error {
+	dataReq := model.GradeRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	submissionID, err := pathID(c, "submissionID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.AssignmentUsecae.GradeSubmission(c.Request().Context(), userInfo.UserID, submissionID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetGradebook lists the status of every enrolled student per assignment.
+func (h *Handler) GetGradebook(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.AssignmentUsecae.GetGradebook(c.Request().Context(), courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetCategoryTree(c echo.Context) error {
+
+	res, err := h.CourseUsecae.GetCategoryTree(c.Request().Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responseError{
+			Message: "internal error",
+		})
+
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetCourseByCategory(c echo.Context) error {
+	categoryIDParam := c.Param("categoryID")
+
+	categoryID, err := strconv.Atoi(categoryIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid parameter",
+		})
+
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.GetCourseByCategory(c.Request().Context(), categoryID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, responseError{
+				Message: "category not found",
+			})
+
+			return echo.ErrNotFound
+		}
+
+		c.JSON(http.StatusInternalServerError, responseError{
+			Message: "internal error",
+		})
+
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateCategory(c echo.Context) error {
+	dataReq := model.Category{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.CreateCategory(c.Request().Context(), dataReq)
+	if err != nil {
+		if errors.Is(err, model.ErrBadParamInput) {
+			c.JSON(http.StatusBadRequest, responseError{
+				Message: "invalid data request",
+			})
+
+			return echo.ErrBadRequest
+		}
+
+		c.JSON(http.StatusInternalServerError, responseError{
+			Message: "internal error",
+		})
+
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetMyCertificate lists the certificates of the user, newest first.
+func (h *Handler) GetMyCertificate(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	res, err := h.CertificateUsecae.GetMyCertificate(c.Request().Context(), userInfo.UserID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// DownloadCertificate streams the PDF of a certificate.
+func (h *Handler) DownloadCertificate(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	certificate, content, err := h.CertificateUsecae.OpenCertificate(c.Request().Context(), c.Param("certificateID"), userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	defer content.Close()
+
+	c.Response().Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "certificate-" + certificate.Id + ".pdf"}))
+
+	return c.Stream(http.StatusOK, "application/pdf", content)
+}
+
+// VerifyCertificate is public, anyone holding a certificate id can check
+// it.
+func (h *Handler) VerifyCertificate(c echo.Context) error {
+	res, err := h.CertificateUsecae.VerifyCertificate(c.Request().Context(), c.Param("certificateID"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	// Revocations must show up right away.
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RevokeCertificate takes the reason for revoking the certificate.
+func (h *Handler) RevokeCertificate(c echo.Context) error {
+	dataReq := model.RevokeRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CertificateUsecae.RevokeCertificate(c.Request().Context(), c.Param("certificateID"), dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetCoupon lets admins list the coupons, newest first.
+func (h *Handler) GetCoupon(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	res, err := h.CouponUsecae.GetCoupon(c.Request().Context())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetDetailCoupon(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	couponID, err := pathID(c, "couponID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.CouponUsecae.GetDetailCoupon(c.Request().Context(), couponID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateCoupon(c echo.Context) error {
+	dataReq := model.CouponRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CouponUsecae.CreateCoupon(c.Request().Context(), dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// UpdateCoupon replaces every setting of a coupon.
+func (h *Handler) UpdateCoupon(c echo.Context) error {
+	dataReq := model.CouponRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	couponID, err := pathID(c, "couponID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CouponUsecae.UpdateCoupon(c.Request().Context(), couponID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteCoupon(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	couponID, err := pathID(c, "couponID")
+	if err != nil {
+		return err
+	}
+
+	if err := h.CouponUsecae.DeleteCoupon(c.Request().Context(), couponID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Coupon has been deleted",
+	})
+}
@@ -0,0 +1,271 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// pathID reads the numeric path parameter name. On failure it answers 400
+// and returns the error the handler should return.
+func pathID(c echo.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid parameter",
+		})
+
+		return 0, echo.ErrBadRequest
+	}
+
+	return id, nil
+}
+
+// GetLesson serves free preview lessons to everyone and the other lessons
+// to the users allowed to follow the course.
+func (h *Handler) GetLesson(c echo.Context) error {
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	userInfo, _ := c.Get("user").(*model.Token)
+
+	res, err := h.CourseUsecae.GetLesson(c.Request().Context(), lessonID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateSection(c echo.Context) error {
+	dataReq := model.Section{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.CreateSection(c.Request().Context(), courseID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// UpdateSection takes a JSON Merge Patch body.
+func (h *Handler) UpdateSection(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	sectionID, err := pathID(c, "sectionID")
+	if err != nil {
+		return err
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.UpdateSection(c.Request().Context(), patch, sectionID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteSection(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	sectionID, err := pathID(c, "sectionID")
+	if err != nil {
+		return err
+	}
+
+	err = h.CourseUsecae.DeleteSection(c.Request().Context(), sectionID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Section has been deleted",
+	})
+}
+
+// ReorderSection takes every section id of the course in the new order.
+func (h *Handler) ReorderSection(c echo.Context) error {
+	dataReq := model.Reorder{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.ReorderSection(c.Request().Context(), courseID, dataReq.Ids)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateLesson(c echo.Context) error {
+	dataReq := model.Lesson{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	sectionID, err := pathID(c, "sectionID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.CreateLesson(c.Request().Context(), sectionID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// UpdateLesson takes a JSON Merge Patch body.
+func (h *Handler) UpdateLesson(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.UpdateLesson(c.Request().Context(), patch, lessonID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteLesson(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	err = h.CourseUsecae.DeleteLesson(c.Request().Context(), lessonID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Lesson has been deleted",
+	})
+}
+
+// ReorderLesson takes every lesson id of the section in the new order.
+func (h *Handler) ReorderLesson(c echo.Context) error {
+	dataReq := model.Reorder{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	sectionID, err := pathID(c, "sectionID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.CourseUsecae.ReorderLesson(c.Request().Context(), sectionID, dataReq.Ids)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
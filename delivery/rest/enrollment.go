@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) Enroll(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.EnrollmentUsecae.Enroll(c.Request().Context(), userInfo.UserID, courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) Unenroll(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	err = h.EnrollmentUsecae.Unenroll(c.Request().Context(), userInfo.UserID, courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Enrollment has been cancelled",
+	})
+}
+
+// GetMyCourse lists the courses the user is enrolled in.
+func (h *Handler) GetMyCourse(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	res, err := h.EnrollmentUsecae.GetMyCourse(c.Request().Context(), userInfo.UserID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetRoster lists the users enrolled in a course.
+func (h *Handler) GetRoster(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.EnrollmentUsecae.GetRoster(c.Request().Context(), courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SaveProgress records the progress of the user in a lesson. Sending the
+// same update again is harmless.
+func (h *Handler) SaveProgress(c echo.Context) error {
+	dataReq := model.ProgressUpdate{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	err = h.EnrollmentUsecae.SaveProgress(c.Request().Context(), userInfo.UserID, lessonID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) GetCourseProgress(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.EnrollmentUsecae.GetCourseProgress(c.Request().Context(), userInfo.UserID, courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
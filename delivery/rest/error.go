@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// errorResponse writes the response matching a usecase error and returns
+// the echo error of the same status.
+func errorResponse(c echo.Context, err error) error {
+	status, message := http.StatusInternalServerError, "internal error"
+
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		status, message = http.StatusNotFound, "data not found"
+	case errors.Is(err, model.ErrBadParamInput):
+		status, message = http.StatusBadRequest, "invalid data request"
+	case errors.Is(err, model.ErrForbidden):
+		status, message = http.StatusForbidden, "access denied"
+	case errors.Is(err, model.ErrPaymentNeeded):
+		status, message = http.StatusPaymentRequired, "the course has to be purchased first"
+	case errors.Is(err, model.ErrNoAttemptLeft):
+		status, message = http.StatusForbidden, "no attempt left"
+	case errors.Is(err, model.ErrPastDeadline):
+		status, message = http.StatusForbidden, "submissions are closed"
+	case errors.Is(err, model.ErrTooLarge):
+		status, message = http.StatusRequestEntityTooLarge, "file too large"
+	case errors.Is(err, model.ErrCoupon):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, model.ErrConflict):
+		status, message = http.StatusPreconditionFailed, "data was modified, fetch it again and retry"
+	}
+
+	c.JSON(status, responseError{
+		Message: message,
+	})
+
+	return echo.NewHTTPError(status)
+}
+
+// responseStatus is the status a handler answered with. When the handler
+// left the response to echo's error handler it is derived from err.
+func responseStatus(c echo.Context, err error) int {
+	if c.Response().Committed {
+		return c.Response().Status
+	}
+
+	var he *echo.HTTPError
+
+	switch {
+	case err == nil:
+		return c.Response().Status
+	case errors.As(err, &he):
+		return he.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// versionETag formats a resource version as a strong ETag.
+func versionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// detailETag is the ETag of a course detail. The detail carries the
+// enrollment of the requesting user and the rating, which change without a
+// new version, so the tag covers them too.
+func detailETag(detail *model.CourseDetail) string {
+	tag := strconv.Itoa(detail.Version)
+
+	if detail.Rating.Count > 0 {
+		stars := make([]string, 0, 5)
+		for rating := 1; rating <= 5; rating++ {
+			stars = append(stars, strconv.Itoa(detail.Rating.Distribution[rating]))
+		}
+
+		tag += "-r" + strings.Join(stars, ".")
+	}
+
+	if detail.Enrollment != nil {
+		tag += fmt.Sprintf("-e%d", detail.Enrollment.Id)
+	}
+
+	return strconv.Quote(tag)
+}
+
+// ifMatchVersion reads the version from the If-Match header. It returns 0
+// when the header is missing or "*", and false when the header can't match
+// any version.
+func ifMatchVersion(c echo.Context) (int, bool) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+
+	// Tags from detailETag carry a suffix after the version.
+	version, err := strconv.Atoi(strings.SplitN(unquoted, "-", 2)[0])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// ifNoneMatch reports whether the If-None-Match header lists etag.
+func ifNoneMatch(c echo.Context, etag string) bool {
+	for _, candidate := range strings.Split(c.Request().Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository/repotest"
+	"github.com/egaevan/online-learning/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDetailETag(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		detail model.CourseDetail
+		want   string
+	}{
+		{"version", model.CourseDetail{Version: 3}, `"3"`},
+		{"rating", model.CourseDetail{Version: 3, Rating: model.RatingSummary{Count: 3, Distribution: map[int]int{4: 1, 5: 2}}}, `"3-r0.0.0.1.2"`},
+		{"enrollment", model.CourseDetail{Version: 3, Enrollment: &model.Enrollment{Id: 7}}, `"3-e7"`},
+		{"rating and enrollment", model.CourseDetail{Version: 3, Rating: model.RatingSummary{Count: 1, Distribution: map[int]int{1: 1}}, Enrollment: &model.Enrollment{Id: 7}}, `"3-r1.0.0.0.0-e7"`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := detailETag(&c.detail); got != c.want {
+				t.Errorf("detailETag = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	for _, c := range []struct {
+		header  string
+		want    int
+		wantOK  bool
+		comment string
+	}{
+		{"", 0, true, "no header"},
+		{"*", 0, true, "any version"},
+		{`"3"`, 3, true, "version tag"},
+		{` "3" `, 3, true, "spaces"},
+		{`"3-r0.0.0.1.2-e7"`, 3, true, "detail tag"},
+		{`3`, 0, false, "unquoted"},
+		{`W/"3"`, 0, false, "weak tag"},
+		{`"0"`, 0, false, "no version"},
+		{`"-1"`, 0, false, "negative version"},
+		{`"abc"`, 0, false, "not a version"},
+	} {
+		t.Run(c.comment, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/", nil)
+			if c.header != "" {
+				req.Header.Set("If-Match", c.header)
+			}
+
+			version, ok := ifMatchVersion(echo.New().NewContext(req, httptest.NewRecorder()))
+			if version != c.want || ok != c.wantOK {
+				t.Errorf("ifMatchVersion(%q) = %d, %v, want %d, %v", c.header, version, ok, c.want, c.wantOK)
+			}
+		})
+	}
+}
+
+func TestUpdateCourseIfMatch(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		ifMatch    string
+		wantStatus int
+	}{
+		{"current version", `"1"`, http.StatusOK},
+		{"current detail tag", `"1-e3"`, http.StatusOK},
+		{"no header", "", http.StatusOK},
+		{"stale version", `"2"`, http.StatusPreconditionFailed},
+		{"malformed", `"latest"`, http.StatusPreconditionFailed},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			repos := repotest.Memory(t)
+			ctx := context.Background()
+
+			category, err := repos.Course.StoreCategory(ctx, model.Category{Name: "Programming"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id, err := repos.Course.Store(ctx, model.Course{CategoryId: category, Name: "Go Basics", Price: 1500})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h := &Handler{CourseUsecae: usecase.NewCourse(repos.Course, repos.Enrollment, repos.Review, repos.Transactor)}
+
+			req := httptest.NewRequest(http.MethodPatch, "/course/"+strconv.Itoa(id), strings.NewReader(`{"price": 2000}`))
+			if c.ifMatch != "" {
+				req.Header.Set("If-Match", c.ifMatch)
+			}
+
+			rec := httptest.NewRecorder()
+
+			e := echo.New()
+			ec := e.NewContext(req, rec)
+			ec.SetParamNames("courseID")
+			ec.SetParamValues(strconv.Itoa(id))
+			ec.Set("user", &model.Token{Role: isAdmin})
+
+			if err := h.UpdateCourse(ec); err != nil {
+				e.HTTPErrorHandler(err, ec)
+			}
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, c.wantStatus, rec.Body)
+			}
+
+			if etag := rec.Header().Get("ETag"); c.wantStatus == http.StatusOK && etag != `"2"` {
+				t.Errorf("ETag = %s, want the new version \"2\"", etag)
+			}
+		})
+	}
+}
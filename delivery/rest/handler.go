@@ -1,17 +1,30 @@
 package rest
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/egaevan/online-learning/constant"
 	"github.com/egaevan/online-learning/model"
 	"github.com/egaevan/online-learning/usecase"
 	"github.com/labstack/echo/v4"
 )
 
 type Handler struct {
-	CourseUsecae usecase.CourseUsecae
-	UserUsecae   usecase.UserUsecae
+	CourseUsecae       usecase.CourseUsecae
+	UserUsecae         usecase.UserUsecae
+	EnrollmentUsecae   usecase.EnrollmentUsecae
+	QuizUsecae         usecase.QuizUsecae
+	AssignmentUsecae   usecase.AssignmentUsecae
+	NotificationUsecae usecase.NotificationUsecae
+	CertificateUsecae  usecase.CertificateUsecae
+	ReviewUsecae       usecase.ReviewUsecae
+	OrderUsecae        usecase.OrderUsecae
+	CouponUsecae       usecase.CouponUsecae
+	MonitorUsecae      usecase.MonitorUsecae
 }
 
 type responseError struct {
@@ -22,29 +35,149 @@ const (
 	isAdmin int = 1
 )
 
-func NewHandler(e *echo.Echo, courseUsecae usecase.CourseUsecae, userUsecae usecase.UserUsecae) {
+func NewHandler(e *echo.Echo, courseUsecae usecase.CourseUsecae, userUsecae usecase.UserUsecae, enrollmentUsecae usecase.EnrollmentUsecae, quizUsecae usecase.QuizUsecae, assignmentUsecae usecase.AssignmentUsecae, notificationUsecae usecase.NotificationUsecae, certificateUsecae usecase.CertificateUsecae, reviewUsecae usecase.ReviewUsecae, orderUsecae usecase.OrderUsecae, couponUsecae usecase.CouponUsecae, monitorUsecae usecase.MonitorUsecae, cfg *model.Config) {
 	handler := &Handler{
-		CourseUsecae: courseUsecae,
-		UserUsecae:   userUsecae,
+		CourseUsecae:       courseUsecae,
+		UserUsecae:         userUsecae,
+		EnrollmentUsecae:   enrollmentUsecae,
+		QuizUsecae:         quizUsecae,
+		AssignmentUsecae:   assignmentUsecae,
+		NotificationUsecae: notificationUsecae,
+		CertificateUsecae:  certificateUsecae,
+		ReviewUsecae:       reviewUsecae,
+		OrderUsecae:        orderUsecae,
+		CouponUsecae:       couponUsecae,
+		MonitorUsecae:      monitorUsecae,
+	}
+
+	auth := JwtVerify(cfg.JWT.Secret)
+	optionalAuth := JwtOptional(cfg.JWT.Secret)
+
+	e.Use(RequestID)
+	e.Use(Tracing)
+	e.Use(Metrics)
+
+	if cfg.Log.Access {
+		e.Use(AccessLog)
 	}
 
 	// Routing User
 	e.POST("/login", handler.Login)
-	e.POST("/register", handler.Register)
-	e.DELETE("/user/:userID", handler.DeleteUser, JwtVerify)
+	if cfg.Feature.Registration {
+		e.POST("/register", handler.Register)
+	}
+	e.DELETE("/user/:userID", handler.DeleteUser, auth)
 
 	// Routing Course
 	e.GET("/course", handler.GetCourse)
-	e.GET("/course/:courseID", handler.GetDetailCourse)
+	e.GET("/course/:courseID", handler.GetDetailCourse, optionalAuth)
 	e.GET("/course-search", handler.SearchCourse)
 	e.GET("/course-sort", handler.SortCourse)
-	e.POST("/course", handler.SendCourse, JwtVerify)
-	e.PATCH("/course/:courseID", handler.UpdateCourse, JwtVerify)
-	e.DELETE("/course/:courseID", handler.DeleteCourse, JwtVerify)
+	e.POST("/course", handler.SendCourse, auth)
+	e.PATCH("/course/:courseID", handler.UpdateCourse, auth)
+	e.DELETE("/course/:courseID", handler.DeleteCourse, auth)
 	e.GET("/category", handler.GetCategory)
 	e.GET("/category/:limit", handler.GetPopularCategory)
-
-	e.GET("/statistic", handler.GetStatistic, JwtVerify)
+	e.GET("/category-tree", handler.GetCategoryTree)
+	e.GET("/category-course/:categoryID", handler.GetCourseByCategory)
+	e.POST("/category", handler.CreateCategory, auth)
+
+	// Routing Curriculum
+	e.POST("/course/:courseID/section", handler.CreateSection, auth)
+	e.PUT("/course/:courseID/section-order", handler.ReorderSection, auth)
+	e.PATCH("/section/:sectionID", handler.UpdateSection, auth)
+	e.DELETE("/section/:sectionID", handler.DeleteSection, auth)
+	e.POST("/section/:sectionID/lesson", handler.CreateLesson, auth)
+	e.PUT("/section/:sectionID/lesson-order", handler.ReorderLesson, auth)
+	e.GET("/lesson/:lessonID", handler.GetLesson, optionalAuth)
+	e.PATCH("/lesson/:lessonID", handler.UpdateLesson, auth)
+	e.DELETE("/lesson/:lessonID", handler.DeleteLesson, auth)
+
+	// Routing Enrollment
+	e.POST("/course/:courseID/enroll", handler.Enroll, auth)
+	e.DELETE("/course/:courseID/enroll", handler.Unenroll, auth)
+	e.GET("/course/:courseID/enrollment", handler.GetRoster, auth)
+	e.GET("/me/courses", handler.GetMyCourse, auth)
+	e.GET("/course/:courseID/progress", handler.GetCourseProgress, auth)
+	e.PUT("/lesson/:lessonID/progress", handler.SaveProgress, auth)
+
+	// Routing Quiz
+	e.GET("/lesson/:lessonID/quiz", handler.GetQuiz, optionalAuth)
+	e.PUT("/lesson/:lessonID/quiz", handler.SaveQuiz, auth)
+	e.POST("/lesson/:lessonID/question", handler.CreateQuestion, auth)
+	e.PUT("/lesson/:lessonID/question-order", handler.ReorderQuestion, auth)
+	e.PATCH("/question/:questionID", handler.UpdateQuestion, auth)
+	e.DELETE("/question/:questionID", handler.DeleteQuestion, auth)
+	e.POST("/lesson/:lessonID/attempt", handler.StartAttempt, auth)
+	e.GET("/lesson/:lessonID/attempt", handler.GetQuizStatus, auth)
+	e.POST("/attempt/:attemptID/submit", handler.SubmitAttempt, auth)
+
+	// Routing Assignment
+	e.GET("/lesson/:lessonID/assignment", handler.GetAssignment, optionalAuth)
+	e.PUT("/lesson/:lessonID/assignment", handler.SaveAssignment, auth)
+	e.POST("/lesson/:lessonID/submission", handler.Submit, auth)
+	e.GET("/lesson/:lessonID/submission", handler.GetMySubmission, auth)
+	e.GET("/submission/:submissionID", handler.GetSubmission, auth)
+	e.GET("/submission/:submissionID/file/:fileID", handler.DownloadFile, auth)
+	e.PUT("/submission/:submissionID/grade", handler.GradeSubmission, auth)
+	e.GET("/course/:courseID/gradebook", handler.GetGradebook, auth)
+
+	// Routing Notification
+	e.GET("/me/notifications", handler.GetNotification, auth)
+	e.PUT("/notification/:notificationID/read", handler.ReadNotification, auth)
+
+	// Routing Certificate
+	e.GET("/me/certificates", handler.GetMyCertificate, auth)
+	e.GET("/certificates/:certificateID/pdf", handler.DownloadCertificate, auth)
+	e.GET("/certificates/:certificateID/verify", handler.VerifyCertificate)
+	e.POST("/certificates/:certificateID/revoke", handler.RevokeCertificate, auth)
+
+	// Routing Review
+	e.GET("/course/:courseID/review", handler.GetReview, optionalAuth)
+	e.PUT("/course/:courseID/review", handler.SaveReview, auth)
+	e.DELETE("/course/:courseID/review", handler.DeleteReview, auth)
+	e.PUT("/review/:reviewID/reply", handler.ReplyReview, auth)
+	e.DELETE("/review/:reviewID/reply", handler.DeleteReply, auth)
+	e.PUT("/review/:reviewID/helpful", handler.VoteReview, auth)
+	e.DELETE("/review/:reviewID/helpful", handler.VoteReview, auth)
+	e.POST("/review/:reviewID/flag", handler.FlagReview, auth)
+	e.PUT("/review/:reviewID/moderation", handler.ModerateReview, auth)
+	e.GET("/review-flagged", handler.GetFlaggedReview, auth)
+
+	// Routing Order
+	e.GET("/me/cart", handler.GetCart, auth)
+	e.POST("/me/cart", handler.AddToCart, auth)
+	e.DELETE("/me/cart/:courseID", handler.RemoveFromCart, auth)
+	e.POST("/me/checkout", handler.Checkout, auth)
+	e.GET("/me/orders", handler.GetMyOrder, auth)
+	e.GET("/order/:orderID", handler.GetOrder, auth)
+	e.PUT("/order/:orderID/status", handler.UpdateOrderStatus, auth)
+	e.POST("/order/:orderID/payment", handler.PayOrder, auth)
+	e.POST("/order/:orderID/refund", handler.RefundOrder, auth)
+
+	// Routing Payment webhook, authenticated by its signature
+	e.POST("/webhooks/payments", handler.PaymentWebhook)
+
+	// Routing fake payments, played by the customer in development
+	if cfg.Payment.Development {
+		e.POST("/payment/:paymentID/confirm", handler.ConfirmPayment, auth)
+		e.POST("/payment/:paymentID/decline", handler.DeclinePayment, auth)
+	}
+
+	// Routing Coupon
+	e.GET("/coupon", handler.GetCoupon, auth)
+	e.GET("/coupon/:couponID", handler.GetDetailCoupon, auth)
+	e.POST("/coupon", handler.CreateCoupon, auth)
+	e.PUT("/coupon/:couponID", handler.UpdateCoupon, auth)
+	e.DELETE("/coupon/:couponID", handler.DeleteCoupon, auth)
+
+	e.GET("/statistic", handler.GetStatistic, auth)
+
+	// Routing Monitoring
+	e.GET("/healthz", handler.Healthz)
+	e.GET("/readyz", handler.Readyz, optionalAuth)
+	e.GET("/pool-stats", handler.GetPoolStats, auth)
+	e.GET("/metrics", handler.GetMetrics)
 
 }
 
@@ -68,12 +201,22 @@ func (h *Handler) GetDetailCourse(c echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
-	res, err := h.CourseUsecae.GetDetailCourse(c.Request().Context(), courseID)
+	userID := 0
+	if userInfo, ok := c.Get("user").(*model.Token); ok {
+		userID = userInfo.UserID
+		c.Response().Header().Set("Cache-Control", "private")
+	}
+
+	res, err := h.CourseUsecae.GetDetailCourse(c.Request().Context(), courseID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responseError{
-			Message: "internal error",
-		})
-		return echo.ErrInternalServerError
+		return errorResponse(c, err)
+	}
+
+	etag := detailETag(res)
+	c.Response().Header().Set("ETag", etag)
+
+	if ifNoneMatch(c, etag) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -122,8 +265,9 @@ func (h *Handler) SendCourse(c echo.Context) error {
 	return c.JSON(http.StatusCreated, res)
 }
 
+// UpdateCourse takes a JSON Merge Patch body, fields left out keep their
+// value. An If-Match header makes the update conditional on the ETag.
 func (h *Handler) UpdateCourse(c echo.Context) error {
-	dataReq := model.CourseUpdate{}
 	courseIDParam := c.Param("courseID")
 
 	userInfo := c.Get("user").(*model.Token)
@@ -150,22 +294,26 @@ func (h *Handler) UpdateCourse(c echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
-	if err := c.Bind(&dataReq); err != nil {
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return errorResponse(c, model.ErrConflict)
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, responseError{
 			Message: "invalid data request",
 		})
 		return echo.ErrBadRequest
 	}
 
-	res, err := h.CourseUsecae.UpdateCourse(c.Request().Context(), dataReq, courseID)
+	res, err := h.CourseUsecae.UpdateCourse(c.Request().Context(), patch, courseID, ifMatch)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responseError{
-			Message: "internal error",
-		})
-
-		return echo.ErrInternalServerError
+		return errorResponse(c, err)
 	}
 
+	c.Response().Header().Set("ETag", versionETag(res.Version))
+
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -196,13 +344,14 @@ func (h *Handler) DeleteCourse(c echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
-	err = h.CourseUsecae.DeleteCourse(c.Request().Context(), courseID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, responseError{
-			Message: "internal error",
-		})
+	ifMatch, ok := ifMatchVersion(c)
+	if !ok {
+		return errorResponse(c, model.ErrConflict)
+	}
 
-		return echo.ErrInternalServerError
+	err = h.CourseUsecae.DeleteCourse(c.Request().Context(), courseID, ifMatch)
+	if err != nil {
+		return errorResponse(c, err)
 	}
 
 	return c.JSON(http.StatusOK, responseError{
@@ -246,6 +395,14 @@ func (h *Handler) SortCourse(c echo.Context) error {
 
 	res, err := h.CourseUsecae.SortCourse(c.Request().Context(), sort)
 	if err != nil {
+		if errors.Is(err, model.ErrBadParamInput) {
+			c.JSON(http.StatusBadRequest, responseError{
+				Message: "invalid parameter",
+			})
+
+			return echo.ErrBadRequest
+		}
+
 		c.JSON(http.StatusInternalServerError, responseError{
 			Message: "internal error",
 		})
@@ -389,8 +546,28 @@ func (h *Handler) GetPopularCategory(c echo.Context) error {
 		return echo.ErrBadRequest
 	}
 
-	res, err := h.CourseUsecae.GetPopularCategory(c.Request().Context(), limit)
+	days := constant.PopularCategoryWindowDays
+	if daysParam := c.QueryParam("days"); daysParam != "" {
+		days, err = strconv.Atoi(daysParam)
+		if err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, responseError{
+				Message: "invalid parameter",
+			})
+
+			return echo.ErrBadRequest
+		}
+	}
+
+	res, err := h.CourseUsecae.GetPopularCategory(c.Request().Context(), limit, time.Duration(days)*24*time.Hour)
 	if err != nil {
+		if errors.Is(err, model.ErrBadParamInput) {
+			c.JSON(http.StatusBadRequest, responseError{
+				Message: "invalid parameter",
+			})
+
+			return echo.ErrBadRequest
+		}
+
 		c.JSON(http.StatusInternalServerError, responseError{
 			Message: "internal error",
 		})
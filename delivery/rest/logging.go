@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/egaevan/online-learning/logging"
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRequestIDLength bounds an incoming X-Request-ID so clients can't flood
+// the logs.
+const maxRequestIDLength = 128
+
+func logger(ctx context.Context) *log.Entry {
+	return logging.From(ctx, "rest")
+}
+
+// RequestID reuses the X-Request-ID header of the request, or generates
+// one, echoes it in the response and adds it to the context logger together
+// with the route.
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		id := req.Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+		ctx := logging.WithFields(req.Context(), log.Fields{
+			"request_id": id,
+			"method":     req.Method,
+			"route":      c.Path(),
+		})
+		c.SetRequest(req.WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// AccessLog logs every request with its status and latency once it is
+// served. It must run after RequestID.
+func AccessLog(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		entry := logger(c.Request().Context()).WithFields(log.Fields{
+			"path":       c.Request().URL.Path,
+			"status":     responseStatus(c, err),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		})
+
+		entry.Info("request served")
+
+		return err
+	}
+}
+
+// withUser adds the user of tk to the context logger of the request.
+func withUser(c echo.Context, tk *model.Token) {
+	ctx := logging.WithFields(c.Request().Context(), log.Fields{
+		"user_id": tk.UserID,
+	})
+	c.SetRequest(c.Request().WithContext(ctx))
+}
@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/egaevan/online-learning/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	httpRequestTotal = metrics.NewCounterVec(
+		"online_learning_http_requests_total",
+		"HTTP requests by method, route and status.",
+		"method", "route", "status",
+	)
+
+	httpRequestDuration = metrics.NewHistogramVec(
+		"online_learning_http_request_duration_seconds",
+		"HTTP request latency by method, route and status.",
+		nil, "method", "route", "status",
+	)
+)
+
+// Metrics counts and times every request by its route pattern, so
+// /course/1 and /course/2 share the /course/:courseID series.
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		route := c.Path()
+		status := responseStatus(c, err)
+
+		if !c.Response().Committed && errors.Is(err, echo.ErrNotFound) {
+			// Echo keeps the raw path when no route matched, one series
+			// per unknown URL would grow without bound.
+			route = "unmatched"
+		}
+
+		labels := []string{c.Request().Method, route, strconv.Itoa(status)}
+		httpRequestTotal.With(labels...).Inc()
+		httpRequestDuration.With(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// GetMetrics writes every metric in the Prometheus text format.
+func (h *Handler) GetMetrics(c echo.Context) error {
+	if err := h.MonitorUsecae.CollectMetrics(c.Request().Context()); err != nil {
+		logger(c.Request().Context()).Warnf("business metrics are stale: %v", err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, metrics.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+
+	_, err := metrics.DefaultRegistry.WriteTo(c.Response())
+
+	return err
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -14,34 +15,66 @@ type Exception struct {
 	Message string `json:"message"`
 }
 
-func JwtVerify(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
+// JwtVerify checks the x-access-token header against the signing secret.
+// The secret is read on every request so a reloaded secret applies at once.
+func JwtVerify(secret model.Secret) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var header = c.Request().Header.Get("x-access-token") // Grab the token from the header
 
-		var header = c.Request().Header.Get("x-access-token") // Grab the token from the header
+			header = strings.TrimSpace(header)
 
-		header = strings.TrimSpace(header)
+			if header == "" {
+				// Token is missing, returns with error code 403 Unauthorized
+				return c.JSON(http.StatusForbidden, Exception{
+					Message: "Missing auth token"},
+				)
+			}
 
-		if header == "" {
-			// Token is missing, returns with error code 403 Unauthorized
-			return c.JSON(http.StatusForbidden, Exception{
-				Message: "Missing auth token"},
-			)
+			tk, err := parseToken(header, secret)
+			if err != nil {
+				return c.JSON(http.StatusForbidden, Exception{
+					Message: err.Error()},
+				)
+			}
+
+			c.Set("user", tk)
+			withUser(c, tk)
+
+			return next(c)
 		}
+	}
+}
+
+// JwtOptional sets the user like JwtVerify when a valid token is sent, and
+// lets anonymous requests through.
+func JwtOptional(secret model.Secret) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := strings.TrimSpace(c.Request().Header.Get("x-access-token"))
 
-		tk := &model.Token{}
+			if header != "" {
+				if tk, err := parseToken(header, secret); err == nil {
+					c.Set("user", tk)
+					withUser(c, tk)
+				}
+			}
 
-		_, err := jwt.ParseWithClaims(header, tk, func(token *jwt.Token) (interface{}, error) {
-			return []byte("secret"), nil
-		})
+			return next(c)
+		}
+	}
+}
 
-		if err != nil {
-			return c.JSON(http.StatusForbidden, Exception{
-				Message: err.Error()},
-			)
+func parseToken(header string, secret model.Secret) (*model.Token, error) {
+	tk := &model.Token{}
+
+	_, err := jwt.ParseWithClaims(header, tk, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
 		}
 
-		c.Set("user", tk)
+		return []byte(secret.Value()), nil
+	})
 
-		return next(c)
-	}
+	return tk, err
 }
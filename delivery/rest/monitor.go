@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) GetPoolStats(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	res, err := h.MonitorUsecae.GetPoolStats(c.Request().Context())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Healthz answers as long as the process serves requests.
+func (h *Handler) Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.HealthReport{
+		Status: model.HealthUp,
+	})
+}
+
+// Readyz runs the readiness checks. Check errors are only shown to admins
+// asking for ?verbose=true.
+func (h *Handler) Readyz(c echo.Context) error {
+	res := h.MonitorUsecae.Readiness(c.Request().Context())
+
+	userInfo, _ := c.Get("user").(*model.Token)
+	verbose, _ := strconv.ParseBool(c.QueryParam("verbose"))
+
+	if !verbose || userInfo == nil || userInfo.Role != isAdmin {
+		for i := range res.Checks {
+			res.Checks[i].Error = ""
+		}
+	}
+
+	status := http.StatusOK
+	if res.Status != model.HealthUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(status, res)
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetNotification lists the notifications of the user, newest first.
+func (h *Handler) GetNotification(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	res, err := h.NotificationUsecae.GetNotification(c.Request().Context(), userInfo.UserID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) ReadNotification(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	notificationID, err := pathID(c, "notificationID")
+	if err != nil {
+		return err
+	}
+
+	err = h.NotificationUsecae.ReadNotification(c.Request().Context(), userInfo.UserID, notificationID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Notification has been read",
+	})
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// maxWebhookSize bounds the body of payment webhook events.
+const maxWebhookSize = 1 << 20
+
+// GetCart shows the cart of the user, priced with the coupon code of the
+// coupon query parameter when it is set.
+func (h *Handler) GetCart(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	res, err := h.OrderUsecae.GetCart(c.Request().Context(), userInfo.UserID, c.QueryParam("coupon"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// AddToCart takes the course to put into the cart of the user.
+func (h *Handler) AddToCart(c echo.Context) error {
+	dataReq := model.CartRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.OrderUsecae.AddToCart(c.Request().Context(), userInfo.UserID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) RemoveFromCart(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.OrderUsecae.RemoveFromCart(c.Request().Context(), userInfo.UserID, courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Checkout turns the cart into a pending order, the body may name a
+// coupon.
+func (h *Handler) Checkout(c echo.Context) error {
+	dataReq := model.CheckoutRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.OrderUsecae.Checkout(c.Request().Context(), userInfo.UserID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GetMyOrder lists the orders of the user, newest first.
+func (h *Handler) GetMyOrder(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	res, err := h.OrderUsecae.GetMyOrder(c.Request().Context(), userInfo.UserID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetOrder(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	orderID, err := pathID(c, "orderID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.OrderUsecae.GetOrder(c.Request().Context(), orderID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// UpdateOrderStatus lets admins move an order to another status.
+func (h *Handler) UpdateOrderStatus(c echo.Context) error {
+	dataReq := model.StatusRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	orderID, err := pathID(c, "orderID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.OrderUsecae.UpdateStatus(c.Request().Context(), orderID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// PayOrder starts a payment of a pending order of the user.
+func (h *Handler) PayOrder(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	orderID, err := pathID(c, "orderID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.OrderUsecae.Pay(c.Request().Context(), userInfo.UserID, orderID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// RefundOrder lets admins pay an order back. The order is refunded once
+// the payment provider confirms it.
+func (h *Handler) RefundOrder(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	orderID, err := pathID(c, "orderID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.OrderUsecae.Refund(c.Request().Context(), orderID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, res)
+}
+
+// ConfirmPayment confirms a payment of the user at the fake gateway, as
+// the customer would at a real provider. Development only.
+func (h *Handler) ConfirmPayment(c echo.Context) error {
+	return h.simulatePayment(c, true)
+}
+
+// DeclinePayment declines a payment of the user at the fake gateway.
+// Development only.
+func (h *Handler) DeclinePayment(c echo.Context) error {
+	return h.simulatePayment(c, false)
+}
+
+func (h *Handler) simulatePayment(c echo.Context, confirm bool) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	err := h.OrderUsecae.SimulatePayment(c.Request().Context(), userInfo.UserID, c.Param("paymentID"), confirm)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, responseError{
+		Message: "Payment has been sent to the provider",
+	})
+}
+
+// PaymentWebhook receives the events of the payment provider. The body is
+// read as is, the signature covers its exact bytes.
+func (h *Handler) PaymentWebhook(c echo.Context) error {
+	payload, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	signature := c.Request().Header.Get(constant.PaymentSignatureHeader)
+
+	if err := h.OrderUsecae.HandleWebhook(c.Request().Context(), payload, signature); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Event has been received",
+	})
+}
@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetQuiz serves the quiz with its answer keys to admins and the quiz
+// settings to the users allowed to follow the lesson.
+func (h *Handler) GetQuiz(c echo.Context) error {
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	userInfo, _ := c.Get("user").(*model.Token)
+
+	res, err := h.QuizUsecae.GetQuiz(c.Request().Context(), lessonID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SaveQuiz sets the quiz settings of a quiz lesson.
+func (h *Handler) SaveQuiz(c echo.Context) error {
+	dataReq := model.Quiz{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.QuizUsecae.SaveQuiz(c.Request().Context(), lessonID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateQuestion(c echo.Context) error {
+	dataReq := model.Question{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.QuizUsecae.CreateQuestion(c.Request().Context(), lessonID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// UpdateQuestion takes a JSON Merge Patch body.
+func (h *Handler) UpdateQuestion(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	questionID, err := pathID(c, "questionID")
+	if err != nil {
+		return err
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.QuizUsecae.UpdateQuestion(c.Request().Context(), patch, questionID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteQuestion(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	questionID, err := pathID(c, "questionID")
+	if err != nil {
+		return err
+	}
+
+	err = h.QuizUsecae.DeleteQuestion(c.Request().Context(), questionID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Question has been deleted",
+	})
+}
+
+// ReorderQuestion takes every question id of the quiz in the new order.
+func (h *Handler) ReorderQuestion(c echo.Context) error {
+	dataReq := model.Reorder{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.QuizUsecae.ReorderQuestion(c.Request().Context(), lessonID, dataReq.Ids)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// StartAttempt starts an attempt at the quiz, or resumes the open one, and
+// sends the questions with it.
+func (h *Handler) StartAttempt(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.QuizUsecae.StartAttempt(c.Request().Context(), userInfo.UserID, lessonID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *Handler) SubmitAttempt(c echo.Context) error {
+	dataReq := model.QuizSubmission{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	attemptID, err := pathID(c, "attemptID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.QuizUsecae.SubmitAttempt(c.Request().Context(), userInfo.UserID, attemptID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetQuizStatus(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	lessonID, err := pathID(c, "lessonID")
+	if err != nil {
+		return err
+	}
+
+	res, err := h.QuizUsecae.GetQuizStatus(c.Request().Context(), userInfo.UserID, lessonID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,218 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetReview lists the reviews of a course, newest first.
+func (h *Handler) GetReview(c echo.Context) error {
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	userInfo, _ := c.Get("user").(*model.Token)
+
+	res, err := h.ReviewUsecae.GetReview(c.Request().Context(), courseID, userInfo)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SaveReview creates or edits the review of the user for a course.
+func (h *Handler) SaveReview(c echo.Context) error {
+	dataReq := model.ReviewRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.ReviewUsecae.SaveReview(c.Request().Context(), userInfo.UserID, courseID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteReview(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	courseID, err := pathID(c, "courseID")
+	if err != nil {
+		return err
+	}
+
+	err = h.ReviewUsecae.DeleteReview(c.Request().Context(), userInfo.UserID, courseID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Review has been deleted",
+	})
+}
+
+// ReplyReview sets the instructor reply to a review.
+func (h *Handler) ReplyReview(c echo.Context) error {
+	dataReq := model.ReplyRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	reviewID, err := pathID(c, "reviewID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.ReviewUsecae.ReplyReview(c.Request().Context(), userInfo.UserID, reviewID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) DeleteReply(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	reviewID, err := pathID(c, "reviewID")
+	if err != nil {
+		return err
+	}
+
+	err = h.ReviewUsecae.DeleteReply(c.Request().Context(), reviewID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Reply has been deleted",
+	})
+}
+
+// VoteReview marks a review as helpful, DELETE takes the vote back.
+func (h *Handler) VoteReview(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	reviewID, err := pathID(c, "reviewID")
+	if err != nil {
+		return err
+	}
+
+	helpful := c.Request().Method != http.MethodDelete
+
+	res, err := h.ReviewUsecae.VoteReview(c.Request().Context(), userInfo, reviewID, helpful)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// FlagReview reports a review to the moderators.
+func (h *Handler) FlagReview(c echo.Context) error {
+	dataReq := model.FlagRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	reviewID, err := pathID(c, "reviewID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	err = h.ReviewUsecae.FlagReview(c.Request().Context(), userInfo, reviewID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responseError{
+		Message: "Review has been flagged",
+	})
+}
+
+// ModerateReview hides a review or shows it again.
+func (h *Handler) ModerateReview(c echo.Context) error {
+	dataReq := model.ModerationRequest{}
+
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	reviewID, err := pathID(c, "reviewID")
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&dataReq); err != nil {
+		c.JSON(http.StatusBadRequest, responseError{
+			Message: "invalid data request",
+		})
+		return echo.ErrBadRequest
+	}
+
+	res, err := h.ReviewUsecae.ModerateReview(c.Request().Context(), reviewID, dataReq)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetFlaggedReview lists the reviews waiting for moderation.
+func (h *Handler) GetFlaggedReview(c echo.Context) error {
+	userInfo := c.Get("user").(*model.Token)
+
+	if userInfo.Role != isAdmin {
+		// unauthorized
+		return echo.ErrUnauthorized
+	}
+
+	res, err := h.ReviewUsecae.GetFlaggedReview(c.Request().Context())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
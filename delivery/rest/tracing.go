@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/egaevan/online-learning/delivery/rest")
+
+// Tracing starts a server span per request, continuing the trace of the
+// caller when the request carries a W3C traceparent header.
+func Tracing(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := c.Path()
+		ctx, span := tracer.Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, req)...),
+		)
+		defer span.End()
+
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+
+		status := responseStatus(c, err)
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return err
+	}
+}
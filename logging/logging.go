@@ -0,0 +1,148 @@
+// Package logging hands out logrus loggers with a level per package and
+// carries request fields, such as the request ID, in the context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	mu        sync.Mutex
+	loggers                 = map[string]*log.Logger{}
+	levels                  = map[string]log.Level{}
+	level                   = log.InfoLevel
+	formatter log.Formatter = &log.TextFormatter{FullTimestamp: true}
+)
+
+// Configure sets the format and the default level of every logger, the
+// standard logrus logger included. packages overrides the level per package
+// as a comma separated list such as "usecase=debug,repository=warn".
+func Configure(defaultLevel, format, packages string) error {
+	lvl, err := log.ParseLevel(defaultLevel)
+	if err != nil {
+		return err
+	}
+
+	pkgLevels, err := ParseLevels(packages)
+	if err != nil {
+		return err
+	}
+
+	var f log.Formatter = &log.TextFormatter{FullTimestamp: true}
+	if format == "json" {
+		f = &log.JSONFormatter{}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	level, levels, formatter = lvl, pkgLevels, f
+
+	log.SetLevel(level)
+	log.SetFormatter(formatter)
+
+	for pkg, l := range loggers {
+		apply(pkg, l)
+	}
+
+	return nil
+}
+
+// ParseLevels parses the per package levels accepted by Configure.
+func ParseLevels(packages string) (map[string]log.Level, error) {
+	result := map[string]log.Level{}
+
+	for _, item := range strings.Split(packages, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pair := strings.SplitN(item, "=", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("%q is not package=level", item)
+		}
+
+		lvl, err := log.ParseLevel(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		result[strings.TrimSpace(pair[0])] = lvl
+	}
+
+	return result, nil
+}
+
+// Logger returns the logger of pkg, e.g. "usecase".
+func Logger(pkg string) *log.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	l, ok := loggers[pkg]
+	if !ok {
+		l = log.New()
+		apply(pkg, l)
+		loggers[pkg] = l
+	}
+
+	return l
+}
+
+// apply copies the settings to l. The caller must hold mu.
+func apply(pkg string, l *log.Logger) {
+	l.SetFormatter(formatter)
+	l.SetOutput(log.StandardLogger().Out)
+
+	if lvl, ok := levels[pkg]; ok {
+		l.SetLevel(lvl)
+	} else {
+		l.SetLevel(level)
+	}
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a context whose loggers add fields to every entry, on
+// top of the fields already in ctx.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	merged := log.Fields{}
+
+	for k, v := range contextFields(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func contextFields(ctx context.Context) log.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(log.Fields)
+
+	return fields
+}
+
+// From returns the logger of pkg with the fields of ctx, and the trace ID
+// when ctx carries a recorded span.
+func From(ctx context.Context, pkg string) *log.Entry {
+	entry := log.NewEntry(Logger(pkg)).WithField("package", pkg)
+
+	if fields := contextFields(ctx); len(fields) > 0 {
+		entry = entry.WithFields(fields)
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		entry = entry.WithField("trace_id", sc.TraceID().String())
+	}
+
+	return entry.WithContext(ctx)
+}
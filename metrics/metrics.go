@@ -0,0 +1,220 @@
+// Package metrics is a small Prometheus client: counters, gauges and
+// histograms with labels, exposed in the text exposition format.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are latency buckets in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metric is a metric family written on every scrape.
+type Metric interface {
+	name() string
+	write(*bytes.Buffer)
+}
+
+// Registry holds metric families and writes them for a scrape.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]Metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: map[string]Metric{},
+	}
+}
+
+// DefaultRegistry is the registry the New* constructors register with.
+var DefaultRegistry = NewRegistry()
+
+// Register adds f. It panics when a metric of the same name exists, like a
+// duplicate route would.
+func (r *Registry) Register(f Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.families[f.name()]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", f.name()))
+	}
+
+	r.families[f.name()] = f
+}
+
+// Unregister removes the metric called name, if any.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.families, name)
+}
+
+// WriteTo writes every metric ordered by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := make([]Metric, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name() < families[j].name()
+	})
+
+	var buf bytes.Buffer
+	for _, f := range families {
+		f.write(&buf)
+	}
+
+	return buf.WriteTo(w)
+}
+
+// desc is the name, help and label names shared by every metric type.
+type desc struct {
+	metricName string
+	help       string
+	typ        string
+	labels     []string
+}
+
+func (d *desc) name() string {
+	return d.metricName
+}
+
+func (d *desc) header(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", d.metricName, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help))
+	fmt.Fprintf(buf, "# TYPE %s %s\n", d.metricName, d.typ)
+}
+
+// labelPairs renders the labels of one series, extra is appended as is.
+func (d *desc) labelPairs(values []string, extra string) string {
+	pairs := make([]string, 0, len(values)+1)
+
+	for i, v := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labels[i], escapeLabel(v)))
+	}
+
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// vec keeps one series per combination of label values.
+type vec struct {
+	desc
+
+	mu     sync.Mutex
+	series map[string]interface{}
+	values map[string][]string
+	create func() interface{}
+}
+
+func newVec(name, help, typ string, labels []string, create func() interface{}) vec {
+	return vec{
+		desc:   desc{metricName: name, help: help, typ: typ, labels: labels},
+		series: map[string]interface{}{},
+		values: map[string][]string{},
+		create: create,
+	}
+}
+
+func (v *vec) with(values []string) interface{} {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", v.metricName, len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = v.create()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), values...)
+	}
+
+	return s
+}
+
+// each calls fn for every series ordered by label values.
+func (v *vec) each(fn func(values []string, series interface{})) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	v.mu.Unlock()
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.mu.Lock()
+		values, series := v.values[k], v.series[k]
+		v.mu.Unlock()
+
+		fn(values, series)
+	}
+}
+
+// value is a float64 updated atomically.
+type value struct {
+	bits uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&v.bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+
+		if atomic.CompareAndSwapUint64(&v.bits, old, next) {
+			return
+		}
+	}
+}
+
+func (v *value) set(f float64) {
+	atomic.StoreUint64(&v.bits, math.Float64bits(f))
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&v.bits))
+}
@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Counter only goes up.
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add increases the counter, delta must not be negative.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+
+	c.v.add(delta)
+}
+
+type CounterVec struct {
+	vec
+}
+
+// NewCounterVec registers a counter with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, "counter", labels, func() interface{} { return &Counter{} })}
+	DefaultRegistry.Register(c)
+
+	return c
+}
+
+// With returns the counter of the label values, in label name order.
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.with(values).(*Counter)
+}
+
+func (c *CounterVec) write(buf *bytes.Buffer) {
+	c.header(buf)
+	c.each(func(values []string, s interface{}) {
+		fmt.Fprintf(buf, "%s%s %s\n", c.metricName, c.labelPairs(values, ""), formatFloat(s.(*Counter).v.get()))
+	})
+}
+
+// Gauge goes up and down.
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(v float64) {
+	g.v.set(v)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.add(delta)
+}
+
+type GaugeVec struct {
+	vec
+}
+
+// NewGaugeVec registers a gauge with the given label names.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, "gauge", labels, func() interface{} { return &Gauge{} })}
+	DefaultRegistry.Register(g)
+
+	return g
+}
+
+// With returns the gauge of the label values, in label name order.
+func (g *GaugeVec) With(values ...string) *Gauge {
+	return g.with(values).(*Gauge)
+}
+
+func (g *GaugeVec) write(buf *bytes.Buffer) {
+	g.header(buf)
+	g.each(func(values []string, s interface{}) {
+		fmt.Fprintf(buf, "%s%s %s\n", g.metricName, g.labelPairs(values, ""), formatFloat(s.(*Gauge).v.get()))
+	})
+}
+
+// GaugeFunc reads its value at scrape time.
+type GaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc returns a gauge reading fn on every scrape. It is not
+// registered, pass it to Registry.Register, so that gauges over resources
+// such as a database can be removed again with Unregister.
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return &GaugeFunc{
+		desc: desc{metricName: name, help: help, typ: "gauge"},
+		fn:   fn,
+	}
+}
+
+func (g *GaugeFunc) write(buf *bytes.Buffer) {
+	g.header(buf)
+	fmt.Fprintf(buf, "%s %s\n", g.metricName, formatFloat(g.fn()))
+}
+
+// CounterFunc reads a running total kept elsewhere at scrape time, such as
+// the wait count of a connection pool.
+type CounterFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewCounterFunc returns a counter reading fn on every scrape. Like
+// NewGaugeFunc it is not registered.
+func NewCounterFunc(name, help string, fn func() float64) *CounterFunc {
+	return &CounterFunc{
+		desc: desc{metricName: name, help: help, typ: "counter"},
+		fn:   fn,
+	}
+}
+
+func (c *CounterFunc) write(buf *bytes.Buffer) {
+	c.header(buf)
+	fmt.Fprintf(buf, "%s %s\n", c.metricName, formatFloat(c.fn()))
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	upper   []float64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i < len(h.buckets) {
+		h.buckets[i]++
+	}
+
+	h.sum += v
+	h.count++
+}
+
+type HistogramVec struct {
+	vec
+	upper []float64
+}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds
+// and label names. nil buckets means DefaultBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	upper := append([]float64(nil), buckets...)
+	sort.Float64s(upper)
+
+	h := &HistogramVec{upper: upper}
+	h.vec = newVec(name, help, "histogram", labels, func() interface{} {
+		return &Histogram{upper: upper, buckets: make([]uint64, len(upper))}
+	})
+	DefaultRegistry.Register(h)
+
+	return h
+}
+
+// With returns the histogram of the label values, in label name order.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.with(values).(*Histogram)
+}
+
+func (h *HistogramVec) write(buf *bytes.Buffer) {
+	h.header(buf)
+	h.each(func(values []string, s interface{}) {
+		hist := s.(*Histogram)
+
+		hist.mu.Lock()
+		buckets := append([]uint64(nil), hist.buckets...)
+		sum, count := hist.sum, hist.count
+		hist.mu.Unlock()
+
+		var cumulative uint64
+		for i, upper := range h.upper {
+			cumulative += buckets[i]
+			fmt.Fprintf(buf, "%s_bucket%s %d\n", h.metricName, h.labelPairs(values, `le="`+formatFloat(upper)+`"`), cumulative)
+		}
+
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", h.metricName, h.labelPairs(values, `le="+Inf"`), count)
+		fmt.Fprintf(buf, "%s_sum%s %s\n", h.metricName, h.labelPairs(values, ""), formatFloat(sum))
+		fmt.Fprintf(buf, "%s_count%s %d\n", h.metricName, h.labelPairs(values, ""), count)
+	})
+}
@@ -0,0 +1,466 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/logging"
+	"github.com/egaevan/online-learning/repository"
+)
+
+// Every dialect has its own directory under sql with the same versions.
+//
+//go:embed sql
+var files embed.FS
+
+var ErrSchemaBehind = errors.New("database schema is behind, run the migrate up command")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *repository.Database
+	Migrations []Migration
+}
+
+func NewMigrator(db *repository.Database) (*Migrator, error) {
+	migrations, err := load(files, path.Join("sql", string(db.Dialect)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// load reads <version>_<name>.up.sql and <version>_<name>.down.sql pairs from
+// dir, ordered by version.
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[int]*Migration{}
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", fileName)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", fileName)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := index[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			index[version] = m
+		}
+
+		if m.Name != parts[1] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, m.Name, parts[1])
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(index))
+	for _, m := range index {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", m.Version, m.Name)
+		}
+
+		result = append(result, *m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// Init creates the table recording the applied migrations when it is
+// missing. Up, Down and To call it, everything else only reads the table,
+// so run Init once at startup before them.
+func (m *Migrator) Init(ctx context.Context) error {
+	timestamp := "DATETIME"
+	if m.DB.Dialect == repository.PostgreSQL {
+		timestamp = "TIMESTAMP"
+	}
+
+	query := `
+			CREATE TABLE IF NOT EXISTS schema_migration (
+				version INT NOT NULL PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				applied_at %s NOT NULL
+			)`
+
+	_, err := m.DB.ExecContext(ctx, fmt.Sprintf(query, timestamp))
+
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	query := `
+			SELECT
+				version,
+				applied_at
+			FROM
+				schema_migration`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logging.From(ctx, "migration").Error(errRow)
+		}
+	}()
+
+	result := map[int]time.Time{}
+
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+// Version returns the highest applied migration version, 0 for an empty
+// database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+
+	return version, nil
+}
+
+// Latest returns the version of the newest embedded migration.
+func (m *Migrator) Latest() int {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		appliedAt, ok := applied[migration.Version]
+		result = append(result, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Check returns ErrSchemaBehind when an embedded migration has not been
+// applied yet. It only reads, so it is cheap enough for readiness probes.
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		if !s.Applied {
+			return fmt.Errorf("%w: migration %d_%s is pending", ErrSchemaBehind, s.Version, s.Name)
+		}
+	}
+
+	return nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	if err := m.Init(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[m.Migrations[i].Version]; ok {
+			return m.revert(ctx, m.Migrations[i])
+		}
+	}
+
+	return nil
+}
+
+// To migrates up or down until exactly the migrations up to version are
+// applied. Version 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version < 0 || (version > 0 && m.find(version) < 0) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	if err := m.Init(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+
+		if err := m.revert(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+
+		if err := m.apply(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) find(version int) int {
+	for i, migration := range m.Migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	logging.From(ctx, "migration").Infof("applying migration %d_%s", migration.Version, migration.Name)
+
+	return m.run(ctx, migration, migration.Up, func(tx *sql.Tx) error {
+		return m.exec(ctx, tx, `INSERT INTO schema_migration (version, name, applied_at) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, time.Now().UTC())
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	logging.From(ctx, "migration").Infof("reverting migration %d_%s", migration.Version, migration.Name)
+
+	return m.run(ctx, migration, migration.Down, func(tx *sql.Tx) error {
+		return m.exec(ctx, tx, `DELETE FROM schema_migration WHERE version = ?`, migration.Version)
+	})
+}
+
+func (m *Migrator) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	_, err := tx.ExecContext(ctx, m.DB.Dialect.Rebind(query), m.DB.Dialect.Args(args)...)
+
+	return err
+}
+
+// run executes script statement by statement and records the result in one
+// transaction. Databases that commit DDL implicitly (MySQL) can't roll the
+// script back, so a failed migration there has to be fixed by hand.
+func (m *Migrator) run(ctx context.Context, migration Migration, script string, record func(*sql.Tx) error) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits a script on semicolons that are not inside quotes,
+// comments or PostgreSQL dollar quoted bodies such as $$ ... $$ of a
+// function. Comments are dropped, so MySQL /*! ... */ hints are not
+// supported.
+func splitStatements(script string) []string {
+	var (
+		result  []string
+		current strings.Builder
+		quote   rune
+	)
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+			continue
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := indexRunes(runes, i+2, "*/")
+			i = end + 1
+			// The comment may be the only thing between two words.
+			current.WriteRune(' ')
+			continue
+		case r == '$':
+			if tag := dollarTag(runes[i:]); tag != "" {
+				end := indexRunes(runes, i+len(tag), tag) + len(tag)
+				if end > len(runes) {
+					end = len(runes)
+				}
+
+				current.WriteString(string(runes[i:end]))
+				i = end - 1
+				continue
+			}
+		case r == ';':
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				result = append(result, statement)
+			}
+			current.Reset()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		result = append(result, statement)
+	}
+
+	return result
+}
+
+// indexRunes returns the index of sub in runes from on, or len(runes) when
+// it is missing.
+func indexRunes(runes []rune, from int, sub string) int {
+	target := []rune(sub)
+
+	for i := from; i+len(target) <= len(runes); i++ {
+		if string(runes[i:i+len(target)]) == sub {
+			return i
+		}
+	}
+
+	return len(runes)
+}
+
+// dollarTag returns the $tag$ opening a dollar quoted string at the start of
+// runes, or "" when there is none. $1 is a parameter, not a tag.
+func dollarTag(runes []rune) string {
+	for i := 1; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '$':
+			return string(runes[:i+1])
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 1 && r >= '0' && r <= '9':
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"statements", "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n", []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"}},
+		{"no trailing semicolon", "SELECT 1; SELECT 2", []string{"SELECT 1", "SELECT 2"}},
+		{"empty statements", ";;\n  ; SELECT 1;;", []string{"SELECT 1"}},
+		{"quotes", `INSERT INTO a VALUES ('a;b', "c;d", ` + "`e;f`" + `);`, []string{`INSERT INTO a VALUES ('a;b', "c;d", ` + "`e;f`" + `)`}},
+		{"doubled quote", "INSERT INTO a VALUES ('it''s; fine');", []string{"INSERT INTO a VALUES ('it''s; fine')"}},
+		{"line comment", "-- drop; the table\nDROP TABLE a; -- done;\n", []string{"DROP TABLE a"}},
+		{"block comment", "/* a; b */ DROP TABLE a;/* c;\n d */DROP TABLE b;", []string{"DROP TABLE a", "DROP TABLE b"}},
+		{"block comment between words", "DROP/* ; */TABLE a;", []string{"DROP TABLE a"}},
+		{"unterminated block comment", "DROP TABLE a; /* b;", []string{"DROP TABLE a"}},
+		{
+			"dollar quoted body",
+			"CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\nSELECT 1;",
+			[]string{"CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			"tagged dollar quote",
+			"DO $body$ BEGIN PERFORM 'x;'; PERFORM $$y;$$; END $body$; SELECT 1;",
+			[]string{"DO $body$ BEGIN PERFORM 'x;'; PERFORM $$y;$$; END $body$", "SELECT 1"},
+		},
+		{"parameter is no dollar quote", "SELECT $1; SELECT $2;", []string{"SELECT $1", "SELECT $2"}},
+		{"unterminated dollar quote", "SELECT 1; SELECT $$a;", []string{"SELECT 1", "SELECT $$a;"}},
+		{"non ascii", "INSERT INTO a VALUES ('é;ü'); SELECT 'ß';", []string{"INSERT INTO a VALUES ('é;ü')", "SELECT 'ß'"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := splitStatements(c.script); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("splitStatements(%q) = %q, want %q", c.script, got, c.want)
+			}
+		})
+	}
+}
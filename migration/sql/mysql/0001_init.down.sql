@@ -0,0 +1,7 @@
+DROP TABLE enrollment;
+
+DROP TABLE user;
+
+DROP TABLE course;
+
+DROP TABLE category;
@@ -0,0 +1,45 @@
+CREATE TABLE category (
+    id INT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    parent_id INT NULL,
+    course_count INT NOT NULL DEFAULT 0,
+    enrollment_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_category_parent FOREIGN KEY (parent_id) REFERENCES category (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE course (
+    id INT NOT NULL AUTO_INCREMENT,
+    category_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    price INT NOT NULL DEFAULT 0,
+    enrollment_count INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_course_category (category_id),
+    CONSTRAINT fk_course_category FOREIGN KEY (category_id) REFERENCES category (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE user (
+    id INT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    phone BIGINT NOT NULL DEFAULT 0,
+    role INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_user_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE enrollment (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    course_id INT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_enrollment_user_course (user_id, course_id),
+    KEY idx_enrollment_course_created (course_id, created_at),
+    CONSTRAINT fk_enrollment_user FOREIGN KEY (user_id) REFERENCES user (id),
+    CONSTRAINT fk_enrollment_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
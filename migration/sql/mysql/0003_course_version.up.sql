@@ -0,0 +1 @@
+ALTER TABLE course ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,25 @@
+CREATE TABLE section (
+    id INT NOT NULL AUTO_INCREMENT,
+    course_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_section_course_position (course_id, position),
+    CONSTRAINT fk_section_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE lesson (
+    id INT NOT NULL AUTO_INCREMENT,
+    section_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    content TEXT NOT NULL,
+    duration INT NOT NULL DEFAULT 0,
+    free_preview TINYINT NOT NULL DEFAULT 0,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_lesson_section_position (section_id, position),
+    CONSTRAINT fk_lesson_section FOREIGN KEY (section_id) REFERENCES section (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
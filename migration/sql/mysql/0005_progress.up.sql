@@ -0,0 +1,20 @@
+ALTER TABLE lesson ADD COLUMN optional TINYINT NOT NULL DEFAULT 0;
+
+ALTER TABLE enrollment ADD COLUMN completed_at DATETIME NULL;
+
+CREATE TABLE lesson_progress (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    lesson_id INT NOT NULL,
+    course_id INT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    completed_at DATETIME NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_lesson_progress_user_lesson (user_id, lesson_id),
+    KEY idx_lesson_progress_user_updated (user_id, updated_at),
+    CONSTRAINT fk_lesson_progress_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_lesson_progress_lesson FOREIGN KEY (lesson_id) REFERENCES lesson (id),
+    CONSTRAINT fk_lesson_progress_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE quiz_attempt;
+
+DROP TABLE quiz_question;
+
+DROP TABLE quiz;
@@ -0,0 +1,46 @@
+CREATE TABLE quiz (
+    lesson_id INT NOT NULL,
+    time_limit INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 0,
+    pass_score INT NOT NULL DEFAULT 0,
+    score_policy VARCHAR(16) NOT NULL,
+    PRIMARY KEY (lesson_id),
+    CONSTRAINT fk_quiz_lesson FOREIGN KEY (lesson_id) REFERENCES lesson (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE quiz_question (
+    id INT NOT NULL AUTO_INCREMENT,
+    lesson_id INT NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    prompt TEXT NOT NULL,
+    options TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    points INT NOT NULL DEFAULT 1,
+    feedback TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY idx_quiz_question_lesson_position (lesson_id, position),
+    CONSTRAINT fk_quiz_question_quiz FOREIGN KEY (lesson_id) REFERENCES quiz (lesson_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE quiz_attempt (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    lesson_id INT NOT NULL,
+    course_id INT NOT NULL,
+    started_at DATETIME NOT NULL,
+    expires_at DATETIME NULL,
+    submitted_at DATETIME NULL,
+    score INT NOT NULL DEFAULT 0,
+    max_score INT NOT NULL DEFAULT 0,
+    percentage INT NOT NULL DEFAULT 0,
+    passed TINYINT NOT NULL DEFAULT 0,
+    responses TEXT NOT NULL,
+    results TEXT NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_quiz_attempt_user_lesson (user_id, lesson_id),
+    CONSTRAINT fk_quiz_attempt_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_quiz_attempt_quiz FOREIGN KEY (lesson_id) REFERENCES quiz (lesson_id),
+    CONSTRAINT fk_quiz_attempt_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
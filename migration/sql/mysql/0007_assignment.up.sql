@@ -0,0 +1,59 @@
+CREATE TABLE assignment (
+    lesson_id INT NOT NULL,
+    due_at DATETIME NOT NULL,
+    rubric TEXT NOT NULL,
+    max_score INT NOT NULL DEFAULT 0,
+    penalty_per_day INT NOT NULL DEFAULT 0,
+    max_penalty INT NOT NULL DEFAULT 0,
+    cutoff_days INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (lesson_id),
+    CONSTRAINT fk_assignment_lesson FOREIGN KEY (lesson_id) REFERENCES lesson (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE submission (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    lesson_id INT NOT NULL,
+    course_id INT NOT NULL,
+    submitted_at DATETIME NOT NULL,
+    days_late INT NOT NULL DEFAULT 0,
+    scores TEXT NOT NULL,
+    raw_score INT NOT NULL DEFAULT 0,
+    penalty INT NOT NULL DEFAULT 0,
+    score INT NOT NULL DEFAULT 0,
+    comment TEXT NOT NULL,
+    graded_at DATETIME NULL,
+    graded_by INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_submission_user_lesson (user_id, lesson_id),
+    KEY idx_submission_course (course_id),
+    CONSTRAINT fk_submission_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_submission_assignment FOREIGN KEY (lesson_id) REFERENCES assignment (lesson_id),
+    CONSTRAINT fk_submission_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE submission_file (
+    id INT NOT NULL AUTO_INCREMENT,
+    submission_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_key VARCHAR(255) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_submission_file_submission (submission_id),
+    CONSTRAINT fk_submission_file_submission FOREIGN KEY (submission_id) REFERENCES submission (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE notification (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    read_at DATETIME NULL,
+    PRIMARY KEY (id),
+    KEY idx_notification_user (user_id, created_at),
+    CONSTRAINT fk_notification_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE certificate (
+    id CHAR(32) NOT NULL,
+    user_id INT NOT NULL,
+    course_id INT NOT NULL,
+    student_name VARCHAR(255) NOT NULL,
+    course_name VARCHAR(255) NOT NULL,
+    completed_at DATETIME NOT NULL,
+    issued_at DATETIME NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    revoked_at DATETIME NULL,
+    revoke_reason VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_certificate_user_course (user_id, course_id),
+    CONSTRAINT fk_certificate_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_certificate_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
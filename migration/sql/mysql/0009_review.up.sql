@@ -0,0 +1,42 @@
+ALTER TABLE course ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE course ADD COLUMN rating_average DOUBLE NOT NULL DEFAULT 0;
+
+CREATE TABLE review (
+    id INT NOT NULL AUTO_INCREMENT,
+    course_id INT NOT NULL,
+    user_id INT NOT NULL,
+    rating INT NOT NULL,
+    body TEXT NOT NULL,
+    hidden TINYINT NOT NULL DEFAULT 0,
+    reply TEXT NULL,
+    replied_by INT NULL,
+    replied_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_review_user_course (user_id, course_id),
+    KEY idx_review_course (course_id, created_at),
+    CONSTRAINT fk_review_course FOREIGN KEY (course_id) REFERENCES course (id),
+    CONSTRAINT fk_review_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_review_replied_by FOREIGN KEY (replied_by) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE review_vote (
+    review_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id),
+    CONSTRAINT fk_review_vote_review FOREIGN KEY (review_id) REFERENCES review (id),
+    CONSTRAINT fk_review_vote_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE review_flag (
+    review_id INT NOT NULL,
+    user_id INT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id),
+    CONSTRAINT fk_review_flag_review FOREIGN KEY (review_id) REFERENCES review (id),
+    CONSTRAINT fk_review_flag_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
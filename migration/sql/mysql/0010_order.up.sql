@@ -0,0 +1,35 @@
+CREATE TABLE cart_item (
+    user_id INT NOT NULL,
+    course_id INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, course_id),
+    CONSTRAINT fk_cart_item_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_cart_item_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE orders (
+    id INT NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    total INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    paid_at DATETIME NULL,
+    PRIMARY KEY (id),
+    KEY idx_orders_user (user_id, created_at),
+    CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE order_item (
+    id INT NOT NULL AUTO_INCREMENT,
+    order_id INT NOT NULL,
+    course_id INT NOT NULL,
+    course_name VARCHAR(255) NOT NULL,
+    price INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_order_item_course (course_id),
+    CONSTRAINT fk_order_item_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_item_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,22 @@
+CREATE TABLE payment (
+    id VARCHAR(64) NOT NULL,
+    order_id INT NOT NULL,
+    amount INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_payment_order (order_id, created_at),
+    CONSTRAINT fk_payment_order FOREIGN KEY (order_id) REFERENCES orders (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE payment_event (
+    id VARCHAR(64) NOT NULL,
+    payment_id VARCHAR(64) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL,
+    received_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payment_event_payment FOREIGN KEY (payment_id) REFERENCES payment (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
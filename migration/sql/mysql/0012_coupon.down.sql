@@ -0,0 +1,15 @@
+DROP TABLE coupon_redemption;
+
+DROP TABLE coupon_category;
+
+DROP TABLE coupon_course;
+
+DROP TABLE coupon;
+
+ALTER TABLE order_item DROP COLUMN discount;
+
+ALTER TABLE orders DROP COLUMN discount;
+
+ALTER TABLE orders DROP COLUMN subtotal;
+
+ALTER TABLE orders DROP COLUMN coupon_code;
@@ -0,0 +1,59 @@
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD COLUMN subtotal INT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ADD COLUMN discount INT NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = total;
+
+ALTER TABLE order_item ADD COLUMN discount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE coupon (
+    id INT NOT NULL AUTO_INCREMENT,
+    code VARCHAR(32) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    value INT NOT NULL,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    max_uses INT NOT NULL DEFAULT 0,
+    max_uses_per_user INT NOT NULL DEFAULT 0,
+    min_amount INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    flag_aktif TINYINT NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_coupon_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE coupon_course (
+    coupon_id INT NOT NULL,
+    course_id INT NOT NULL,
+    PRIMARY KEY (coupon_id, course_id),
+    CONSTRAINT fk_coupon_course_coupon FOREIGN KEY (coupon_id) REFERENCES coupon (id),
+    CONSTRAINT fk_coupon_course_course FOREIGN KEY (course_id) REFERENCES course (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE coupon_category (
+    coupon_id INT NOT NULL,
+    category_id INT NOT NULL,
+    PRIMARY KEY (coupon_id, category_id),
+    CONSTRAINT fk_coupon_category_coupon FOREIGN KEY (coupon_id) REFERENCES coupon (id),
+    CONSTRAINT fk_coupon_category_category FOREIGN KEY (category_id) REFERENCES category (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE coupon_redemption (
+    id INT NOT NULL AUTO_INCREMENT,
+    coupon_id INT NOT NULL,
+    user_id INT NOT NULL,
+    order_id INT NOT NULL,
+    discount INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_coupon_redemption_order (order_id),
+    KEY idx_coupon_redemption_user (coupon_id, user_id),
+    CONSTRAINT fk_coupon_redemption_coupon FOREIGN KEY (coupon_id) REFERENCES coupon (id),
+    CONSTRAINT fk_coupon_redemption_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_coupon_redemption_order FOREIGN KEY (order_id) REFERENCES orders (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
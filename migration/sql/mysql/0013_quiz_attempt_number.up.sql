@@ -0,0 +1,12 @@
+ALTER TABLE quiz_attempt ADD COLUMN number INT NOT NULL DEFAULT 0;
+
+UPDATE quiz_attempt
+JOIN (
+    SELECT attempt.id, COUNT(earlier.id) AS number
+    FROM quiz_attempt AS attempt
+    JOIN quiz_attempt AS earlier ON earlier.user_id = attempt.user_id AND earlier.lesson_id = attempt.lesson_id AND earlier.id <= attempt.id
+    GROUP BY attempt.id
+) AS numbered ON numbered.id = quiz_attempt.id
+SET quiz_attempt.number = numbered.number;
+
+CREATE UNIQUE INDEX uq_quiz_attempt_user_lesson_number ON quiz_attempt (user_id, lesson_id, number);
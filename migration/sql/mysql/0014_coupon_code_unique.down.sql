@@ -0,0 +1,3 @@
+DROP INDEX uq_coupon_active_code ON coupon;
+
+ALTER TABLE coupon DROP COLUMN active_code;
@@ -0,0 +1,3 @@
+ALTER TABLE coupon ADD COLUMN active_code VARCHAR(32) AS (CASE WHEN flag_aktif = 1 THEN code END) VIRTUAL;
+
+CREATE UNIQUE INDEX uq_coupon_active_code ON coupon (active_code);
@@ -0,0 +1,39 @@
+CREATE TABLE category (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    parent_id INT NULL REFERENCES category (id),
+    course_count INT NOT NULL DEFAULT 0,
+    enrollment_count INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE course (
+    id SERIAL PRIMARY KEY,
+    category_id INT NOT NULL REFERENCES category (id),
+    name VARCHAR(255) NOT NULL,
+    price INT NOT NULL DEFAULT 0,
+    enrollment_count INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_course_category ON course (category_id);
+
+CREATE TABLE "user" (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    phone BIGINT NOT NULL DEFAULT 0,
+    role INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1,
+    CONSTRAINT uq_user_email UNIQUE (email)
+);
+
+CREATE TABLE enrollment (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES "user" (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_enrollment_user_course UNIQUE (user_id, course_id)
+);
+
+CREATE INDEX idx_enrollment_course_created ON enrollment (course_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE course DROP COLUMN version;
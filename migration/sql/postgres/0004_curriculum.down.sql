@@ -0,0 +1,3 @@
+DROP TABLE lesson;
+
+DROP TABLE section;
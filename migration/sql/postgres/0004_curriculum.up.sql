@@ -0,0 +1,23 @@
+CREATE TABLE section (
+    id SERIAL PRIMARY KEY,
+    course_id INT NOT NULL REFERENCES course (id),
+    title VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_section_course_position ON section (course_id, position);
+
+CREATE TABLE lesson (
+    id SERIAL PRIMARY KEY,
+    section_id INT NOT NULL REFERENCES section (id),
+    title VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    content TEXT NOT NULL,
+    duration INT NOT NULL DEFAULT 0,
+    free_preview SMALLINT NOT NULL DEFAULT 0,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_lesson_section_position ON lesson (section_id, position);
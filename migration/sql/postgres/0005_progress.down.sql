@@ -0,0 +1,5 @@
+DROP TABLE lesson_progress;
+
+ALTER TABLE enrollment DROP COLUMN completed_at;
+
+ALTER TABLE lesson DROP COLUMN optional;
@@ -0,0 +1,17 @@
+ALTER TABLE lesson ADD COLUMN optional SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE enrollment ADD COLUMN completed_at TIMESTAMP NULL;
+
+CREATE TABLE lesson_progress (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    lesson_id INT NOT NULL REFERENCES lesson (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    position INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT uq_lesson_progress_user_lesson UNIQUE (user_id, lesson_id)
+);
+
+CREATE INDEX idx_lesson_progress_user_updated ON lesson_progress (user_id, updated_at);
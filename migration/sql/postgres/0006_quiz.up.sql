@@ -0,0 +1,40 @@
+CREATE TABLE quiz (
+    lesson_id INT PRIMARY KEY REFERENCES lesson (id),
+    time_limit INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 0,
+    pass_score INT NOT NULL DEFAULT 0,
+    score_policy VARCHAR(16) NOT NULL
+);
+
+CREATE TABLE quiz_question (
+    id SERIAL PRIMARY KEY,
+    lesson_id INT NOT NULL REFERENCES quiz (lesson_id),
+    type VARCHAR(16) NOT NULL,
+    prompt TEXT NOT NULL,
+    options TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    points INT NOT NULL DEFAULT 1,
+    feedback TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_quiz_question_lesson_position ON quiz_question (lesson_id, position);
+
+CREATE TABLE quiz_attempt (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    lesson_id INT NOT NULL REFERENCES quiz (lesson_id),
+    course_id INT NOT NULL REFERENCES course (id),
+    started_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NULL,
+    submitted_at TIMESTAMP NULL,
+    score INT NOT NULL DEFAULT 0,
+    max_score INT NOT NULL DEFAULT 0,
+    percentage INT NOT NULL DEFAULT 0,
+    passed SMALLINT NOT NULL DEFAULT 0,
+    responses TEXT NOT NULL,
+    results TEXT NOT NULL
+);
+
+CREATE INDEX idx_quiz_attempt_user_lesson ON quiz_attempt (user_id, lesson_id);
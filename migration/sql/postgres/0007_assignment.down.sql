@@ -0,0 +1,7 @@
+DROP TABLE notification;
+
+DROP TABLE submission_file;
+
+DROP TABLE submission;
+
+DROP TABLE assignment;
@@ -0,0 +1,52 @@
+CREATE TABLE assignment (
+    lesson_id INT PRIMARY KEY REFERENCES lesson (id),
+    due_at TIMESTAMP NOT NULL,
+    rubric TEXT NOT NULL,
+    max_score INT NOT NULL DEFAULT 0,
+    penalty_per_day INT NOT NULL DEFAULT 0,
+    max_penalty INT NOT NULL DEFAULT 0,
+    cutoff_days INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE submission (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    lesson_id INT NOT NULL REFERENCES assignment (lesson_id),
+    course_id INT NOT NULL REFERENCES course (id),
+    submitted_at TIMESTAMP NOT NULL,
+    days_late INT NOT NULL DEFAULT 0,
+    scores TEXT NOT NULL,
+    raw_score INT NOT NULL DEFAULT 0,
+    penalty INT NOT NULL DEFAULT 0,
+    score INT NOT NULL DEFAULT 0,
+    comment TEXT NOT NULL,
+    graded_at TIMESTAMP NULL,
+    graded_by INT NOT NULL DEFAULT 0,
+    CONSTRAINT uq_submission_user_lesson UNIQUE (user_id, lesson_id)
+);
+
+CREATE INDEX idx_submission_course ON submission (course_id);
+
+CREATE TABLE submission_file (
+    id SERIAL PRIMARY KEY,
+    submission_id INT NOT NULL REFERENCES submission (id),
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_key VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_submission_file_submission ON submission_file (submission_id);
+
+CREATE TABLE notification (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    type VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_notification_user ON notification (user_id, created_at);
@@ -0,0 +1,13 @@
+CREATE TABLE certificate (
+    id CHAR(32) PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    student_name VARCHAR(255) NOT NULL,
+    course_name VARCHAR(255) NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    issued_at TIMESTAMP NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    revoke_reason VARCHAR(255) NOT NULL DEFAULT '',
+    CONSTRAINT uq_certificate_user_course UNIQUE (user_id, course_id)
+);
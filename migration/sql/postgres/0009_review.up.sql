@@ -0,0 +1,35 @@
+ALTER TABLE course ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE course ADD COLUMN rating_average DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE review (
+    id SERIAL PRIMARY KEY,
+    course_id INT NOT NULL REFERENCES course (id),
+    user_id INT NOT NULL REFERENCES users (id),
+    rating INT NOT NULL,
+    body TEXT NOT NULL,
+    hidden SMALLINT NOT NULL DEFAULT 0,
+    reply TEXT NULL,
+    replied_by INT NULL REFERENCES users (id),
+    replied_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CONSTRAINT uq_review_user_course UNIQUE (user_id, course_id)
+);
+
+CREATE INDEX idx_review_course ON review (course_id, created_at);
+
+CREATE TABLE review_vote (
+    review_id INT NOT NULL REFERENCES review (id),
+    user_id INT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (review_id, user_id)
+);
+
+CREATE TABLE review_flag (
+    review_id INT NOT NULL REFERENCES review (id),
+    user_id INT NOT NULL REFERENCES users (id),
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (review_id, user_id)
+);
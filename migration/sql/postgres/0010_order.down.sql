@@ -0,0 +1,5 @@
+DROP TABLE order_item;
+
+DROP TABLE orders;
+
+DROP TABLE cart_item;
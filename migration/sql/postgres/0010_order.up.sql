@@ -0,0 +1,32 @@
+CREATE TABLE cart_item (
+    user_id INT NOT NULL REFERENCES users (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id),
+    status VARCHAR(16) NOT NULL,
+    total INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_orders_user ON orders (user_id, created_at);
+
+CREATE TABLE order_item (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    course_name VARCHAR(255) NOT NULL,
+    price INT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX idx_order_item_order ON order_item (order_id);
+
+CREATE INDEX idx_order_item_course ON order_item (course_id);
@@ -0,0 +1,3 @@
+DROP TABLE payment_event;
+
+DROP TABLE payment;
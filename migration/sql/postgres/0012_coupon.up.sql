@@ -0,0 +1,52 @@
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD COLUMN subtotal INT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ADD COLUMN discount INT NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = total;
+
+ALTER TABLE order_item ADD COLUMN discount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE coupon (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    value INT NOT NULL,
+    starts_at TIMESTAMP NULL,
+    ends_at TIMESTAMP NULL,
+    max_uses INT NOT NULL DEFAULT 0,
+    max_uses_per_user INT NOT NULL DEFAULT 0,
+    min_amount INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    flag_aktif SMALLINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_coupon_code ON coupon (code);
+
+CREATE TABLE coupon_course (
+    coupon_id INT NOT NULL REFERENCES coupon (id),
+    course_id INT NOT NULL REFERENCES course (id),
+    PRIMARY KEY (coupon_id, course_id)
+);
+
+CREATE TABLE coupon_category (
+    coupon_id INT NOT NULL REFERENCES coupon (id),
+    category_id INT NOT NULL REFERENCES category (id),
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+CREATE TABLE coupon_redemption (
+    id SERIAL PRIMARY KEY,
+    coupon_id INT NOT NULL REFERENCES coupon (id),
+    user_id INT NOT NULL REFERENCES users (id),
+    order_id INT NOT NULL REFERENCES orders (id),
+    discount INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT uq_coupon_redemption_order UNIQUE (order_id)
+);
+
+CREATE INDEX idx_coupon_redemption_user ON coupon_redemption (coupon_id, user_id);
@@ -0,0 +1,3 @@
+DROP INDEX uq_quiz_attempt_user_lesson_number;
+
+ALTER TABLE quiz_attempt DROP COLUMN number;
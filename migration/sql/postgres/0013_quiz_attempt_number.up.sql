@@ -0,0 +1,9 @@
+ALTER TABLE quiz_attempt ADD COLUMN number INT NOT NULL DEFAULT 0;
+
+UPDATE quiz_attempt SET number = (
+    SELECT COUNT(earlier.id)
+    FROM quiz_attempt AS earlier
+    WHERE earlier.user_id = quiz_attempt.user_id AND earlier.lesson_id = quiz_attempt.lesson_id AND earlier.id <= quiz_attempt.id
+);
+
+CREATE UNIQUE INDEX uq_quiz_attempt_user_lesson_number ON quiz_attempt (user_id, lesson_id, number);
@@ -0,0 +1 @@
+DROP INDEX uq_coupon_active_code;
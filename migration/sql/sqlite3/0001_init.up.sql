@@ -0,0 +1,38 @@
+CREATE TABLE category (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    parent_id INTEGER NULL REFERENCES category (id),
+    course_count INTEGER NOT NULL DEFAULT 0,
+    enrollment_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE course (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category_id INTEGER NOT NULL REFERENCES category (id),
+    name VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL DEFAULT 0,
+    enrollment_count INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_course_category ON course (category_id);
+
+CREATE TABLE user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    phone INTEGER NOT NULL DEFAULT 0,
+    role INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE enrollment (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES user (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, course_id)
+);
+
+CREATE INDEX idx_enrollment_course_created ON enrollment (course_id, created_at);
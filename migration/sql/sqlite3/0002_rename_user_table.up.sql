@@ -0,0 +1,2 @@
+-- user is a reserved word in PostgreSQL, every dialect uses users instead.
+ALTER TABLE user RENAME TO users;
@@ -0,0 +1,23 @@
+CREATE TABLE section (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    title VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_section_course_position ON section (course_id, position);
+
+CREATE TABLE lesson (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    section_id INTEGER NOT NULL REFERENCES section (id),
+    title VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    content TEXT NOT NULL,
+    duration INTEGER NOT NULL DEFAULT 0,
+    free_preview INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_lesson_section_position ON lesson (section_id, position);
@@ -0,0 +1,17 @@
+ALTER TABLE lesson ADD COLUMN optional INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE enrollment ADD COLUMN completed_at DATETIME NULL;
+
+CREATE TABLE lesson_progress (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    lesson_id INTEGER NOT NULL REFERENCES lesson (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    position INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    completed_at DATETIME NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (user_id, lesson_id)
+);
+
+CREATE INDEX idx_lesson_progress_user_updated ON lesson_progress (user_id, updated_at);
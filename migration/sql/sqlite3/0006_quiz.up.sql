@@ -0,0 +1,40 @@
+CREATE TABLE quiz (
+    lesson_id INTEGER PRIMARY KEY REFERENCES lesson (id),
+    time_limit INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 0,
+    pass_score INTEGER NOT NULL DEFAULT 0,
+    score_policy VARCHAR(16) NOT NULL
+);
+
+CREATE TABLE quiz_question (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lesson_id INTEGER NOT NULL REFERENCES quiz (lesson_id),
+    type VARCHAR(16) NOT NULL,
+    prompt TEXT NOT NULL,
+    options TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    points INTEGER NOT NULL DEFAULT 1,
+    feedback TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_quiz_question_lesson_position ON quiz_question (lesson_id, position);
+
+CREATE TABLE quiz_attempt (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    lesson_id INTEGER NOT NULL REFERENCES quiz (lesson_id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    started_at DATETIME NOT NULL,
+    expires_at DATETIME NULL,
+    submitted_at DATETIME NULL,
+    score INTEGER NOT NULL DEFAULT 0,
+    max_score INTEGER NOT NULL DEFAULT 0,
+    percentage INTEGER NOT NULL DEFAULT 0,
+    passed INTEGER NOT NULL DEFAULT 0,
+    responses TEXT NOT NULL,
+    results TEXT NOT NULL
+);
+
+CREATE INDEX idx_quiz_attempt_user_lesson ON quiz_attempt (user_id, lesson_id);
@@ -0,0 +1,52 @@
+CREATE TABLE assignment (
+    lesson_id INTEGER PRIMARY KEY REFERENCES lesson (id),
+    due_at DATETIME NOT NULL,
+    rubric TEXT NOT NULL,
+    max_score INTEGER NOT NULL DEFAULT 0,
+    penalty_per_day INTEGER NOT NULL DEFAULT 0,
+    max_penalty INTEGER NOT NULL DEFAULT 0,
+    cutoff_days INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE submission (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    lesson_id INTEGER NOT NULL REFERENCES assignment (lesson_id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    submitted_at DATETIME NOT NULL,
+    days_late INTEGER NOT NULL DEFAULT 0,
+    scores TEXT NOT NULL,
+    raw_score INTEGER NOT NULL DEFAULT 0,
+    penalty INTEGER NOT NULL DEFAULT 0,
+    score INTEGER NOT NULL DEFAULT 0,
+    comment TEXT NOT NULL,
+    graded_at DATETIME NULL,
+    graded_by INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (user_id, lesson_id)
+);
+
+CREATE INDEX idx_submission_course ON submission (course_id);
+
+CREATE TABLE submission_file (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    submission_id INTEGER NOT NULL REFERENCES submission (id),
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    storage_key VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_submission_file_submission ON submission_file (submission_id);
+
+CREATE TABLE notification (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    type VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    read_at DATETIME NULL
+);
+
+CREATE INDEX idx_notification_user ON notification (user_id, created_at);
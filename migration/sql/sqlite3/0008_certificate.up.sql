@@ -0,0 +1,13 @@
+CREATE TABLE certificate (
+    id CHAR(32) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    student_name VARCHAR(255) NOT NULL,
+    course_name VARCHAR(255) NOT NULL,
+    completed_at DATETIME NOT NULL,
+    issued_at DATETIME NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    revoked_at DATETIME NULL,
+    revoke_reason VARCHAR(255) NOT NULL DEFAULT '',
+    UNIQUE (user_id, course_id)
+);
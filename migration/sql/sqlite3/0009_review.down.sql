@@ -0,0 +1,9 @@
+DROP TABLE review_flag;
+
+DROP TABLE review_vote;
+
+DROP TABLE review;
+
+ALTER TABLE course DROP COLUMN rating_average;
+
+ALTER TABLE course DROP COLUMN rating_count;
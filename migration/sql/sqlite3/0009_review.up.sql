@@ -0,0 +1,35 @@
+ALTER TABLE course ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE course ADD COLUMN rating_average REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE review (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    rating INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    hidden INTEGER NOT NULL DEFAULT 0,
+    reply TEXT NULL,
+    replied_by INTEGER NULL REFERENCES users (id),
+    replied_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (user_id, course_id)
+);
+
+CREATE INDEX idx_review_course ON review (course_id, created_at);
+
+CREATE TABLE review_vote (
+    review_id INTEGER NOT NULL REFERENCES review (id),
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id)
+);
+
+CREATE TABLE review_flag (
+    review_id INTEGER NOT NULL REFERENCES review (id),
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    reason VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id)
+);
@@ -0,0 +1,32 @@
+CREATE TABLE cart_item (
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE TABLE orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    status VARCHAR(16) NOT NULL,
+    total INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    paid_at DATETIME NULL
+);
+
+CREATE INDEX idx_orders_user ON orders (user_id, created_at);
+
+CREATE TABLE order_item (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id INTEGER NOT NULL REFERENCES orders (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    course_name VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX idx_order_item_order ON order_item (order_id);
+
+CREATE INDEX idx_order_item_course ON order_item (course_id);
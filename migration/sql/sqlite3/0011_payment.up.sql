@@ -0,0 +1,19 @@
+CREATE TABLE payment (
+    id VARCHAR(64) PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id),
+    amount INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_payment_order ON payment (order_id, created_at);
+
+CREATE TABLE payment_event (
+    id VARCHAR(64) PRIMARY KEY,
+    payment_id VARCHAR(64) NOT NULL REFERENCES payment (id),
+    type VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL,
+    received_at DATETIME NOT NULL
+);
@@ -0,0 +1,52 @@
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD COLUMN subtotal INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ADD COLUMN discount INTEGER NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = total;
+
+ALTER TABLE order_item ADD COLUMN discount INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE coupon (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(32) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    value INTEGER NOT NULL,
+    starts_at DATETIME NULL,
+    ends_at DATETIME NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+    min_amount INTEGER NOT NULL DEFAULT 0,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    flag_aktif INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_coupon_code ON coupon (code);
+
+CREATE TABLE coupon_course (
+    coupon_id INTEGER NOT NULL REFERENCES coupon (id),
+    course_id INTEGER NOT NULL REFERENCES course (id),
+    PRIMARY KEY (coupon_id, course_id)
+);
+
+CREATE TABLE coupon_category (
+    coupon_id INTEGER NOT NULL REFERENCES coupon (id),
+    category_id INTEGER NOT NULL REFERENCES category (id),
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+CREATE TABLE coupon_redemption (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    coupon_id INTEGER NOT NULL REFERENCES coupon (id),
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    order_id INTEGER NOT NULL REFERENCES orders (id),
+    discount INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (order_id)
+);
+
+CREATE INDEX idx_coupon_redemption_user ON coupon_redemption (coupon_id, user_id);
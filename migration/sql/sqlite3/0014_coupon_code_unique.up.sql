@@ -0,0 +1 @@
+CREATE UNIQUE INDEX uq_coupon_active_code ON coupon (code) WHERE flag_aktif = 1;
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+// Assignment holds the settings of an assignment lesson. Submissions after
+// DueAt lose PenaltyPerDay percent of their score for every started day,
+// at most MaxPenalty percent, 0 means up to the whole score. Submissions
+// more than CutoffDays days late are refused, 0 means they are accepted
+// at any time. MaxScore is the sum of the points of the rubric.
+type Assignment struct {
+	LessonId      int         `json:"lesson_id"`
+	CourseId      int         `json:"course_id"`
+	Title         string      `json:"title"`
+	DueAt         time.Time   `json:"due_at"`
+	Rubric        []Criterion `json:"rubric"`
+	MaxScore      int         `json:"max_score"`
+	PenaltyPerDay int         `json:"penalty_per_day"`
+	MaxPenalty    int         `json:"max_penalty"`
+	CutoffDays    int         `json:"cutoff_days"`
+}
+
+// Criterion is one line of the rubric of an assignment.
+type Criterion struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Points      int    `json:"points"`
+}
+
+// CriterionScore is the points given for the criterion at index Criterion
+// of the rubric.
+type CriterionScore struct {
+	Criterion int    `json:"criterion"`
+	Points    int    `json:"points"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Submission is the work of a user for an assignment. A user has one
+// submission per assignment, replaced by submitting again until it is
+// graded. Score is RawScore, the sum of Scores, less Penalty percent.
+type Submission struct {
+	Id          int              `json:"id"`
+	UserId      int              `json:"user_id"`
+	LessonId    int              `json:"lesson_id"`
+	CourseId    int              `json:"course_id"`
+	SubmittedAt time.Time        `json:"submitted_at"`
+	DaysLate    int              `json:"days_late"`
+	Files       []SubmissionFile `json:"files"`
+	Scores      []CriterionScore `json:"scores,omitempty"`
+	RawScore    int              `json:"raw_score"`
+	Penalty     int              `json:"penalty"`
+	Score       int              `json:"score"`
+	Comment     string           `json:"comment,omitempty"`
+	GradedAt    *time.Time       `json:"graded_at"`
+	GradedBy    int              `json:"graded_by,omitempty"`
+}
+
+// SubmissionFile is a file uploaded with a submission. Key locates the
+// content in the file store.
+type SubmissionFile struct {
+	Id           int    `json:"id"`
+	SubmissionId int    `json:"submission_id"`
+	Name         string `json:"name"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Key          string `json:"-"`
+}
+
+// Upload is a file sent by a client.
+type Upload struct {
+	Name        string
+	ContentType string
+	Body        io.Reader
+}
+
+// GradeRequest grades a submission with a score for every criterion of the
+// rubric.
+type GradeRequest struct {
+	Scores  []CriterionScore `json:"scores"`
+	Comment string           `json:"comment"`
+}
+
+// Gradebook lists the status of every enrolled student for every
+// assignment of a course.
+type Gradebook struct {
+	CourseId    int            `json:"course_id"`
+	Assignments []Assignment   `json:"assignments"`
+	Students    []GradebookRow `json:"students"`
+}
+
+// GradebookRow is a student with one entry per assignment, in the order of
+// the assignments of the gradebook.
+type GradebookRow struct {
+	UserId  int              `json:"user_id"`
+	Name    string           `json:"name"`
+	Email   string           `json:"email"`
+	Entries []GradebookEntry `json:"entries"`
+}
+
+// GradebookEntry is the status of a student for an assignment. Score is
+// set once the submission is graded.
+type GradebookEntry struct {
+	LessonId     int        `json:"lesson_id"`
+	Status       string     `json:"status"`
+	SubmissionId int        `json:"submission_id,omitempty"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	DaysLate     int        `json:"days_late,omitempty"`
+	Score        *int       `json:"score,omitempty"`
+}
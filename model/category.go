@@ -0,0 +1,37 @@
+package model
+
+type Category struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentId int    `json:"parent_id,omitempty"`
+}
+
+// CategoryDetail carries the course and enrollment counts of the category
+// itself, not of its descendants. For popular categories EnrollmentCount only
+// covers the requested time window.
+type CategoryDetail struct {
+	Id              int    `json:"id"`
+	Name            string `json:"name"`
+	ParentId        int    `json:"parent_id,omitempty"`
+	CourseCount     int    `json:"course_count"`
+	EnrollmentCount int    `json:"enrollment_count"`
+}
+
+// CategoryNode is a flat category row together with the number of active
+// courses attached directly to it.
+type CategoryNode struct {
+	Id          int
+	Name        string
+	ParentId    int
+	CourseCount int
+}
+
+// CategoryTree is a category with its descendants. CourseCount includes the
+// courses of every descendant category.
+type CategoryTree struct {
+	Id          int            `json:"id"`
+	Name        string         `json:"name"`
+	ParentId    int            `json:"parent_id,omitempty"`
+	CourseCount int            `json:"course_count"`
+	Children    []CategoryTree `json:"children"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Certificate is issued to a user for completing a course. The names are
+// those at issue time, Sha256 is the checksum of the issued PDF.
+type Certificate struct {
+	Id           string     `json:"id"`
+	UserId       int        `json:"-"`
+	CourseId     int        `json:"course_id"`
+	StudentName  string     `json:"student_name"`
+	CourseName   string     `json:"course_name"`
+	CompletedAt  time.Time  `json:"completed_at"`
+	IssuedAt     time.Time  `json:"issued_at"`
+	Sha256       string     `json:"sha256"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+}
+
+// CertificateVerification is the public answer to whether a certificate
+// is genuine. It is valid unless it was revoked.
+type CertificateVerification struct {
+	Valid bool `json:"valid"`
+	Certificate
+}
+
+// RevokeRequest gives the reason for revoking a certificate.
+type RevokeRequest struct {
+	Reason string `json:"reason"`
+}
@@ -1,14 +1,250 @@
 package model
 
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
 type Config struct {
-	Database DatabaseConfig `json:"database"`
+	HTTP        HTTPConfig        `json:"http" yaml:"http"`
+	Database    DatabaseConfig    `json:"database" yaml:"database"`
+	JWT         JWTConfig         `json:"jwt" yaml:"jwt"`
+	Log         LogConfig         `json:"log" yaml:"log"`
+	Tracing     TracingConfig     `json:"tracing" yaml:"tracing"`
+	Feature     FeatureConfig     `json:"feature" yaml:"feature"`
+	Upload      UploadConfig      `json:"upload" yaml:"upload"`
+	Certificate CertificateConfig `json:"certificate" yaml:"certificate"`
+	Commerce    CommerceConfig    `json:"commerce" yaml:"commerce"`
+	Payment     PaymentConfig     `json:"payment" yaml:"payment"`
+}
+
+type HTTPConfig struct {
+	Address         string   `json:"address" yaml:"address"`
+	ReadTimeout     Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout" yaml:"write_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
 }
 
 type DatabaseConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Name     string `json:"name"`
-	User     string `json:"user"`
-	Database string `json:"database"`
-	Password string `json:"password"`
+	Driver   string `json:"driver" yaml:"driver"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Name     string `json:"name" yaml:"name"`
+	User     string `json:"user" yaml:"user"`
+	Database string `json:"database" yaml:"database"`
+	Password Secret `json:"password" yaml:"password"`
+	SSLMode  string `json:"sslmode" yaml:"sslmode"`
+	// TransactionRetry is how often a transaction aborted by a deadlock is
+	// retried.
+	TransactionRetry int `json:"transaction_retry" yaml:"transaction_retry"`
+	// ConnectAttempts is how often the startup ping is tried. The wait
+	// between attempts starts at ConnectBackoff and doubles up to
+	// ConnectMaxBackoff.
+	ConnectAttempts   int      `json:"connect_attempts" yaml:"connect_attempts"`
+	ConnectBackoff    Duration `json:"connect_backoff" yaml:"connect_backoff"`
+	ConnectMaxBackoff Duration `json:"connect_max_backoff" yaml:"connect_max_backoff"`
+	// QueryTimeout bounds every query, on top of the request deadline.
+	QueryTimeout Duration   `json:"query_timeout" yaml:"query_timeout"`
+	Pool         PoolConfig `json:"pool" yaml:"pool"`
+}
+
+type PoolConfig struct {
+	MaxOpenConns    int      `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
+}
+
+type JWTConfig struct {
+	Secret     Secret   `json:"secret" yaml:"secret"`
+	Expiration Duration `json:"expiration" yaml:"expiration"`
+}
+
+type LogConfig struct {
+	// Level is a logrus level name, Format is text or json.
+	Level  string `json:"level" yaml:"level"`
+	Format string `json:"format" yaml:"format"`
+	// Packages overrides the level per package, e.g.
+	// "usecase=debug,repository=warn".
+	Packages string `json:"packages" yaml:"packages"`
+	// Access logs every HTTP request.
+	Access bool `json:"access" yaml:"access"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp. Endpoint is the host:port of the
+	// OTLP/HTTP collector.
+	Exporter    string `json:"exporter" yaml:"exporter"`
+	Endpoint    string `json:"endpoint" yaml:"endpoint"`
+	Insecure    bool   `json:"insecure" yaml:"insecure"`
+	ServiceName string `json:"service_name" yaml:"service_name"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Requests
+	// carrying a traceparent follow the decision of the caller.
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio"`
+}
+
+type FeatureConfig struct {
+	// Storage is sql or memory.
+	Storage                string   `json:"storage" yaml:"storage"`
+	Registration           bool     `json:"registration" yaml:"registration"`
+	CountRecomputeInterval Duration `json:"count_recompute_interval" yaml:"count_recompute_interval"`
+}
+
+type UploadConfig struct {
+	// Directory keeps the uploaded files. MaxSize is the largest file
+	// accepted, in bytes.
+	Directory string `json:"directory" yaml:"directory"`
+	MaxSize   int64  `json:"max_size" yaml:"max_size"`
+}
+
+type CertificateConfig struct {
+	// Template is a JSON file with the layout of the certificate PDF, the
+	// built in layout when empty. BaseURL is the public address of the API,
+	// printed on certificates as the place to verify them.
+	Template string `json:"template" yaml:"template"`
+	BaseURL  string `json:"base_url" yaml:"base_url"`
+}
+
+type CommerceConfig struct {
+	// Currency is the ISO 4217 code of the course prices, which are in its
+	// minor unit.
+	Currency string `json:"currency" yaml:"currency"`
+}
+
+type PaymentConfig struct {
+	// Gateway is the payment provider, only fake for now.
+	Gateway string `json:"gateway" yaml:"gateway"`
+	// WebhookSecret signs the webhook events of the provider. The fake
+	// gateway makes up one when empty.
+	WebhookSecret Secret `json:"webhook_secret" yaml:"webhook_secret"`
+	// AutoConfirm has the fake gateway confirm payments as soon as they
+	// are created.
+	AutoConfirm bool `json:"auto_confirm" yaml:"auto_confirm"`
+	// Development adds the endpoints confirming and declining payments
+	// like the customer would.
+	Development bool `json:"development" yaml:"development"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" in config
+// files, environment variables and flags.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// UnmarshalJSON accepts a duration string, or a number of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*d = Duration(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// redacted replaces secret values whenever a config is printed or marshalled.
+const redacted = "[REDACTED]"
+
+// Secret is a sensitive setting. Its reference is either the secret itself
+// or a pointer to it such as file:/run/secrets/db or env:DB_PASS, and the
+// config loader resolves it into the value. Copies of a Secret share the
+// value, so a reload reaches every holder. Printing or marshalling a Secret
+// never shows the value.
+type Secret struct {
+	ref   string
+	value *secretValue
+}
+
+type secretValue struct {
+	mu sync.RWMutex
+	v  string
+}
+
+func NewSecret(ref string) Secret {
+	return Secret{ref: ref, value: &secretValue{}}
+}
+
+// Ref returns the reference as written in the configuration.
+func (s Secret) Ref() string {
+	return s.ref
+}
+
+// Value returns the resolved secret, empty before resolution.
+func (s Secret) Value() string {
+	if s.value == nil {
+		return ""
+	}
+
+	s.value.mu.RLock()
+	defer s.value.mu.RUnlock()
+
+	return s.value.v
+}
+
+// Set stores the resolved secret. s must come from NewSecret or UnmarshalText.
+func (s Secret) Set(v string) {
+	s.value.mu.Lock()
+	s.value.v = v
+	s.value.mu.Unlock()
+}
+
+func (s Secret) String() string {
+	if s.ref == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = NewSecret(string(text))
+
+	return nil
+}
+
+// PoolStats is a snapshot of the database connection pool.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	// WaitDuration is the total time blocked waiting for a connection.
+	WaitDuration      Duration `json:"wait_duration"`
+	MaxIdleClosed     int64    `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64    `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64    `json:"max_lifetime_closed"`
+}
+
+// BusinessStats are the catalogue counts exposed as metrics.
+type BusinessStats struct {
+	ActiveCourses   int `json:"active_courses"`
+	RegisteredUsers int `json:"registered_users"`
 }
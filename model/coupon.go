@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Coupon is a promotion code taking Value percent or Value in the minor
+// unit of the currency off the courses it covers. A coupon without courses
+// and categories covers every course, categories include their
+// descendants. StartsAt and EndsAt bound when it can be used, MaxUses and
+// MaxUsesPerUser how often, 0 means no limit. Orders below MinAmount before
+// the discount can't use it.
+type Coupon struct {
+	Id             int        `json:"id"`
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int        `json:"value"`
+	CourseIds      []int      `json:"course_ids"`
+	CategoryIds    []int      `json:"category_ids"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	MinAmount      int        `json:"min_amount"`
+	// UsedCount is the number of orders that redeemed the coupon.
+	UsedCount int       `json:"used_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CouponRequest creates a coupon or replaces its settings.
+type CouponRequest struct {
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int        `json:"value"`
+	CourseIds      []int      `json:"course_ids"`
+	CategoryIds    []int      `json:"category_ids"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	MinAmount      int        `json:"min_amount"`
+}
+
+// CouponRedemption is the use of a coupon by an order.
+type CouponRedemption struct {
+	Id        int       `json:"id"`
+	CouponId  int       `json:"coupon_id"`
+	UserId    int       `json:"user_id"`
+	OrderId   int       `json:"order_id"`
+	Discount  Money     `json:"discount"`
+	CreatedAt time.Time `json:"created_at"`
+}
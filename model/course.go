@@ -1,37 +1,39 @@
 package model
 
 type Course struct {
-	Id    int    `json:"id"`
-	Name  string `json:"name"`
-	Price int    `json:"price"`
-	Count string `json:"count"`
+	Id              int    `json:"id"`
+	CategoryId      int    `json:"category_id"`
+	Name            string `json:"name"`
+	Price           int    `json:"price"`
+	EnrollmentCount int    `json:"enrollment_count"`
+	// RatingAverage is the average stars of the visible reviews, 0 without
+	// any.
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
 }
 
 type CourseDetail struct {
-	Id       int      `json:"id"`
-	Category Category `json:"category"`
-	Name     string   `json:"name"`
-	Price    int      `json:"price"`
-	Count    string   `json:"count"`
-}
-
-type CategoryDetail struct {
-	Id    int    `json:"id"`
-	Name  string `json:"name"`
-	Count string `json:"count"`
-}
-
-type Category struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id              int           `json:"id"`
+	Category        Category      `json:"category"`
+	Breadcrumb      []Category    `json:"breadcrumb"`
+	Name            string        `json:"name"`
+	Price           int           `json:"price"`
+	EnrollmentCount int           `json:"enrollment_count"`
+	Version         int           `json:"version"`
+	Rating          RatingSummary `json:"rating"`
+	Curriculum      []Section     `json:"curriculum"`
+	// Enrollment is the enrollment of the requesting user, nil when the
+	// user is anonymous or not enrolled.
+	Enrollment *Enrollment `json:"enrollment"`
 }
 
+// CourseUpdate holds the editable course fields. Version is the version the
+// update was based on.
 type CourseUpdate struct {
-	Id         int    `json:"Id"`
-	CategoryId int    `json:"course_id"`
+	CategoryId int    `json:"category_id"`
 	Name       string `json:"name"`
 	Price      int    `json:"price"`
-	Count      string `json:"count"`
+	Version    int    `json:"-"`
 }
 
 type StatisticResponse struct {
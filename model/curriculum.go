@@ -0,0 +1,33 @@
+package model
+
+// Section is an ordered group of lessons of a course.
+type Section struct {
+	Id       int      `json:"id"`
+	CourseId int      `json:"course_id"`
+	Title    string   `json:"title"`
+	Position int      `json:"position"`
+	Lessons  []Lesson `json:"lessons"`
+}
+
+// Lesson is one item of a section. Content is the markdown body of a text
+// lesson and the URL of the video, file or embedded page otherwise.
+// Duration is the length of a video in seconds. Optional lessons don't count
+// towards the completion of the course.
+type Lesson struct {
+	Id          int    `json:"id"`
+	SectionId   int    `json:"section_id"`
+	CourseId    int    `json:"course_id"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Content     string `json:"content,omitempty"`
+	Duration    int    `json:"duration"`
+	FreePreview bool   `json:"free_preview"`
+	Optional    bool   `json:"optional"`
+	Position    int    `json:"position"`
+}
+
+// Reorder lists every section of a course, or every lesson of a section, in
+// the new order.
+type Reorder struct {
+	Ids []int `json:"ids"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Enrollment links a user to a course. CompletedAt is set once every
+// required lesson of the course is completed.
+type Enrollment struct {
+	Id          int        `json:"id"`
+	UserId      int        `json:"user_id"`
+	CourseId    int        `json:"course_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// EnrolledCourse is a course on the learning list of a user.
+type EnrolledCourse struct {
+	Course
+	EnrolledAt time.Time       `json:"enrolled_at"`
+	Progress   *CourseProgress `json:"progress"`
+}
+
+// RosterEntry is a user enrolled in a course.
+type RosterEntry struct {
+	UserId     int       `json:"user_id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
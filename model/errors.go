@@ -0,0 +1,17 @@
+package model
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("data not found")
+	ErrBadParamInput = errors.New("invalid parameter")
+	ErrConflict      = errors.New("data was modified concurrently")
+	ErrForbidden     = errors.New("access denied")
+	ErrPaymentNeeded = errors.New("payment required")
+	ErrNoAttemptLeft = errors.New("no attempt left")
+	ErrPastDeadline  = errors.New("past the deadline")
+	ErrTooLarge      = errors.New("file too large")
+	// ErrCoupon is wrapped with the reason a coupon can't be applied,
+	// which is shown to the user.
+	ErrCoupon = errors.New("coupon can't be applied")
+)
@@ -0,0 +1,21 @@
+package model
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// HealthCheck is the result of one readiness check. Error is only shown in
+// verbose output.
+type HealthCheck struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Latency Duration `json:"latency"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// HealthReport is down as soon as one of its checks is down.
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Notification is a message to a user, e.g. that a submission was graded.
+// Link is the API path of the subject.
+type Notification struct {
+	Id        int        `json:"id"`
+	UserId    int        `json:"-"`
+	Type      string     `json:"type"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
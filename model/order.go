@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Money is an amount in the minor unit of its currency, cents for USD.
+// Currency is an ISO 4217 code.
+type Money struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// CartItem is a course waiting in the cart of a user, at its current price.
+// Discount is the part of the price a coupon takes off.
+type CartItem struct {
+	CourseId   int       `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	CategoryId int       `json:"category_id"`
+	Price      Money     `json:"price"`
+	Discount   Money     `json:"discount"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+// Cart is priced as Subtotal, the sum of the prices, less Discount, the
+// sum of the item discounts of Coupon.
+type Cart struct {
+	Items    []CartItem `json:"items"`
+	Coupon   string     `json:"coupon,omitempty"`
+	Subtotal Money      `json:"subtotal"`
+	Discount Money      `json:"discount"`
+	Total    Money      `json:"total"`
+}
+
+// CartRequest adds a course to the cart.
+type CartRequest struct {
+	CourseId int `json:"course_id"`
+}
+
+// CheckoutRequest checks the cart out, with the coupon code when set.
+type CheckoutRequest struct {
+	Coupon string `json:"coupon"`
+}
+
+// Order is a checked out cart. Its items keep the course names, prices and
+// discounts of the checkout, later course changes don't alter it.
+type Order struct {
+	Id        int         `json:"id"`
+	UserId    int         `json:"user_id"`
+	Status    string      `json:"status"`
+	Coupon    string      `json:"coupon,omitempty"`
+	Subtotal  Money       `json:"subtotal"`
+	Discount  Money       `json:"discount"`
+	Total     Money       `json:"total"`
+	Items     []OrderItem `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	PaidAt    *time.Time  `json:"paid_at"`
+}
+
+type OrderItem struct {
+	Id         int    `json:"id"`
+	CourseId   int    `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Price      Money  `json:"price"`
+	Discount   Money  `json:"discount"`
+}
+
+// StatusRequest moves an order to another status.
+type StatusRequest struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// PaymentIntent is a payment started at the payment provider. The client
+// completes it with the provider using ClientSecret.
+type PaymentIntent struct {
+	Id           string `json:"id"`
+	OrderId      int    `json:"order_id"`
+	Amount       Money  `json:"amount"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// Payment is a payment intent of an order as last reported by the
+// provider. An order has a payment per attempt to pay it.
+type Payment struct {
+	Id        string    `json:"id"`
+	OrderId   int       `json:"order_id"`
+	Amount    Money     `json:"amount"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PaymentEvent is a webhook event of the payment provider. Id is unique
+// per event, a provider sends the same event again until it is accepted.
+type PaymentEvent struct {
+	Id        string    `json:"id"`
+	Type      string    `json:"type"`
+	PaymentId string    `json:"payment_id"`
+	Amount    Money     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// LessonProgress is how far a user got in a lesson. Position is the last
+// playback position of a video in seconds.
+type LessonProgress struct {
+	UserId      int        `json:"-"`
+	LessonId    int        `json:"lesson_id"`
+	CourseId    int        `json:"course_id"`
+	Position    int        `json:"position"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ProgressUpdate is sent by clients while they follow a lesson.
+type ProgressUpdate struct {
+	Position  int  `json:"position"`
+	Completed bool `json:"completed"`
+}
+
+// CourseProgress sums up the progress of a user in a course. Only required
+// lessons count towards Percentage. Resume is the lesson touched last.
+type CourseProgress struct {
+	CourseId         int              `json:"course_id"`
+	RequiredLessons  int              `json:"required_lessons"`
+	CompletedLessons int              `json:"completed_lessons"`
+	Percentage       int              `json:"percentage"`
+	CompletedAt      *time.Time       `json:"completed_at"`
+	Resume           *LessonProgress  `json:"resume"`
+	Lessons          []LessonProgress `json:"lessons,omitempty"`
+}
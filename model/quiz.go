@@ -0,0 +1,102 @@
+package model
+
+import "time"
+
+// Quiz holds the settings of a quiz lesson. TimeLimit is the length of an
+// attempt in seconds and MaxAttempts the attempts a user gets, 0 means no
+// limit for both. PassScore is the percentage needed to pass, taken from
+// the best or the latest attempt as ScorePolicy says.
+type Quiz struct {
+	LessonId    int        `json:"lesson_id"`
+	CourseId    int        `json:"course_id"`
+	TimeLimit   int        `json:"time_limit"`
+	MaxAttempts int        `json:"max_attempts"`
+	PassScore   int        `json:"pass_score"`
+	ScorePolicy string     `json:"score_policy"`
+	Questions   []Question `json:"questions,omitempty"`
+}
+
+// Question is one question of a quiz. Options are the choices of single and
+// multiple choice questions. Answer and Feedback are only shown to admins,
+// Feedback is shown to users with the result of their attempt.
+type Question struct {
+	Id       int        `json:"id"`
+	LessonId int        `json:"lesson_id"`
+	Type     string     `json:"type"`
+	Prompt   string     `json:"prompt"`
+	Options  []string   `json:"options,omitempty"`
+	Answer   *AnswerKey `json:"answer,omitempty"`
+	Points   int        `json:"points"`
+	Feedback string     `json:"feedback,omitempty"`
+	Position int        `json:"position"`
+}
+
+// AnswerKey is the correct answer of a question. Choices are indexes into
+// the options, Texts the accepted short answers, and a numeric answer is
+// correct when it is within Tolerance of Number.
+type AnswerKey struct {
+	Choices   []int    `json:"choices,omitempty"`
+	Truth     *bool    `json:"truth,omitempty"`
+	Texts     []string `json:"texts,omitempty"`
+	Number    *float64 `json:"number,omitempty"`
+	Tolerance float64  `json:"tolerance,omitempty"`
+}
+
+// Response is the answer of a user to a question, in the field matching
+// the question type.
+type Response struct {
+	QuestionId int      `json:"question_id"`
+	Choices    []int    `json:"choices,omitempty"`
+	Truth      *bool    `json:"truth,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Number     *float64 `json:"number,omitempty"`
+}
+
+// QuestionResult is the grade of one response.
+type QuestionResult struct {
+	QuestionId int    `json:"question_id"`
+	Correct    bool   `json:"correct"`
+	Points     int    `json:"points"`
+	MaxPoints  int    `json:"max_points"`
+	Feedback   string `json:"feedback,omitempty"`
+}
+
+// QuizAttempt is one try of a user at a quiz. Number counts the attempts
+// of the user at the quiz from 1. ExpiresAt is set when the quiz has a
+// time limit. The score and results are filled in once the
+// attempt is submitted. Questions are sent with a started attempt.
+type QuizAttempt struct {
+	Id          int              `json:"id"`
+	UserId      int              `json:"-"`
+	LessonId    int              `json:"lesson_id"`
+	CourseId    int              `json:"course_id"`
+	Number      int              `json:"number"`
+	StartedAt   time.Time        `json:"started_at"`
+	ExpiresAt   *time.Time       `json:"expires_at"`
+	SubmittedAt *time.Time       `json:"submitted_at"`
+	Score       int              `json:"score"`
+	MaxScore    int              `json:"max_score"`
+	Percentage  int              `json:"percentage"`
+	Passed      bool             `json:"passed"`
+	Responses   []Response       `json:"responses,omitempty"`
+	Results     []QuestionResult `json:"results,omitempty"`
+	Questions   []Question       `json:"questions,omitempty"`
+}
+
+// QuizSubmission is sent by clients to finish an attempt.
+type QuizSubmission struct {
+	Responses []Response `json:"responses"`
+}
+
+// QuizStatus sums up the attempts of a user at a quiz. Score is the
+// percentage counted by the score policy, nil before the first submitted
+// attempt.
+type QuizStatus struct {
+	LessonId    int           `json:"lesson_id"`
+	ScorePolicy string        `json:"score_policy"`
+	PassScore   int           `json:"pass_score"`
+	MaxAttempts int           `json:"max_attempts"`
+	Score       *int          `json:"score"`
+	Passed      bool          `json:"passed"`
+	Attempts    []QuizAttempt `json:"attempts"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// Review is the rating of a course by an enrolled user, from 1 to 5 stars,
+// with an optional text. Hidden reviews were hidden by a moderator, they
+// are shown to admins and their author only and don't count in the course
+// rating.
+type Review struct {
+	Id           int          `json:"id"`
+	CourseId     int          `json:"course_id"`
+	UserId       int          `json:"user_id"`
+	UserName     string       `json:"user_name"`
+	Rating       int          `json:"rating"`
+	Body         string       `json:"body"`
+	HelpfulCount int          `json:"helpful_count"`
+	FlagCount    int          `json:"flag_count,omitempty"`
+	Hidden       bool         `json:"hidden"`
+	Reply        *ReviewReply `json:"reply"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// ReviewReply is the answer of the instructor to a review.
+type ReviewReply struct {
+	Body      string    `json:"body"`
+	RepliedBy int       `json:"-"`
+	RepliedAt time.Time `json:"replied_at"`
+}
+
+// ReviewRequest creates or edits the review of a user.
+type ReviewRequest struct {
+	Rating int    `json:"rating"`
+	Body   string `json:"body"`
+}
+
+// ReplyRequest holds the reply to a review.
+type ReplyRequest struct {
+	Body string `json:"body"`
+}
+
+// ReviewFlag reports a review to the moderators.
+type ReviewFlag struct {
+	ReviewId  int
+	UserId    int
+	Reason    string
+	CreatedAt time.Time
+}
+
+// FlagRequest gives the reason for flagging a review.
+type FlagRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ModerationRequest hides or shows a review.
+type ModerationRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// RatingSummary is the rating of a course over its visible reviews.
+// Distribution counts the reviews per number of stars.
+type RatingSummary struct {
+	Average      float64     `json:"average"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"`
+}
@@ -0,0 +1,298 @@
+// Package payment holds the payment providers behind
+// usecase.PaymentGateway. Fake is an in-process provider for development
+// and tests.
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/logging"
+	"github.com/egaevan/online-learning/model"
+)
+
+// DefaultTolerance is how old a webhook signature may be before it is
+// refused as a replay.
+const DefaultTolerance = 5 * time.Minute
+
+// Deliveries of a webhook event are tried this often, the wait between
+// attempts starts at deliveryBackoff and doubles.
+const (
+	deliveryAttempts = 5
+	deliveryBackoff  = 50 * time.Millisecond
+)
+
+type fakePayment struct {
+	orderID int
+	amount  model.Money
+	status  string
+}
+
+// Fake moves payments like a provider would, without any money involved.
+// Payments wait for Confirm or Decline, as if the customer was paying,
+// unless AutoConfirm is set. Every step is sent to Deliver as a signed
+// webhook event.
+type Fake struct {
+	// Deliver receives the webhook events with their signature. It is
+	// called from a goroutine, as providers call webhooks after the
+	// request, and called again on error. Nil drops the events.
+	Deliver func(ctx context.Context, payload []byte, signature string) error
+	// AutoConfirm confirms payments right after they are created.
+	AutoConfirm bool
+	Tolerance   time.Duration
+
+	secret []byte
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	sending  sync.WaitGroup
+}
+
+// NewFake returns a fake provider signing webhooks with secret. Without a
+// secret a random one is used, then only events of this process verify.
+func NewFake(secret string) (*Fake, error) {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Fake{
+		Tolerance: DefaultTolerance,
+		secret:    key,
+		payments:  map[string]*fakePayment{},
+	}, nil
+}
+
+func randomID(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return prefix + hex.EncodeToString(b), nil
+}
+
+func (f *Fake) CreateIntent(ctx context.Context, orderID int, amount model.Money) (*model.PaymentIntent, error) {
+	if amount.Amount <= 0 {
+		return nil, fmt.Errorf("invalid payment amount %d", amount.Amount)
+	}
+
+	id, err := randomID("pi_")
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.payments[id] = &fakePayment{orderID: orderID, amount: amount, status: constant.PaymentCreated}
+	f.mu.Unlock()
+
+	if f.AutoConfirm {
+		if err := f.Confirm(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.PaymentIntent{
+		Id:           id,
+		OrderId:      orderID,
+		Amount:       amount,
+		ClientSecret: id + "_secret",
+	}, nil
+}
+
+// move changes the status of a payment that is in one of the from
+// statuses and sends the event of the new status.
+func (f *Fake) move(paymentID string, to string, event string, from ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("unknown payment %s", paymentID)
+	}
+
+	for _, status := range from {
+		if p.status == status {
+			p.status = to
+			return f.send(paymentID, p.amount, event)
+		}
+	}
+
+	return fmt.Errorf("payment %s is %s", paymentID, p.status)
+}
+
+// Confirm authorizes a payment as the customer would.
+func (f *Fake) Confirm(paymentID string) error {
+	return f.move(paymentID, constant.PaymentAuthorized, constant.EventPaymentAuthorized, constant.PaymentCreated)
+}
+
+// Decline fails a payment as a declined card would.
+func (f *Fake) Decline(paymentID string) error {
+	return f.move(paymentID, constant.PaymentFailed, constant.EventPaymentFailed, constant.PaymentCreated)
+}
+
+// Capture collects an authorized payment. Capturing it again is no error.
+func (f *Fake) Capture(ctx context.Context, paymentID string) error {
+	f.mu.Lock()
+	p, ok := f.payments[paymentID]
+	captured := ok && p.status == constant.PaymentSucceeded
+	f.mu.Unlock()
+
+	if captured {
+		return nil
+	}
+
+	return f.move(paymentID, constant.PaymentSucceeded, constant.EventPaymentSucceeded, constant.PaymentAuthorized)
+}
+
+// Refund pays a captured payment back. Only full refunds are supported.
+func (f *Fake) Refund(ctx context.Context, paymentID string, amount model.Money) error {
+	f.mu.Lock()
+	p, ok := f.payments[paymentID]
+	full := ok && p.amount == amount
+	f.mu.Unlock()
+
+	if ok && !full {
+		return fmt.Errorf("refund of %d %s does not match payment %s", amount.Amount, amount.Currency, paymentID)
+	}
+
+	return f.move(paymentID, constant.PaymentRefunded, constant.EventPaymentRefunded, constant.PaymentSucceeded)
+}
+
+// send signs the event and hands it to Deliver. The caller must hold mu.
+func (f *Fake) send(paymentID string, amount model.Money, eventType string) error {
+	id, err := randomID("evt_")
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(model.PaymentEvent{
+		Id:        id,
+		Type:      eventType,
+		PaymentId: paymentID,
+		Amount:    amount,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	if f.Deliver == nil {
+		return nil
+	}
+
+	signature := f.Sign(payload, time.Now())
+
+	f.sending.Add(1)
+
+	go func() {
+		defer f.sending.Done()
+
+		ctx := context.Background()
+
+		wait := deliveryBackoff
+		for attempt := 1; ; attempt++ {
+			err := f.Deliver(ctx, payload, signature)
+			if err == nil {
+				return
+			}
+
+			if attempt == deliveryAttempts {
+				logging.From(ctx, "payment").WithField("event_id", id).Errorf("payment webhook not delivered: %v", err)
+				return
+			}
+
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}()
+
+	return nil
+}
+
+// Wait blocks until every webhook event sent so far is delivered or given
+// up.
+func (f *Fake) Wait() {
+	f.sending.Wait()
+}
+
+// Sign returns the signature of a webhook body sent at the given time:
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">.
+func (f *Fake) Sign(payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(f.mac(timestamp, payload))
+}
+
+func (f *Fake) mac(timestamp string, payload []byte) []byte {
+	m := hmac.New(sha256.New, f.secret)
+	m.Write([]byte(timestamp))
+	m.Write([]byte("."))
+	m.Write(payload)
+
+	return m.Sum(nil)
+}
+
+// VerifyWebhook checks a signature made by Sign. Signatures older than
+// Tolerance are refused, so captured requests can't be replayed later.
+func (f *Fake) VerifyWebhook(payload []byte, signature string) (*model.PaymentEvent, error) {
+	var timestamp, sum string
+
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sum = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.New("signature without timestamp")
+	}
+
+	if age := time.Since(time.Unix(unix, 0)); age > f.Tolerance || age < -f.Tolerance {
+		return nil, fmt.Errorf("signature timestamp is %v off", age.Round(time.Second))
+	}
+
+	got, err := hex.DecodeString(sum)
+	if err != nil || !hmac.Equal(got, f.mac(timestamp, payload)) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	event := model.PaymentEvent{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	if event.Id == "" || event.PaymentId == "" {
+		return nil, errors.New("event without id or payment")
+	}
+
+	return &event, nil
+}
+
+// cut is strings.Cut, which needs a newer Go.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
@@ -0,0 +1,446 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Assignment struct {
+	DB *Database
+}
+
+func NewAssignmentRepository(db *Database) AssignmentRepository {
+	return &Assignment{
+		DB: db,
+	}
+}
+
+// saveAssignmentQuery inserts the settings of an assignment or updates the
+// existing row.
+var saveAssignmentQuery = map[Dialect]string{
+	MySQL: `
+				INSERT INTO assignment
+					(lesson_id, due_at, rubric, max_score, penalty_per_day, max_penalty, cutoff_days)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					due_at = VALUES(due_at),
+					rubric = VALUES(rubric),
+					max_score = VALUES(max_score),
+					penalty_per_day = VALUES(penalty_per_day),
+					max_penalty = VALUES(max_penalty),
+					cutoff_days = VALUES(cutoff_days)
+			`,
+	PostgreSQL: saveAssignmentOnConflict,
+	SQLite:     saveAssignmentOnConflict,
+}
+
+const saveAssignmentOnConflict = `
+				INSERT INTO assignment
+					(lesson_id, due_at, rubric, max_score, penalty_per_day, max_penalty, cutoff_days)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (lesson_id) DO UPDATE SET
+					due_at = excluded.due_at,
+					rubric = excluded.rubric,
+					max_score = excluded.max_score,
+					penalty_per_day = excluded.penalty_per_day,
+					max_penalty = excluded.max_penalty,
+					cutoff_days = excluded.cutoff_days
+			`
+
+// fetchAssignment returns the assignments of active lessons in active
+// sections matching where, in the order of the curriculum.
+func (a *Assignment) fetchAssignment(ctx context.Context, where string, args ...interface{}) (result []model.Assignment, err error) {
+	query := `
+			SELECT
+				assignment.lesson_id,
+				section.course_id,
+				lesson.title,
+				assignment.due_at,
+				assignment.rubric,
+				assignment.max_score,
+				assignment.penalty_per_day,
+				assignment.max_penalty,
+				assignment.cutoff_days
+			FROM
+				assignment
+			JOIN
+				lesson ON lesson.id = assignment.lesson_id AND lesson.flag_aktif = 1
+			JOIN
+				section ON section.id = lesson.section_id AND section.flag_aktif = 1
+			WHERE
+				%s
+			ORDER BY
+				section.position ASC, section.id ASC, lesson.position ASC, lesson.id ASC`
+
+	rows, err := a.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Assignment, 0)
+
+	for rows.Next() {
+		var rubric string
+
+		t := model.Assignment{}
+		err = rows.Scan(
+			&t.LessonId,
+			&t.CourseId,
+			&t.Title,
+			&t.DueAt,
+			&rubric,
+			&t.MaxScore,
+			&t.PenaltyPerDay,
+			&t.MaxPenalty,
+			&t.CutoffDays,
+		)
+
+		if err == nil {
+			err = json.Unmarshal([]byte(rubric), &t.Rubric)
+		}
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (a *Assignment) FindAssignment(ctx context.Context, lessonID int) (*model.Assignment, error) {
+	assignments, err := a.fetchAssignment(ctx, `assignment.lesson_id = ?`, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("%w: assignment %d", model.ErrNotFound, lessonID)
+	}
+
+	return &assignments[0], nil
+}
+
+func (a *Assignment) SaveAssignment(ctx context.Context, assignment model.Assignment) error {
+	rubric, err := jsonColumn(assignment.Rubric)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.DB.ExecContext(ctx, saveAssignmentQuery[a.DB.Dialect],
+		assignment.LessonId, assignment.DueAt, rubric, assignment.MaxScore, assignment.PenaltyPerDay, assignment.MaxPenalty, assignment.CutoffDays)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchAssignment returns the assignments of the course in the order of the
+// curriculum.
+func (a *Assignment) FetchAssignment(ctx context.Context, courseID int) ([]model.Assignment, error) {
+	return a.fetchAssignment(ctx, `section.course_id = ?`, courseID)
+}
+
+// fetchSubmission returns the submissions matching where, without their
+// files, oldest first.
+func (a *Assignment) fetchSubmission(ctx context.Context, where string, args ...interface{}) (result []model.Submission, err error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				lesson_id,
+				course_id,
+				submitted_at,
+				days_late,
+				scores,
+				raw_score,
+				penalty,
+				score,
+				comment,
+				graded_at,
+				graded_by
+			FROM
+				submission
+			WHERE
+				%s
+			ORDER BY
+				submitted_at ASC, id ASC`
+
+	rows, err := a.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Submission, 0)
+
+	for rows.Next() {
+		var scores string
+
+		t := model.Submission{}
+		err = rows.Scan(
+			&t.Id,
+			&t.UserId,
+			&t.LessonId,
+			&t.CourseId,
+			&t.SubmittedAt,
+			&t.DaysLate,
+			&scores,
+			&t.RawScore,
+			&t.Penalty,
+			&t.Score,
+			&t.Comment,
+			&t.GradedAt,
+			&t.GradedBy,
+		)
+
+		if err == nil {
+			err = json.Unmarshal([]byte(scores), &t.Scores)
+		}
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+// withFile returns the first submission with its files.
+func (a *Assignment) withFile(ctx context.Context, submissions []model.Submission) (*model.Submission, error) {
+	submission := submissions[0]
+
+	files, err := a.fetchFile(ctx, `submission_id = ?`, submission.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	submission.Files = files
+
+	return &submission, nil
+}
+
+func (a *Assignment) FindSubmission(ctx context.Context, submissionID int) (*model.Submission, error) {
+	submissions, err := a.fetchSubmission(ctx, `id = ?`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(submissions) == 0 {
+		return nil, fmt.Errorf("%w: submission %d", model.ErrNotFound, submissionID)
+	}
+
+	return a.withFile(ctx, submissions)
+}
+
+func (a *Assignment) FindUserSubmission(ctx context.Context, userID int, lessonID int) (*model.Submission, error) {
+	submissions, err := a.fetchSubmission(ctx, `user_id = ? AND lesson_id = ?`, userID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(submissions) == 0 {
+		return nil, fmt.Errorf("%w: submission of user %d to assignment %d", model.ErrNotFound, userID, lessonID)
+	}
+
+	return a.withFile(ctx, submissions)
+}
+
+// StoreSubmission stores the first submission of a user to an assignment,
+// without its files.
+func (a *Assignment) StoreSubmission(ctx context.Context, submission model.Submission) (int, error) {
+	query := `
+				INSERT INTO submission
+					(user_id, lesson_id, course_id, submitted_at, days_late, scores, comment)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := a.DB.InsertContext(ctx, query,
+		submission.UserId, submission.LessonId, submission.CourseId, submission.SubmittedAt, submission.DaysLate, "null", "")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Resubmit moves the submission to its new submission time unless it was
+// graded already, and reports whether it did.
+func (a *Assignment) Resubmit(ctx context.Context, submission model.Submission) (bool, error) {
+	query := `
+				UPDATE
+					submission
+				SET
+					submitted_at = ?,
+					days_late = ?
+				WHERE
+					id = ? AND graded_at IS NULL
+			`
+
+	res, err := a.DB.ExecContext(ctx, query, submission.SubmittedAt, submission.DaysLate, submission.Id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (a *Assignment) GradeSubmission(ctx context.Context, submission model.Submission) error {
+	query := `
+				UPDATE
+					submission
+				SET
+					scores = ?,
+					raw_score = ?,
+					penalty = ?,
+					score = ?,
+					comment = ?,
+					graded_at = ?,
+					graded_by = ?
+				WHERE
+					id = ?
+			`
+
+	scores, err := jsonColumn(submission.Scores)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.DB.ExecContext(ctx, query,
+		scores, submission.RawScore, submission.Penalty, submission.Score, submission.Comment, submission.GradedAt, submission.GradedBy, submission.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchSubmission returns the submissions to the course without their
+// files.
+func (a *Assignment) FetchSubmission(ctx context.Context, courseID int) ([]model.Submission, error) {
+	return a.fetchSubmission(ctx, `course_id = ?`, courseID)
+}
+
+// fetchFile returns the files matching where in upload order.
+func (a *Assignment) fetchFile(ctx context.Context, where string, args ...interface{}) (result []model.SubmissionFile, err error) {
+	query := `
+			SELECT
+				id,
+				submission_id,
+				name,
+				content_type,
+				size,
+				storage_key
+			FROM
+				submission_file
+			WHERE
+				%s
+			ORDER BY
+				id ASC`
+
+	rows, err := a.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.SubmissionFile, 0)
+
+	for rows.Next() {
+		t := model.SubmissionFile{}
+		err = rows.Scan(
+			&t.Id,
+			&t.SubmissionId,
+			&t.Name,
+			&t.ContentType,
+			&t.Size,
+			&t.Key,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (a *Assignment) FindFile(ctx context.Context, fileID int) (*model.SubmissionFile, error) {
+	files, err := a.fetchFile(ctx, `id = ?`, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: file %d", model.ErrNotFound, fileID)
+	}
+
+	return &files[0], nil
+}
+
+func (a *Assignment) StoreFile(ctx context.Context, file model.SubmissionFile) (int, error) {
+	query := `
+				INSERT INTO submission_file
+					(submission_id, name, content_type, size, storage_key)
+				VALUES
+					(?, ?, ?, ?, ?)
+			`
+
+	id, err := a.DB.InsertContext(ctx, query, file.SubmissionId, file.Name, file.ContentType, file.Size, file.Key)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// DeleteFile deletes every file of the submission.
+func (a *Assignment) DeleteFile(ctx context.Context, submissionID int) error {
+	query := `DELETE FROM submission_file WHERE submission_id = ?`
+
+	_, err := a.DB.ExecContext(ctx, query, submissionID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Cart struct {
+	DB *Database
+}
+
+func NewCartRepository(db *Database) CartRepository {
+	return &Cart{
+		DB: db,
+	}
+}
+
+// cartQuery adds a course to the cart, adding it again changes nothing.
+var cartQuery = map[Dialect]string{
+	MySQL: `
+				INSERT IGNORE INTO cart_item
+					(user_id, course_id, created_at)
+				VALUES
+					(?, ?, ?)
+			`,
+	PostgreSQL: cartOnConflict,
+	SQLite:     cartOnConflict,
+}
+
+const cartOnConflict = `
+				INSERT INTO cart_item
+					(user_id, course_id, created_at)
+				VALUES
+					(?, ?, ?)
+				ON CONFLICT (user_id, course_id) DO NOTHING
+			`
+
+// Fetch returns the courses in the cart of the user at their current
+// price, in the order they were added.
+func (c *Cart) Fetch(ctx context.Context, userID int) (result []model.CartItem, err error) {
+	query := `
+			SELECT
+				course.id,
+				course.name,
+				course.category_id,
+				course.price,
+				cart_item.created_at
+			FROM
+				cart_item
+			JOIN
+				course ON course.id = cart_item.course_id
+			WHERE
+				cart_item.user_id = ? AND course.flag_aktif = 1
+			ORDER BY
+				cart_item.created_at ASC, course.id ASC`
+
+	rows, err := c.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.CartItem, 0)
+
+	for rows.Next() {
+		t := model.CartItem{}
+		err = rows.Scan(
+			&t.CourseId,
+			&t.CourseName,
+			&t.CategoryId,
+			&t.Price.Amount,
+			&t.AddedAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (c *Cart) Add(ctx context.Context, userID int, courseID int, at time.Time) error {
+	_, err := c.DB.ExecContext(ctx, cartQuery[c.DB.Dialect], userID, courseID, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cart) Remove(ctx context.Context, userID int, courseID int) error {
+	_, err := c.DB.ExecContext(ctx, `DELETE FROM cart_item WHERE user_id = ? AND course_id = ?`, userID, courseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Clear empties the cart of the user.
+func (c *Cart) Clear(ctx context.Context, userID int) error {
+	_, err := c.DB.ExecContext(ctx, `DELETE FROM cart_item WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
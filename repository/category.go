@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+func (c *Course) FetchCategoryNode(ctx context.Context) (result []model.CategoryNode, err error) {
+	query := `
+			SELECT
+				category.id,
+				category.name,
+				category.parent_id,
+				COUNT(course.id)
+			FROM
+				category
+			LEFT JOIN
+				course ON course.category_id = category.id AND course.flag_aktif = 1
+			GROUP BY
+				category.id, category.name, category.parent_id`
+
+	rows, err := c.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.CategoryNode, 0)
+
+	for rows.Next() {
+		t := model.CategoryNode{}
+		parentID := sql.NullInt64{}
+		err = rows.Scan(
+			&t.Id,
+			&t.Name,
+			&parentID,
+			&t.CourseCount,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		t.ParentId = int(parentID.Int64)
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *Course) FetchByCategory(ctx context.Context, categoryIDs []int) (result []model.Course, err error) {
+	result = make([]model.Course, 0)
+	if len(categoryIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+			SELECT
+				id,
+				category_id,
+				name,
+				price,
+				enrollment_count,
+				rating_average,
+				rating_count
+			FROM
+				course
+			WHERE
+				category_id IN (%s) AND flag_aktif = 1
+			ORDER BY
+				id ASC`
+
+	args := make([]interface{}, 0, len(categoryIDs))
+	for _, id := range categoryIDs {
+		args = append(args, id)
+	}
+
+	placeholder := strings.TrimSuffix(strings.Repeat("?, ", len(categoryIDs)), ", ")
+
+	rows, err := c.DB.QueryContext(ctx, strings.Replace(query, "%s", placeholder, 1), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	for rows.Next() {
+		t := model.Course{}
+		err = rows.Scan(
+			&t.Id,
+			&t.CategoryId,
+			&t.Name,
+			&t.Price,
+			&t.EnrollmentCount,
+			&t.RatingAverage,
+			&t.RatingCount,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func (c *Course) StoreCategory(ctx context.Context, category model.Category) (int, error) {
+	query := `
+				INSERT INTO category
+					(name, parent_id)
+				VALUES
+					(?, ?)
+			`
+
+	parentID := sql.NullInt64{
+		Int64: int64(category.ParentId),
+		Valid: category.ParentId != 0,
+	}
+
+	id, err := c.DB.InsertContext(ctx, query, category.Name, parentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Certificate struct {
+	DB *Database
+}
+
+func NewCertificateRepository(db *Database) CertificateRepository {
+	return &Certificate{
+		DB: db,
+	}
+}
+
+// fetchCertificate returns the certificates matching where, newest first.
+func (c *Certificate) fetchCertificate(ctx context.Context, where string, args ...interface{}) (result []model.Certificate, err error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				course_id,
+				student_name,
+				course_name,
+				completed_at,
+				issued_at,
+				sha256,
+				revoked_at,
+				revoke_reason
+			FROM
+				certificate
+			WHERE
+				%s
+			ORDER BY
+				issued_at DESC, id ASC`
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Certificate, 0)
+
+	for rows.Next() {
+		t := model.Certificate{}
+		err = rows.Scan(
+			&t.Id,
+			&t.UserId,
+			&t.CourseId,
+			&t.StudentName,
+			&t.CourseName,
+			&t.CompletedAt,
+			&t.IssuedAt,
+			&t.Sha256,
+			&t.RevokedAt,
+			&t.RevokeReason,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (c *Certificate) FindOne(ctx context.Context, certificateID string) (*model.Certificate, error) {
+	certificates, err := c.fetchCertificate(ctx, `id = ?`, certificateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%w: certificate %s", model.ErrNotFound, certificateID)
+	}
+
+	return &certificates[0], nil
+}
+
+func (c *Certificate) FindByCourse(ctx context.Context, userID int, courseID int) (*model.Certificate, error) {
+	certificates, err := c.fetchCertificate(ctx, `user_id = ? AND course_id = ?`, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%w: certificate of user %d for course %d", model.ErrNotFound, userID, courseID)
+	}
+
+	return &certificates[0], nil
+}
+
+// FetchByUser returns the certificates of the user, newest first.
+func (c *Certificate) FetchByUser(ctx context.Context, userID int) ([]model.Certificate, error) {
+	return c.fetchCertificate(ctx, `user_id = ?`, userID)
+}
+
+// Store inserts the certificate with its id. A user has one certificate
+// per course, storing a second one fails.
+func (c *Certificate) Store(ctx context.Context, certificate model.Certificate) error {
+	query := `
+				INSERT INTO certificate
+					(id, user_id, course_id, student_name, course_name, completed_at, issued_at, sha256)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	_, err := c.DB.ExecContext(ctx, query,
+		certificate.Id, certificate.UserId, certificate.CourseId, certificate.StudentName, certificate.CourseName,
+		certificate.CompletedAt, certificate.IssuedAt, certificate.Sha256)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Revoke marks the certificate as revoked at the given time unless it is
+// revoked already, and reports whether it did.
+func (c *Certificate) Revoke(ctx context.Context, certificateID string, at time.Time, reason string) (bool, error) {
+	query := `
+				UPDATE
+					certificate
+				SET
+					revoked_at = ?,
+					revoke_reason = ?
+				WHERE
+					id = ? AND revoked_at IS NULL
+			`
+
+	res, err := c.DB.ExecContext(ctx, query, at, reason, certificateID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Coupon struct {
+	DB *Database
+}
+
+func NewCouponRepository(db *Database) CouponRepository {
+	return &Coupon{
+		DB: db,
+	}
+}
+
+// fetchCoupon returns the active coupons matching where, newest first, with
+// the courses and categories they cover.
+func (c *Coupon) fetchCoupon(ctx context.Context, where string, args ...interface{}) (result []model.Coupon, err error) {
+	query := `
+			SELECT
+				id,
+				code,
+				type,
+				value,
+				starts_at,
+				ends_at,
+				max_uses,
+				max_uses_per_user,
+				min_amount,
+				used_count,
+				created_at,
+				updated_at
+			FROM
+				coupon
+			WHERE
+				flag_aktif = 1 AND %s
+			ORDER BY
+				created_at DESC, id DESC`
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Coupon, 0)
+
+	for rows.Next() {
+		t := model.Coupon{CourseIds: []int{}, CategoryIds: []int{}}
+		err = rows.Scan(
+			&t.Id,
+			&t.Code,
+			&t.Type,
+			&t.Value,
+			&t.StartsAt,
+			&t.EndsAt,
+			&t.MaxUses,
+			&t.MaxUsesPerUser,
+			&t.MinAmount,
+			&t.UsedCount,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	courses, err := c.fetchScope(ctx, "coupon_course", "course_id", where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := c.fetchScope(ctx, "coupon_category", "category_id", where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		if v, ok := courses[result[i].Id]; ok {
+			result[i].CourseIds = v
+		}
+
+		if v, ok := categories[result[i].Id]; ok {
+			result[i].CategoryIds = v
+		}
+	}
+
+	return result, nil
+}
+
+// fetchScope returns the ids in column of table by coupon id, for the
+// coupons matching where.
+func (c *Coupon) fetchScope(ctx context.Context, table string, column string, where string, args ...interface{}) (result map[int][]int, err error) {
+	query := `
+			SELECT
+				coupon_id,
+				%[2]s
+			FROM
+				%[1]s
+			WHERE
+				coupon_id IN (SELECT id FROM coupon WHERE flag_aktif = 1 AND %[3]s)
+			ORDER BY
+				%[2]s ASC`
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, table, column, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = map[int][]int{}
+
+	for rows.Next() {
+		var couponID, id int
+
+		if err := rows.Scan(&couponID, &id); err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result[couponID] = append(result[couponID], id)
+	}
+
+	return result, rows.Err()
+}
+
+// Fetch returns the active coupons, newest first.
+func (c *Coupon) Fetch(ctx context.Context) ([]model.Coupon, error) {
+	return c.fetchCoupon(ctx, `1 = 1`)
+}
+
+func (c *Coupon) FindOne(ctx context.Context, couponID int) (*model.Coupon, error) {
+	coupons, err := c.fetchCoupon(ctx, `id = ?`, couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(coupons) == 0 {
+		return nil, fmt.Errorf("%w: coupon %d", model.ErrNotFound, couponID)
+	}
+
+	return &coupons[0], nil
+}
+
+// FindByCode returns the active coupon with the code. Codes are compared
+// as stored, callers normalize them.
+func (c *Coupon) FindByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	coupons, err := c.fetchCoupon(ctx, `code = ?`, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(coupons) == 0 {
+		return nil, fmt.Errorf("%w: coupon %q", model.ErrNotFound, code)
+	}
+
+	return &coupons[0], nil
+}
+
+// storeScope inserts the courses and categories a coupon covers.
+func (c *Coupon) storeScope(ctx context.Context, coupon model.Coupon) error {
+	for _, courseID := range coupon.CourseIds {
+		_, err := c.DB.ExecContext(ctx, `INSERT INTO coupon_course (coupon_id, course_id) VALUES (?, ?)`, coupon.Id, courseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, categoryID := range coupon.CategoryIds {
+		_, err := c.DB.ExecContext(ctx, `INSERT INTO coupon_category (coupon_id, category_id) VALUES (?, ?)`, coupon.Id, categoryID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Store inserts the coupon with its scope. Run it in a transaction, so a
+// coupon is never stored covering more than it should. The code of an
+// active coupon is unique, taking it twice returns model.ErrConflict.
+func (c *Coupon) Store(ctx context.Context, coupon model.Coupon) (int, error) {
+	query := `
+				INSERT INTO coupon
+					(code, type, value, starts_at, ends_at, max_uses, max_uses_per_user, min_amount, created_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := c.DB.InsertContext(ctx, query,
+		coupon.Code, coupon.Type, coupon.Value, coupon.StartsAt, coupon.EndsAt,
+		coupon.MaxUses, coupon.MaxUsesPerUser, coupon.MinAmount, coupon.CreatedAt, coupon.UpdatedAt)
+	if c.DB.Dialect.IsDuplicate(err) {
+		return 0, fmt.Errorf("%w: code %s is in use", model.ErrConflict, coupon.Code)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	coupon.Id = int(id)
+
+	if err := c.storeScope(ctx, coupon); err != nil {
+		return 0, err
+	}
+
+	return coupon.Id, nil
+}
+
+// Update replaces the settings and the scope of the coupon, its use count
+// stays. Run it in a transaction like Store, the code is unique like there.
+func (c *Coupon) Update(ctx context.Context, coupon model.Coupon) error {
+	query := `
+				UPDATE
+					coupon
+				SET
+					code = ?,
+					type = ?,
+					value = ?,
+					starts_at = ?,
+					ends_at = ?,
+					max_uses = ?,
+					max_uses_per_user = ?,
+					min_amount = ?,
+					updated_at = ?
+				WHERE
+					id = ?
+			`
+
+	_, err := c.DB.ExecContext(ctx, query,
+		coupon.Code, coupon.Type, coupon.Value, coupon.StartsAt, coupon.EndsAt,
+		coupon.MaxUses, coupon.MaxUsesPerUser, coupon.MinAmount, coupon.UpdatedAt, coupon.Id)
+	if c.DB.Dialect.IsDuplicate(err) {
+		return fmt.Errorf("%w: code %s is in use", model.ErrConflict, coupon.Code)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"coupon_course", "coupon_category"} {
+		if _, err := c.DB.ExecContext(ctx, `DELETE FROM `+table+` WHERE coupon_id = ?`, coupon.Id); err != nil {
+			return err
+		}
+	}
+
+	return c.storeScope(ctx, coupon)
+}
+
+func (c *Coupon) Delete(ctx context.Context, couponID int) error {
+	query := `
+				UPDATE
+					coupon
+				SET
+					flag_aktif = 0
+				WHERE
+					id = ?
+			`
+
+	_, err := c.DB.ExecContext(ctx, query, couponID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// countRedemptionQuery counts the redemptions of a user while Redeem holds
+// the coupon row. A MySQL transaction reads its snapshot, which misses the
+// redemptions committed while it waited for the row, unless the read locks.
+// PostgreSQL reads committed rows and SQLite has a single writer.
+var countRedemptionQuery = map[Dialect]string{
+	MySQL:      countRedemption + ` FOR UPDATE`,
+	PostgreSQL: countRedemption,
+	SQLite:     countRedemption,
+}
+
+const countRedemption = `SELECT COUNT(*) FROM coupon_redemption WHERE coupon_id = ? AND user_id = ?`
+
+// Redeem counts a use of the coupon and records the redemption, unless the
+// coupon, or its uses by the user, are used up or it is deleted meanwhile.
+// The conditional update locks the coupon row until the transaction ends,
+// so concurrent redemptions can't pass the limits together.
+func (c *Coupon) Redeem(ctx context.Context, redemption model.CouponRedemption) (bool, error) {
+	query := `
+				UPDATE
+					coupon
+				SET
+					used_count = used_count + 1
+				WHERE
+					id = ? AND flag_aktif = 1 AND (max_uses = 0 OR used_count < max_uses)
+			`
+
+	res, err := c.DB.ExecContext(ctx, query, redemption.CouponId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	var perUser, count int
+
+	err = c.DB.QueryRowContext(ctx, `SELECT max_uses_per_user FROM coupon WHERE id = ?`, redemption.CouponId).Scan(&perUser)
+	if err != nil {
+		return false, err
+	}
+
+	if perUser > 0 {
+		err = c.DB.QueryRowContext(ctx, countRedemptionQuery[c.DB.Dialect], redemption.CouponId, redemption.UserId).Scan(&count)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if perUser > 0 && count >= perUser {
+		// Give the use back, the user has none left.
+		_, err = c.DB.ExecContext(ctx, `UPDATE coupon SET used_count = used_count - 1 WHERE id = ?`, redemption.CouponId)
+
+		return false, err
+	}
+
+	query = `
+				INSERT INTO coupon_redemption
+					(coupon_id, user_id, order_id, discount, currency, created_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?)
+			`
+
+	_, err = c.DB.InsertContext(ctx, query,
+		redemption.CouponId, redemption.UserId, redemption.OrderId,
+		redemption.Discount.Amount, redemption.Discount.Currency, redemption.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CountRedemption returns how often the user redeemed the coupon. It reads
+// without locking, Redeem checks the count again.
+func (c *Coupon) CountRedemption(ctx context.Context, couponID int, userID int) (int, error) {
+	var count int
+
+	err := c.DB.QueryRowContext(ctx, countRedemption, couponID, userID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Release deletes the redemption of the order and gives the use back to
+// its coupon. Orders without a redemption are left alone.
+func (c *Coupon) Release(ctx context.Context, orderID int) error {
+	query := `
+				UPDATE
+					coupon
+				SET
+					used_count = used_count - 1
+				WHERE
+					id IN (SELECT coupon_id FROM coupon_redemption WHERE order_id = ?) AND used_count > 0
+			`
+
+	if _, err := c.DB.ExecContext(ctx, query, orderID); err != nil {
+		return err
+	}
+
+	if _, err := c.DB.ExecContext(ctx, `DELETE FROM coupon_redemption WHERE order_id = ?`, orderID); err != nil {
+		return err
+	}
+
+	return nil
+}
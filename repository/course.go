@@ -4,17 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/egaevan/online-learning/constant"
 	"github.com/egaevan/online-learning/model"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type Course struct {
-	DB *sql.DB
+	DB *Database
 }
 
-func NewCourseRepository(db *sql.DB) CourseRepository {
+func NewCourseRepository(db *Database) CourseRepository {
 	return &Course{
 		DB: db,
 	}
@@ -26,7 +27,10 @@ func (c *Course) FindOne(ctx context.Context, courseID int) (*model.CourseDetail
 				course.id,
 				course.name,
 				course.price,
-				course.count,
+				course.enrollment_count,
+				course.version,
+				course.rating_average,
+				course.rating_count,
 				category.id,
 				category.name
 			FROM 
@@ -38,10 +42,10 @@ func (c *Course) FindOne(ctx context.Context, courseID int) (*model.CourseDetail
 
 	course := model.CourseDetail{}
 
-	err := c.DB.QueryRowContext(ctx, query, courseID).Scan(&course.Id, &course.Name, &course.Price, &course.Count, &course.Category.Id, &course.Category.Name)
+	err := c.DB.QueryRowContext(ctx, query, courseID).Scan(&course.Id, &course.Name, &course.Price, &course.EnrollmentCount, &course.Version, &course.Rating.Average, &course.Rating.Count, &course.Category.Id, &course.Category.Name)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("data not found %s", err.Error())
+			return nil, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
 		}
 		return nil, err
 	}
@@ -53,13 +57,18 @@ func (c *Course) Fetch(ctx context.Context) (result []model.Course, err error) {
 	query := `
 			SELECT 
 				id,
+				category_id,
 				name,
 				price,
-				count
+				enrollment_count,
+				rating_average,
+				rating_count
 			FROM 
 				course
 			WHERE
-				flag_aktif = 1`
+				flag_aktif = 1
+			ORDER BY
+				id ASC`
 
 	rows, err := c.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -69,7 +78,7 @@ func (c *Course) Fetch(ctx context.Context) (result []model.Course, err error) {
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logger(ctx).Error(errRow)
 		}
 	}()
 
@@ -79,13 +88,16 @@ func (c *Course) Fetch(ctx context.Context) (result []model.Course, err error) {
 		t := model.Course{}
 		err = rows.Scan(
 			&t.Id,
+			&t.CategoryId,
 			&t.Name,
 			&t.Price,
-			&t.Count,
+			&t.EnrollmentCount,
+			&t.RatingAverage,
+			&t.RatingCount,
 		)
 
 		if err != nil {
-			log.Error(err)
+			logger(ctx).Error(err)
 			return nil, err
 		}
 
@@ -95,24 +107,27 @@ func (c *Course) Fetch(ctx context.Context) (result []model.Course, err error) {
 	return result, nil
 }
 
-func (c *Course) Store(ctx context.Context, course model.Course) error {
+func (c *Course) Store(ctx context.Context, course model.Course) (int, error) {
 	query := `
 				INSERT INTO course
-					(id,name,price,count)
+					(category_id, name, price)
 				VALUES
-					(?, ?, ?, ?, ?)
+					(?, ?, ?)
 			`
 
-	_, err := c.DB.ExecContext(ctx, query,
-		course.Id, course.Name, course.Price, course.Count)
+	id, err := c.DB.InsertContext(ctx, query,
+		course.CategoryId, course.Name, course.Price)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return int(id), nil
 }
 
+// Update overwrites the course only if it is still active and at
+// course.Version, and bumps the version. Otherwise it returns
+// model.ErrConflict.
 func (c *Course) Update(ctx context.Context, course model.CourseUpdate, courseID int) error {
 	query := `
 				UPDATE 
@@ -120,19 +135,28 @@ func (c *Course) Update(ctx context.Context, course model.CourseUpdate, courseID
 				SET
 					category_id = ?, 
 					name = ?, 
-					price = ?, 
-					count = ?
+					price = ?,
+					version = version + 1
 				WHERE
-					id = ?
+					id = ? AND version = ? AND flag_aktif = 1
 			`
 
-	_, err := c.DB.ExecContext(ctx, query,
-		course.CategoryId, course.Name, course.Price, course.Count, courseID)
+	res, err := c.DB.ExecContext(ctx, query,
+		course.CategoryId, course.Name, course.Price, courseID, course.Version)
+
+	if err != nil {
+		return err
+	}
 
+	affected, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if affected == 0 {
+		return model.ErrConflict
+	}
+
 	return nil
 
 }
@@ -160,15 +184,20 @@ func (c *Course) Search(ctx context.Context, search string) (result []model.Cour
 	query := `
 			SELECT
 				id,
+				category_id,
 				name,
 				price,
-				count
+				enrollment_count,
+				rating_average,
+				rating_count
 			FROM
 				course
 			WHERE
-				name LIKE '%%%s%%' AND flag_aktif = 1`
+				LOWER(name) LIKE ? AND flag_aktif = 1
+			ORDER BY
+				id ASC`
 
-	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, search))
+	rows, err := c.DB.QueryContext(ctx, query, "%"+strings.ToLower(search)+"%")
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +205,7 @@ func (c *Course) Search(ctx context.Context, search string) (result []model.Cour
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logger(ctx).Error(errRow)
 		}
 	}()
 
@@ -186,13 +215,16 @@ func (c *Course) Search(ctx context.Context, search string) (result []model.Cour
 		t := model.Course{}
 		err = rows.Scan(
 			&t.Id,
+			&t.CategoryId,
 			&t.Name,
 			&t.Price,
-			&t.Count,
+			&t.EnrollmentCount,
+			&t.RatingAverage,
+			&t.RatingCount,
 		)
 
 		if err != nil {
-			log.Error(err)
+			logger(ctx).Error(err)
 			return nil, err
 		}
 
@@ -202,19 +234,34 @@ func (c *Course) Search(ctx context.Context, search string) (result []model.Cour
 	return result, nil
 }
 
+var sortQuery = map[string]string{
+	constant.SortLowPrice:  "ORDER BY price ASC, id ASC",
+	constant.SortHighPrice: "ORDER BY price DESC, id ASC",
+	constant.SortFree:      "AND price = 0 ORDER BY id ASC",
+	constant.SortRating:    "ORDER BY rating_average DESC, rating_count DESC, id ASC",
+}
+
 func (c *Course) Sort(ctx context.Context, sort string) (result []model.Course, err error) {
+	order, ok := sortQuery[sort]
+	if !ok {
+		return nil, model.ErrBadParamInput
+	}
+
 	query := `
 			SELECT
 				id,
+				category_id,
 				name,
 				price,
-				count
+				enrollment_count,
+				rating_average,
+				rating_count
 			FROM
 				course
 			WHERE
 				flag_aktif = 1 %s`
 
-	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, sort))
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, order))
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +269,7 @@ func (c *Course) Sort(ctx context.Context, sort string) (result []model.Course,
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logger(ctx).Error(errRow)
 		}
 	}()
 
@@ -232,13 +279,16 @@ func (c *Course) Sort(ctx context.Context, sort string) (result []model.Course,
 		t := model.Course{}
 		err = rows.Scan(
 			&t.Id,
+			&t.CategoryId,
 			&t.Name,
 			&t.Price,
-			&t.Count,
+			&t.EnrollmentCount,
+			&t.RatingAverage,
+			&t.RatingCount,
 		)
 
 		if err != nil {
-			log.Error(err)
+			logger(ctx).Error(err)
 			return nil, err
 		}
 
@@ -249,7 +299,7 @@ func (c *Course) Sort(ctx context.Context, sort string) (result []model.Course,
 }
 
 func (c *Course) Statistic(ctx context.Context) (*model.StatisticResponse, error) {
-	query := `SELECT COUNT(id) FROM user WHERE role = 1`
+	query := `SELECT COUNT(id) FROM users WHERE role = 1`
 	query2 := `SELECT COUNT(id) FROM course`
 	query3 := `SELECT COUNT(id) FROM course WHERE price = 0`
 
@@ -258,7 +308,7 @@ func (c *Course) Statistic(ctx context.Context) (*model.StatisticResponse, error
 	err := c.DB.QueryRowContext(ctx, query).Scan(&statistic.TotalUser)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("data not found %s", err.Error())
+			return nil, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
 		}
 		return nil, err
 	}
@@ -266,7 +316,7 @@ func (c *Course) Statistic(ctx context.Context) (*model.StatisticResponse, error
 	err = c.DB.QueryRowContext(ctx, query2).Scan(&statistic.TotalCourse)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("data not found %s", err.Error())
+			return nil, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
 		}
 		return nil, err
 	}
@@ -274,7 +324,7 @@ func (c *Course) Statistic(ctx context.Context) (*model.StatisticResponse, error
 	err = c.DB.QueryRowContext(ctx, query3).Scan(&statistic.TotalCourseFree)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("data not found %s", err.Error())
+			return nil, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
 		}
 		return nil, err
 	}
@@ -288,9 +338,13 @@ func (c *Course) FetchCategory(ctx context.Context) (result []model.CategoryDeta
 			SELECT 
 				id,
 				name,
-				count
+				parent_id,
+				course_count,
+				enrollment_count
 			FROM 
-				category`
+				category
+			ORDER BY
+				id ASC`
 
 	rows, err := c.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -300,7 +354,7 @@ func (c *Course) FetchCategory(ctx context.Context) (result []model.CategoryDeta
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logger(ctx).Error(errRow)
 		}
 	}()
 
@@ -308,37 +362,50 @@ func (c *Course) FetchCategory(ctx context.Context) (result []model.CategoryDeta
 
 	for rows.Next() {
 		t := model.CategoryDetail{}
+		parentID := sql.NullInt64{}
 		err = rows.Scan(
 			&t.Id,
 			&t.Name,
-			&t.Count,
+			&parentID,
+			&t.CourseCount,
+			&t.EnrollmentCount,
 		)
 
 		if err != nil {
-			log.Error(err)
+			logger(ctx).Error(err)
 			return nil, err
 		}
 
+		t.ParentId = int(parentID.Int64)
+
 		result = append(result, t)
 	}
 
 	return result, nil
 }
 
-func (c *Course) FetchPopularCategory(ctx context.Context, limit int) (result []model.CategoryDetail, err error) {
+func (c *Course) FetchPopularCategory(ctx context.Context, limit int, since time.Time) (result []model.CategoryDetail, err error) {
 	query := `
 			SELECT 
-				id,
-				name,
-				count
+				category.id,
+				category.name,
+				category.parent_id,
+				category.course_count,
+				COUNT(enrollment.id) AS recent_enrollment
 			FROM 
 				category
+			LEFT JOIN
+				course ON course.category_id = category.id AND course.flag_aktif = 1
+			LEFT JOIN
+				enrollment ON enrollment.course_id = course.id AND enrollment.created_at >= ?
+			GROUP BY
+				category.id, category.name, category.parent_id, category.course_count
 			ORDER BY
-				count DESC
+				recent_enrollment DESC, category.id ASC
 			LIMIT
 				?`
 
-	rows, err := c.DB.QueryContext(ctx, query, limit)
+	rows, err := c.DB.QueryContext(ctx, query, since, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -346,7 +413,7 @@ func (c *Course) FetchPopularCategory(ctx context.Context, limit int) (result []
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logger(ctx).Error(errRow)
 		}
 	}()
 
@@ -354,19 +421,90 @@ func (c *Course) FetchPopularCategory(ctx context.Context, limit int) (result []
 
 	for rows.Next() {
 		t := model.CategoryDetail{}
+		parentID := sql.NullInt64{}
 		err = rows.Scan(
 			&t.Id,
 			&t.Name,
-			&t.Count,
+			&parentID,
+			&t.CourseCount,
+			&t.EnrollmentCount,
 		)
 
 		if err != nil {
-			log.Error(err)
+			logger(ctx).Error(err)
 			return nil, err
 		}
 
+		t.ParentId = int(parentID.Int64)
+
 		result = append(result, t)
 	}
 
 	return result, nil
 }
+
+// RecomputeCount rebuilds the denormalized enrollment and course counters
+// from the enrollment and course tables, and the course ratings from the
+// reviews.
+func (c *Course) RecomputeCount(ctx context.Context) error {
+	queries := []string{`
+				UPDATE
+					course
+				SET
+					enrollment_count = (
+						SELECT COUNT(enrollment.id) FROM enrollment WHERE enrollment.course_id = course.id
+					)
+			`, `
+				UPDATE
+					category
+				SET
+					course_count = (
+						SELECT COUNT(course.id) FROM course WHERE course.category_id = category.id AND course.flag_aktif = 1
+					),
+					enrollment_count = (
+						SELECT COALESCE(SUM(course.enrollment_count), 0) FROM course WHERE course.category_id = category.id AND course.flag_aktif = 1
+					)
+			`, recomputeRatingQuery}
+
+	for _, query := range queries {
+		_, err := c.DB.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecomputeCategoryCount rebuilds the course and enrollment counters of the
+// given categories only, for writes that move courses between them.
+func (c *Course) RecomputeCategoryCount(ctx context.Context, categoryIDs []int) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	query := `
+				UPDATE
+					category
+				SET
+					course_count = (
+						SELECT COUNT(course.id) FROM course WHERE course.category_id = category.id AND course.flag_aktif = 1
+					),
+					enrollment_count = (
+						SELECT COALESCE(SUM(course.enrollment_count), 0) FROM course WHERE course.category_id = category.id AND course.flag_aktif = 1
+					)
+				WHERE
+					id IN (%s)
+			`
+
+	args := make([]interface{}, 0, len(categoryIDs))
+	for _, id := range categoryIDs {
+		args = append(args, id)
+	}
+
+	placeholder := strings.TrimSuffix(strings.Repeat("?, ", len(categoryIDs)), ", ")
+
+	_, err := c.DB.ExecContext(ctx, strings.Replace(query, "%s", placeholder, 1), args...)
+
+	return err
+}
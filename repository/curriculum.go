@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+// boolInt stores a bool in the TINYINT/SMALLINT flag columns.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// BumpVersion marks the course as modified, e.g. when its curriculum
+// changes, so cached copies of the course detail go stale.
+func (c *Course) BumpVersion(ctx context.Context, courseID int) error {
+	query := `
+				UPDATE
+					course
+				SET
+					version = version + 1
+				WHERE
+					id = ? AND flag_aktif = 1
+			`
+
+	_, err := c.DB.ExecContext(ctx, query, courseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchCurriculum returns the active sections of the course in order, each
+// with its active lessons in order.
+func (c *Course) FetchCurriculum(ctx context.Context, courseID int) ([]model.Section, error) {
+	result, err := c.fetchSection(ctx, `course_id = ?`, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons, err := c.fetchLesson(ctx, `section.course_id = ?`, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[int]int{}
+	for i, v := range result {
+		index[v.Id] = i
+	}
+
+	for _, l := range lessons {
+		if i, ok := index[l.SectionId]; ok {
+			result[i].Lessons = append(result[i].Lessons, l)
+		}
+	}
+
+	return result, nil
+}
+
+// fetchSection returns the active sections matching where, without their
+// lessons, ordered by position.
+func (c *Course) fetchSection(ctx context.Context, where string, args ...interface{}) (result []model.Section, err error) {
+	query := `
+			SELECT
+				id,
+				course_id,
+				title,
+				position
+			FROM
+				section
+			WHERE
+				%s AND flag_aktif = 1
+			ORDER BY
+				position ASC, id ASC`
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Section, 0)
+
+	for rows.Next() {
+		t := model.Section{Lessons: []model.Lesson{}}
+		err = rows.Scan(
+			&t.Id,
+			&t.CourseId,
+			&t.Title,
+			&t.Position,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+// fetchLesson returns the active lessons of active sections matching where,
+// ordered by section and lesson position.
+func (c *Course) fetchLesson(ctx context.Context, where string, args ...interface{}) (result []model.Lesson, err error) {
+	query := `
+			SELECT
+				lesson.id,
+				lesson.section_id,
+				section.course_id,
+				lesson.title,
+				lesson.type,
+				lesson.content,
+				lesson.duration,
+				lesson.free_preview,
+				lesson.optional,
+				lesson.position
+			FROM
+				lesson
+			JOIN
+				section ON section.id = lesson.section_id
+			WHERE
+				%s AND lesson.flag_aktif = 1 AND section.flag_aktif = 1
+			ORDER BY
+				section.position ASC, section.id ASC, lesson.position ASC, lesson.id ASC`
+
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Lesson, 0)
+
+	for rows.Next() {
+		t := model.Lesson{}
+		err = rows.Scan(
+			&t.Id,
+			&t.SectionId,
+			&t.CourseId,
+			&t.Title,
+			&t.Type,
+			&t.Content,
+			&t.Duration,
+			&t.FreePreview,
+			&t.Optional,
+			&t.Position,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (c *Course) FindSection(ctx context.Context, sectionID int) (*model.Section, error) {
+	sections, err := c.fetchSection(ctx, `id = ?`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("%w: section %d", model.ErrNotFound, sectionID)
+	}
+
+	section := sections[0]
+
+	section.Lessons, err = c.fetchLesson(ctx, `lesson.section_id = ?`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &section, nil
+}
+
+// StoreSection appends the section after the last section of its course.
+func (c *Course) StoreSection(ctx context.Context, section model.Section) (int, error) {
+	query := `SELECT COALESCE(MAX(position), 0) FROM section WHERE course_id = ? AND flag_aktif = 1`
+	query2 := `
+				INSERT INTO section
+					(course_id, title, position)
+				VALUES
+					(?, ?, ?)
+			`
+
+	var last int
+
+	err := c.DB.QueryRowContext(ctx, query, section.CourseId).Scan(&last)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := c.DB.InsertContext(ctx, query2, section.CourseId, section.Title, last+1)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (c *Course) UpdateSection(ctx context.Context, section model.Section) error {
+	query := `
+				UPDATE
+					section
+				SET
+					title = ?
+				WHERE
+					id = ? AND flag_aktif = 1
+			`
+
+	_, err := c.DB.ExecContext(ctx, query, section.Title, section.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSection soft deletes the section together with its lessons.
+func (c *Course) DeleteSection(ctx context.Context, sectionID int) error {
+	queries := []string{`
+				UPDATE
+					lesson
+				SET
+					flag_aktif = 0
+				WHERE
+					section_id = ?
+			`, `
+				UPDATE
+					section
+				SET
+					flag_aktif = 0
+				WHERE
+					id = ?
+			`}
+
+	for _, query := range queries {
+		_, err := c.DB.ExecContext(ctx, query, sectionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReorderSection numbers the sections of the course in the order of
+// sectionIDs.
+func (c *Course) ReorderSection(ctx context.Context, courseID int, sectionIDs []int) error {
+	query := `
+				UPDATE
+					section
+				SET
+					position = ?
+				WHERE
+					id = ? AND course_id = ?
+			`
+
+	for i, id := range sectionIDs {
+		_, err := c.DB.ExecContext(ctx, query, i+1, id, courseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Course) FindLesson(ctx context.Context, lessonID int) (*model.Lesson, error) {
+	lessons, err := c.fetchLesson(ctx, `lesson.id = ?`, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lessons) == 0 {
+		return nil, fmt.Errorf("%w: lesson %d", model.ErrNotFound, lessonID)
+	}
+
+	return &lessons[0], nil
+}
+
+// StoreLesson appends the lesson after the last lesson of its section.
+func (c *Course) StoreLesson(ctx context.Context, lesson model.Lesson) (int, error) {
+	query := `SELECT COALESCE(MAX(position), 0) FROM lesson WHERE section_id = ? AND flag_aktif = 1`
+	query2 := `
+				INSERT INTO lesson
+					(section_id, title, type, content, duration, free_preview, optional, position)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	var last int
+
+	err := c.DB.QueryRowContext(ctx, query, lesson.SectionId).Scan(&last)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := c.DB.InsertContext(ctx, query2,
+		lesson.SectionId, lesson.Title, lesson.Type, lesson.Content, lesson.Duration, boolInt(lesson.FreePreview), boolInt(lesson.Optional), last+1)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (c *Course) UpdateLesson(ctx context.Context, lesson model.Lesson) error {
+	query := `
+				UPDATE
+					lesson
+				SET
+					title = ?,
+					type = ?,
+					content = ?,
+					duration = ?,
+					free_preview = ?,
+					optional = ?
+				WHERE
+					id = ? AND flag_aktif = 1
+			`
+
+	_, err := c.DB.ExecContext(ctx, query,
+		lesson.Title, lesson.Type, lesson.Content, lesson.Duration, boolInt(lesson.FreePreview), boolInt(lesson.Optional), lesson.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Course) DeleteLesson(ctx context.Context, lessonID int) error {
+	query := `
+				UPDATE
+					lesson
+				SET
+					flag_aktif = 0
+				WHERE
+					id = ?
+			`
+
+	_, err := c.DB.ExecContext(ctx, query, lessonID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReorderLesson numbers the lessons of the section in the order of
+// lessonIDs.
+func (c *Course) ReorderLesson(ctx context.Context, sectionID int, lessonIDs []int) error {
+	query := `
+				UPDATE
+					lesson
+				SET
+					position = ?
+				WHERE
+					id = ? AND section_id = ?
+			`
+
+	for i, id := range lessonIDs {
+		_, err := c.DB.ExecContext(ctx, query, i+1, id, sectionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Database wraps *sql.DB so repositories can write queries once and run
+// them on every supported dialect.
+type Database struct {
+	*sql.DB
+	Dialect Dialect
+	// QueryTimeout bounds every query when set. The context of the caller,
+	// usually the request context, still applies if it ends earlier.
+	QueryTimeout time.Duration
+}
+
+func NewDatabase(db *sql.DB, dialect Dialect) *Database {
+	return &Database{
+		DB:      db,
+		Dialect: dialect,
+	}
+}
+
+// OpenDatabase opens dsn with the driver registered under the dialect name.
+func OpenDatabase(dialect Dialect, dsn string) (*Database, error) {
+	return OpenDatabaseFunc(dialect, func() string { return dsn })
+}
+
+// OpenDatabaseFunc is OpenDatabase with a dsn that is built again for every
+// new connection, so a rotated password is used without reopening the pool.
+func OpenDatabaseFunc(dialect Dialect, dsn func() string) (*Database, error) {
+	// sql.Open only looks up the driver, it does not connect.
+	opened, err := sql.Open(string(dialect), dsn())
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(dsnConnector{driver: opened.Driver(), dsn: dsn})
+	opened.Close()
+
+	if dialect == SQLite {
+		// SQLite allows a single writer, one connection avoids "database is locked".
+		db.SetMaxOpenConns(1)
+	}
+
+	return NewDatabase(db, dialect), nil
+}
+
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    func() string
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if d, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := d.OpenConnector(c.dsn())
+		if err != nil {
+			return nil, err
+		}
+
+		return connector.Connect(ctx)
+	}
+
+	return c.driver.Open(c.dsn())
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction started by WithinTransaction on this
+// database, or the database itself outside of a transaction.
+func (d *Database) conn(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{db: d}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return d.DB
+}
+
+func (d *Database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := d.queryContext(ctx)
+	defer cancel()
+
+	ctx, end := d.startQuery(ctx, query)
+
+	res, err := d.conn(ctx).ExecContext(ctx, d.Dialect.Rebind(query), d.Dialect.Args(args)...)
+	end(err)
+
+	return res, err
+}
+
+func (d *Database) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel := d.queryContext(ctx)
+	ctx, end := d.startQuery(ctx, query)
+
+	rows, err := d.conn(ctx).QueryContext(ctx, d.Dialect.Rebind(query), d.Dialect.Args(args)...)
+	end(err)
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+func (d *Database) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel := d.queryContext(ctx)
+	ctx, end := d.startQuery(ctx, query)
+
+	row := d.conn(ctx).QueryRowContext(ctx, d.Dialect.Rebind(query), d.Dialect.Args(args)...)
+	end(row.Err())
+
+	return &Row{Row: row, cancel: cancel}
+}
+
+// queryContext bounds ctx by the query timeout.
+func (d *Database) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d.QueryTimeout)
+}
+
+// Rows are read after QueryContext returns, closing them ends the query
+// timeout.
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+func (r *Rows) Close() error {
+	defer r.cancel()
+
+	return r.Rows.Close()
+}
+
+// Row is scanned after QueryRowContext returns, scanning it ends the query
+// timeout.
+type Row struct {
+	*sql.Row
+	cancel context.CancelFunc
+}
+
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+
+	return r.Row.Scan(dest...)
+}
+
+// PingRetry pings the database until it answers, at most attempts times.
+// The wait between attempts starts at backoff and doubles up to maxBackoff.
+// onError is called with every failed attempt.
+func (d *Database) PingRetry(ctx context.Context, attempts int, backoff, maxBackoff time.Duration, onError func(attempt int, err error)) error {
+	var err error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = d.PingContext(ctx); err == nil {
+			return nil
+		}
+
+		if onError != nil {
+			onError(attempt, err)
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return fmt.Errorf("database not reachable after %d attempts: %w", attempts, err)
+}
+
+// InsertContext runs an INSERT and returns the generated id column.
+// PostgreSQL has no LastInsertId, so the id is read back with RETURNING.
+func (d *Database) InsertContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if d.Dialect == PostgreSQL {
+		var id int64
+		err := d.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+
+		return id, err
+	}
+
+	res, err := d.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
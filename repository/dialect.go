@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect hides the SQL differences between the supported databases.
+// Queries are written with MySQL style ? placeholders and rebound per dialect.
+type Dialect string
+
+const (
+	MySQL      Dialect = "mysql"
+	PostgreSQL Dialect = "postgres"
+	SQLite     Dialect = "sqlite3"
+)
+
+func NewDialect(driver string) (Dialect, error) {
+	switch d := Dialect(driver); d {
+	case MySQL, PostgreSQL, SQLite:
+		return d, nil
+	case "":
+		return MySQL, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %s", driver)
+	}
+}
+
+// Rebind replaces ? placeholders with the dialect's placeholder syntax.
+// Question marks inside quoted literals are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != PostgreSQL {
+		return query
+	}
+
+	var b strings.Builder
+	var quote rune
+	n := 0
+
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Args converts query arguments the driver would store in a form the
+// dialect can't compare. SQLite keeps timestamps as text, so times are
+// written in the same UTC layout CURRENT_TIMESTAMP uses.
+func (d Dialect) Args(args []interface{}) []interface{} {
+	if d != SQLite {
+		return args
+	}
+
+	result := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch t := arg.(type) {
+		case time.Time:
+			arg = t.UTC().Format("2006-01-02 15:04:05")
+		case *time.Time:
+			if t == nil {
+				arg = nil
+			} else {
+				arg = t.UTC().Format("2006-01-02 15:04:05")
+			}
+		}
+
+		result[i] = arg
+	}
+
+	return result
+}
+
+// IsDuplicate reports whether err is the violation of a unique key.
+func (d Dialect) IsDuplicate(err error) bool {
+	switch d {
+	case MySQL:
+		var mysqlErr *mysql.MySQLError
+		// 1062 duplicate entry.
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+	case PostgreSQL:
+		var pqErr *pq.Error
+		// 23505 unique violation.
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	case SQLite:
+		var sqliteErr sqlite3.Error
+		return errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether err aborted a transaction because of a
+// deadlock or lock contention, so running it again may succeed.
+func (d Dialect) IsRetryable(err error) bool {
+	switch d {
+	case MySQL:
+		var mysqlErr *mysql.MySQLError
+		// 1213 deadlock found, 1205 lock wait timeout.
+		return errors.As(err, &mysqlErr) && (mysqlErr.Number == 1213 || mysqlErr.Number == 1205)
+	case PostgreSQL:
+		var pqErr *pq.Error
+		// 40P01 deadlock detected, 40001 serialization failure.
+		return errors.As(err, &pqErr) && (pqErr.Code == "40P01" || pqErr.Code == "40001")
+	case SQLite:
+		var sqliteErr sqlite3.Error
+		return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Enrollment struct {
+	DB *Database
+}
+
+func NewEnrollmentRepository(db *Database) EnrollmentRepository {
+	return &Enrollment{
+		DB: db,
+	}
+}
+
+func (e *Enrollment) FindOne(ctx context.Context, userID int, courseID int) (*model.Enrollment, error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				course_id,
+				created_at,
+				completed_at
+			FROM
+				enrollment
+			WHERE
+				user_id = ? AND course_id = ?`
+
+	enrollment := model.Enrollment{}
+
+	err := e.DB.QueryRowContext(ctx, query, userID, courseID).Scan(&enrollment.Id, &enrollment.UserId, &enrollment.CourseId, &enrollment.CreatedAt, &enrollment.CompletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return &enrollment, nil
+}
+
+func (e *Enrollment) Store(ctx context.Context, enrollment model.Enrollment) (int, error) {
+	query := `
+				INSERT INTO enrollment
+					(user_id, course_id, created_at)
+				VALUES
+					(?, ?, ?)
+			`
+
+	id, err := e.DB.InsertContext(ctx, query,
+		enrollment.UserId, enrollment.CourseId, enrollment.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (e *Enrollment) Delete(ctx context.Context, userID int, courseID int) error {
+	query := `
+				DELETE FROM
+					enrollment
+				WHERE
+					user_id = ? AND course_id = ?
+			`
+
+	_, err := e.DB.ExecContext(ctx, query, userID, courseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Complete sets the completion time of the enrollment unless it is set
+// already, and reports whether it did.
+func (e *Enrollment) Complete(ctx context.Context, userID int, courseID int, at time.Time) (bool, error) {
+	query := `
+				UPDATE
+					enrollment
+				SET
+					completed_at = ?
+				WHERE
+					user_id = ? AND course_id = ? AND completed_at IS NULL
+			`
+
+	res, err := e.DB.ExecContext(ctx, query, at, userID, courseID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FetchByUser returns the active courses the user is enrolled in, most
+// recent enrollment first.
+func (e *Enrollment) FetchByUser(ctx context.Context, userID int) (result []model.EnrolledCourse, err error) {
+	query := `
+			SELECT
+				course.id,
+				course.category_id,
+				course.name,
+				course.price,
+				course.enrollment_count,
+				course.rating_average,
+				course.rating_count,
+				enrollment.created_at
+			FROM
+				enrollment
+			JOIN
+				course ON course.id = enrollment.course_id
+			WHERE
+				enrollment.user_id = ? AND course.flag_aktif = 1
+			ORDER BY
+				enrollment.created_at DESC, enrollment.id DESC`
+
+	rows, err := e.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.EnrolledCourse, 0)
+
+	for rows.Next() {
+		t := model.EnrolledCourse{}
+		err = rows.Scan(
+			&t.Id,
+			&t.CategoryId,
+			&t.Name,
+			&t.Price,
+			&t.EnrollmentCount,
+			&t.RatingAverage,
+			&t.RatingCount,
+			&t.EnrolledAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+// FetchByCourse returns the active users enrolled in the course in
+// enrollment order.
+func (e *Enrollment) FetchByCourse(ctx context.Context, courseID int) (result []model.RosterEntry, err error) {
+	query := `
+			SELECT
+				users.id,
+				users.name,
+				users.email,
+				enrollment.created_at
+			FROM
+				enrollment
+			JOIN
+				users ON users.id = enrollment.user_id
+			WHERE
+				enrollment.course_id = ? AND users.flag_aktif = 1
+			ORDER BY
+				enrollment.created_at ASC, enrollment.id ASC`
+
+	rows, err := e.DB.QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.RosterEntry, 0)
+
+	for rows.Next() {
+		t := model.RosterEntry{}
+		err = rows.Scan(
+			&t.UserId,
+			&t.Name,
+			&t.Email,
+			&t.EnrolledAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/egaevan/online-learning/model"
 )
@@ -9,20 +10,163 @@ import (
 type CourseRepository interface {
 	FindOne(context.Context, int) (*model.CourseDetail, error)
 	Fetch(context.Context) ([]model.Course, error)
-	Store(context.Context, model.Course) error
+	Store(context.Context, model.Course) (int, error)
 	Update(context.Context, model.CourseUpdate, int) error
 	Delete(context.Context, int) error
 	Search(context.Context, string) ([]model.Course, error)
 	Sort(context.Context, string) ([]model.Course, error)
 	Statistic(ctx context.Context) (*model.StatisticResponse, error)
 	FetchCategory(context.Context) ([]model.CategoryDetail, error)
-	FetchPopularCategory(context.Context, int) ([]model.CategoryDetail, error)
+	FetchPopularCategory(context.Context, int, time.Time) ([]model.CategoryDetail, error)
+	RecomputeCount(context.Context) error
+	RecomputeCategoryCount(context.Context, []int) error
+	FetchCategoryNode(context.Context) ([]model.CategoryNode, error)
+	FetchByCategory(context.Context, []int) ([]model.Course, error)
+	StoreCategory(context.Context, model.Category) (int, error)
+	BumpVersion(context.Context, int) error
+	FetchCurriculum(context.Context, int) ([]model.Section, error)
+	FindSection(context.Context, int) (*model.Section, error)
+	StoreSection(context.Context, model.Section) (int, error)
+	UpdateSection(context.Context, model.Section) error
+	DeleteSection(context.Context, int) error
+	ReorderSection(context.Context, int, []int) error
+	FindLesson(context.Context, int) (*model.Lesson, error)
+	StoreLesson(context.Context, model.Lesson) (int, error)
+	UpdateLesson(context.Context, model.Lesson) error
+	DeleteLesson(context.Context, int) error
+	ReorderLesson(context.Context, int, []int) error
 }
 
 type UserRepository interface {
-	FindOne(context.Context, string, string) (model.User, error)
+	FindOne(context.Context, string) (model.User, error)
+	FindByID(context.Context, int) (model.User, error)
 	Fetch(context.Context) error
 	Store(context.Context, model.User) error
 	Update(context.Context) error
 	Delete(context.Context, int) error
 }
+
+type EnrollmentRepository interface {
+	FindOne(context.Context, int, int) (*model.Enrollment, error)
+	Store(context.Context, model.Enrollment) (int, error)
+	Delete(context.Context, int, int) error
+	Complete(context.Context, int, int, time.Time) (bool, error)
+	FetchByUser(context.Context, int) ([]model.EnrolledCourse, error)
+	FetchByCourse(context.Context, int) ([]model.RosterEntry, error)
+}
+
+type ProgressRepository interface {
+	Save(context.Context, model.LessonProgress) error
+	FetchByUser(context.Context, int) ([]model.LessonProgress, error)
+	FetchByCourse(context.Context, int, int) ([]model.LessonProgress, error)
+	Summary(context.Context, int) ([]model.CourseProgress, error)
+}
+
+type QuizRepository interface {
+	FindQuiz(context.Context, int) (*model.Quiz, error)
+	SaveQuiz(context.Context, model.Quiz) error
+	FindQuestion(context.Context, int) (*model.Question, error)
+	StoreQuestion(context.Context, model.Question) (int, error)
+	UpdateQuestion(context.Context, model.Question) error
+	DeleteQuestion(context.Context, int) error
+	ReorderQuestion(context.Context, int, []int) error
+	FindAttempt(context.Context, int) (*model.QuizAttempt, error)
+	StoreAttempt(context.Context, model.QuizAttempt) (int, error)
+	SubmitAttempt(context.Context, model.QuizAttempt) (bool, error)
+	FetchAttempt(context.Context, int, int) ([]model.QuizAttempt, error)
+}
+
+type AssignmentRepository interface {
+	FindAssignment(context.Context, int) (*model.Assignment, error)
+	SaveAssignment(context.Context, model.Assignment) error
+	FetchAssignment(context.Context, int) ([]model.Assignment, error)
+	FindSubmission(context.Context, int) (*model.Submission, error)
+	FindUserSubmission(context.Context, int, int) (*model.Submission, error)
+	StoreSubmission(context.Context, model.Submission) (int, error)
+	Resubmit(context.Context, model.Submission) (bool, error)
+	GradeSubmission(context.Context, model.Submission) error
+	FetchSubmission(context.Context, int) ([]model.Submission, error)
+	FindFile(context.Context, int) (*model.SubmissionFile, error)
+	StoreFile(context.Context, model.SubmissionFile) (int, error)
+	DeleteFile(context.Context, int) error
+}
+
+type NotificationRepository interface {
+	Store(context.Context, model.Notification) (int, error)
+	FetchByUser(context.Context, int) ([]model.Notification, error)
+	MarkRead(context.Context, int, int, time.Time) (bool, error)
+}
+
+type CertificateRepository interface {
+	FindOne(context.Context, string) (*model.Certificate, error)
+	FindByCourse(context.Context, int, int) (*model.Certificate, error)
+	FetchByUser(context.Context, int) ([]model.Certificate, error)
+	Store(context.Context, model.Certificate) error
+	Revoke(context.Context, string, time.Time, string) (bool, error)
+}
+
+type ReviewRepository interface {
+	FindOne(context.Context, int) (*model.Review, error)
+	FindByUser(context.Context, int, int) (*model.Review, error)
+	FetchByCourse(context.Context, int) ([]model.Review, error)
+	FetchFlagged(context.Context) ([]model.Review, error)
+	Store(context.Context, model.Review) (int, error)
+	Update(context.Context, model.Review) error
+	Delete(context.Context, int) error
+	SaveReply(context.Context, int, *model.ReviewReply) error
+	SetHidden(context.Context, int, bool) error
+	Vote(context.Context, int, int, time.Time) error
+	Unvote(context.Context, int, int) error
+	Flag(context.Context, model.ReviewFlag) error
+	Distribution(context.Context, int) (map[int]int, error)
+	RecomputeRating(context.Context, int) error
+}
+
+// CartRepository keeps the courses users are about to buy. Fetch skips
+// deleted courses and leaves the currency of the prices to the caller.
+type CartRepository interface {
+	Fetch(context.Context, int) ([]model.CartItem, error)
+	Add(context.Context, int, int, time.Time) error
+	Remove(context.Context, int, int) error
+	Clear(context.Context, int) error
+}
+
+type OrderRepository interface {
+	FindOne(context.Context, int) (*model.Order, error)
+	FetchByUser(context.Context, int) ([]model.Order, error)
+	Store(context.Context, model.Order) (int, error)
+	UpdateStatus(context.Context, int, string, string, time.Time) (bool, error)
+	HasPaidCourse(context.Context, int, int) (bool, error)
+}
+
+// PaymentRepository keeps the payments of orders and the webhook events
+// already handled. StoreEvent reports false for an event stored before.
+type PaymentRepository interface {
+	FindOne(context.Context, string) (*model.Payment, error)
+	FetchByOrder(context.Context, int) ([]model.Payment, error)
+	Store(context.Context, model.Payment) error
+	UpdateStatus(context.Context, string, string, time.Time) error
+	StoreEvent(context.Context, model.PaymentEvent, time.Time) (bool, error)
+}
+
+// CouponRepository keeps the coupons and their redemptions. Only active
+// coupons are found, Delete deactivates a coupon and keeps its
+// redemptions. Redeem records a redemption and counts the use unless the
+// coupon, or its uses by the user, are used up, and reports whether it did. Release takes back the
+// redemption of an order.
+type CouponRepository interface {
+	Fetch(context.Context) ([]model.Coupon, error)
+	FindOne(context.Context, int) (*model.Coupon, error)
+	FindByCode(context.Context, string) (*model.Coupon, error)
+	Store(context.Context, model.Coupon) (int, error)
+	Update(context.Context, model.Coupon) error
+	Delete(context.Context, int) error
+	Redeem(context.Context, model.CouponRedemption) (bool, error)
+	CountRedemption(context.Context, int, int) (int, error)
+	Release(context.Context, int) error
+}
+
+type StatsRepository interface {
+	PoolStats(context.Context) (model.PoolStats, error)
+	BusinessStats(context.Context) (model.BusinessStats, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egaevan/online-learning/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+func logger(ctx context.Context) *log.Entry {
+	return logging.From(ctx, "repository")
+}
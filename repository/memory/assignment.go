@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Assignment struct {
+	Data *Store
+}
+
+func NewAssignmentRepository(store *Store) repository.AssignmentRepository {
+	return &Assignment{
+		Data: store,
+	}
+}
+
+// activeAssignment returns the assignment of an active lesson in an active
+// section. The caller must hold the read lock.
+func (a *Assignment) activeAssignment(lessonID int) (*model.Assignment, bool) {
+	v, ok := a.Data.assignment[lessonID]
+	l, found := a.Data.lesson[lessonID]
+	if !ok || !found || !l.active {
+		return nil, false
+	}
+
+	s, ok := a.Data.section[l.SectionId]
+	if !ok || !s.active {
+		return nil, false
+	}
+
+	result := *v
+	result.CourseId = s.CourseId
+	result.Title = l.Title
+
+	return &result, true
+}
+
+func (a *Assignment) FindAssignment(ctx context.Context, lessonID int) (*model.Assignment, error) {
+	defer a.Data.rlock(ctx)()
+
+	v, ok := a.activeAssignment(lessonID)
+	if !ok {
+		return nil, fmt.Errorf("%w: assignment %d", model.ErrNotFound, lessonID)
+	}
+
+	return v, nil
+}
+
+func (a *Assignment) SaveAssignment(ctx context.Context, data model.Assignment) error {
+	defer a.Data.lock(ctx)()
+
+	if _, ok := a.Data.lesson[data.LessonId]; !ok {
+		return fmt.Errorf("lesson %d does not exist", data.LessonId)
+	}
+
+	data.CourseId = 0
+	data.Title = ""
+	a.Data.assignment[data.LessonId] = &data
+
+	return nil
+}
+
+func (a *Assignment) FetchAssignment(ctx context.Context, courseID int) ([]model.Assignment, error) {
+	defer a.Data.rlock(ctx)()
+
+	course := &Course{Data: a.Data}
+	result := make([]model.Assignment, 0)
+
+	for _, s := range course.activeSection(func(v *section) bool { return v.CourseId == courseID }) {
+		for _, l := range course.activeLesson(s.Id) {
+			if v, ok := a.activeAssignment(l.Id); ok {
+				result = append(result, *v)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// files returns the files of the submission in upload order. The caller
+// must hold the read lock.
+func (a *Assignment) files(submissionID int) []model.SubmissionFile {
+	result := make([]model.SubmissionFile, 0)
+
+	for _, v := range a.Data.file {
+		if v.SubmissionId == submissionID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+func (a *Assignment) FindSubmission(ctx context.Context, submissionID int) (*model.Submission, error) {
+	defer a.Data.rlock(ctx)()
+
+	v, ok := a.Data.submission[submissionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: submission %d", model.ErrNotFound, submissionID)
+	}
+
+	result := *v
+	result.Files = a.files(v.Id)
+
+	return &result, nil
+}
+
+func (a *Assignment) FindUserSubmission(ctx context.Context, userID int, lessonID int) (*model.Submission, error) {
+	defer a.Data.rlock(ctx)()
+
+	for _, v := range a.Data.submission {
+		if v.UserId == userID && v.LessonId == lessonID {
+			result := *v
+			result.Files = a.files(v.Id)
+
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: submission of user %d to assignment %d", model.ErrNotFound, userID, lessonID)
+}
+
+func (a *Assignment) StoreSubmission(ctx context.Context, data model.Submission) (int, error) {
+	defer a.Data.lock(ctx)()
+
+	if _, ok := a.Data.user[data.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	if _, ok := a.Data.assignment[data.LessonId]; !ok {
+		return 0, fmt.Errorf("assignment %d does not exist", data.LessonId)
+	}
+
+	for _, v := range a.Data.submission {
+		if v.UserId == data.UserId && v.LessonId == data.LessonId {
+			return 0, fmt.Errorf("duplicate submission of user %d to assignment %d", data.UserId, data.LessonId)
+		}
+	}
+
+	data.Id = a.Data.nextID("submission")
+	data.Files = nil
+	data.Scores = nil
+	data.RawScore, data.Penalty, data.Score = 0, 0, 0
+	data.Comment = ""
+	data.GradedAt = nil
+	data.GradedBy = 0
+
+	a.Data.submission[data.Id] = &data
+
+	return data.Id, nil
+}
+
+func (a *Assignment) Resubmit(ctx context.Context, data model.Submission) (bool, error) {
+	defer a.Data.lock(ctx)()
+
+	v, ok := a.Data.submission[data.Id]
+	if !ok || v.GradedAt != nil {
+		return false, nil
+	}
+
+	v.SubmittedAt = data.SubmittedAt
+	v.DaysLate = data.DaysLate
+
+	return true, nil
+}
+
+func (a *Assignment) GradeSubmission(ctx context.Context, data model.Submission) error {
+	defer a.Data.lock(ctx)()
+
+	if v, ok := a.Data.submission[data.Id]; ok {
+		v.Scores = data.Scores
+		v.RawScore = data.RawScore
+		v.Penalty = data.Penalty
+		v.Score = data.Score
+		v.Comment = data.Comment
+		v.GradedAt = data.GradedAt
+		v.GradedBy = data.GradedBy
+	}
+
+	return nil
+}
+
+func (a *Assignment) FetchSubmission(ctx context.Context, courseID int) ([]model.Submission, error) {
+	defer a.Data.rlock(ctx)()
+
+	result := make([]model.Submission, 0)
+
+	for _, v := range a.Data.submission {
+		if v.CourseId == courseID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].SubmittedAt.Equal(result[j].SubmittedAt) {
+			return result[i].SubmittedAt.Before(result[j].SubmittedAt)
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (a *Assignment) FindFile(ctx context.Context, fileID int) (*model.SubmissionFile, error) {
+	defer a.Data.rlock(ctx)()
+
+	v, ok := a.Data.file[fileID]
+	if !ok {
+		return nil, fmt.Errorf("%w: file %d", model.ErrNotFound, fileID)
+	}
+
+	result := *v
+
+	return &result, nil
+}
+
+func (a *Assignment) StoreFile(ctx context.Context, data model.SubmissionFile) (int, error) {
+	defer a.Data.lock(ctx)()
+
+	if _, ok := a.Data.submission[data.SubmissionId]; !ok {
+		return 0, fmt.Errorf("submission %d does not exist", data.SubmissionId)
+	}
+
+	data.Id = a.Data.nextID("file")
+	a.Data.file[data.Id] = &data
+
+	return data.Id, nil
+}
+
+func (a *Assignment) DeleteFile(ctx context.Context, submissionID int) error {
+	defer a.Data.lock(ctx)()
+
+	for id, v := range a.Data.file {
+		if v.SubmissionId == submissionID {
+			delete(a.Data.file, id)
+		}
+	}
+
+	return nil
+}
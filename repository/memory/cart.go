@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Cart struct {
+	Data *Store
+}
+
+func NewCartRepository(store *Store) repository.CartRepository {
+	return &Cart{
+		Data: store,
+	}
+}
+
+func (c *Cart) Fetch(ctx context.Context, userID int) ([]model.CartItem, error) {
+	defer c.Data.rlock(ctx)()
+
+	result := make([]model.CartItem, 0)
+
+	for courseID, at := range c.Data.cart[userID] {
+		v, ok := c.Data.course[courseID]
+		if !ok || !v.active {
+			continue
+		}
+
+		result = append(result, model.CartItem{
+			CourseId:   v.Id,
+			CourseName: v.Name,
+			CategoryId: v.CategoryId,
+			Price:      model.Money{Amount: v.Price},
+			AddedAt:    at,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].AddedAt.Equal(result[j].AddedAt) {
+			return result[i].AddedAt.Before(result[j].AddedAt)
+		}
+
+		return result[i].CourseId < result[j].CourseId
+	})
+
+	return result, nil
+}
+
+func (c *Cart) Add(ctx context.Context, userID int, courseID int, at time.Time) error {
+	defer c.Data.lock(ctx)()
+
+	if _, ok := c.Data.user[userID]; !ok {
+		return fmt.Errorf("user %d does not exist", userID)
+	}
+
+	if _, ok := c.Data.course[courseID]; !ok {
+		return fmt.Errorf("course %d does not exist", courseID)
+	}
+
+	if c.Data.cart[userID] == nil {
+		c.Data.cart[userID] = map[int]time.Time{}
+	}
+
+	if _, ok := c.Data.cart[userID][courseID]; !ok {
+		c.Data.cart[userID][courseID] = at
+	}
+
+	return nil
+}
+
+func (c *Cart) Remove(ctx context.Context, userID int, courseID int) error {
+	defer c.Data.lock(ctx)()
+
+	delete(c.Data.cart[userID], courseID)
+
+	return nil
+}
+
+func (c *Cart) Clear(ctx context.Context, userID int) error {
+	defer c.Data.lock(ctx)()
+
+	delete(c.Data.cart, userID)
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Certificate struct {
+	Data *Store
+}
+
+func NewCertificateRepository(store *Store) repository.CertificateRepository {
+	return &Certificate{
+		Data: store,
+	}
+}
+
+func (c *Certificate) FindOne(ctx context.Context, certificateID string) (*model.Certificate, error) {
+	defer c.Data.rlock(ctx)()
+
+	v, ok := c.Data.certificate[certificateID]
+	if !ok {
+		return nil, fmt.Errorf("%w: certificate %s", model.ErrNotFound, certificateID)
+	}
+
+	result := *v
+
+	return &result, nil
+}
+
+func (c *Certificate) FindByCourse(ctx context.Context, userID int, courseID int) (*model.Certificate, error) {
+	defer c.Data.rlock(ctx)()
+
+	for _, v := range c.Data.certificate {
+		if v.UserId == userID && v.CourseId == courseID {
+			result := *v
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: certificate of user %d for course %d", model.ErrNotFound, userID, courseID)
+}
+
+func (c *Certificate) FetchByUser(ctx context.Context, userID int) ([]model.Certificate, error) {
+	defer c.Data.rlock(ctx)()
+
+	result := make([]model.Certificate, 0)
+
+	for _, v := range c.Data.certificate {
+		if v.UserId == userID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].IssuedAt.Equal(result[j].IssuedAt) {
+			return result[i].IssuedAt.After(result[j].IssuedAt)
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (c *Certificate) Store(ctx context.Context, data model.Certificate) error {
+	defer c.Data.lock(ctx)()
+
+	if _, ok := c.Data.user[data.UserId]; !ok {
+		return fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	if _, ok := c.Data.course[data.CourseId]; !ok {
+		return fmt.Errorf("course %d does not exist", data.CourseId)
+	}
+
+	for id, v := range c.Data.certificate {
+		if id == data.Id || (v.UserId == data.UserId && v.CourseId == data.CourseId) {
+			return fmt.Errorf("duplicate certificate %s", data.Id)
+		}
+	}
+
+	data.RevokedAt = nil
+	data.RevokeReason = ""
+	c.Data.certificate[data.Id] = &data
+
+	return nil
+}
+
+func (c *Certificate) Revoke(ctx context.Context, certificateID string, at time.Time, reason string) (bool, error) {
+	defer c.Data.lock(ctx)()
+
+	v, ok := c.Data.certificate[certificateID]
+	if !ok || v.RevokedAt != nil {
+		return false, nil
+	}
+
+	v.RevokedAt = &at
+	v.RevokeReason = reason
+
+	return true, nil
+}
@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Coupon struct {
+	Data *Store
+}
+
+func NewCouponRepository(store *Store) repository.CouponRepository {
+	return &Coupon{
+		Data: store,
+	}
+}
+
+// copyCoupon returns a copy of the coupon sharing nothing with it.
+func copyCoupon(v model.Coupon) model.Coupon {
+	v.CourseIds = append([]int{}, v.CourseIds...)
+	v.CategoryIds = append([]int{}, v.CategoryIds...)
+
+	sort.Ints(v.CourseIds)
+	sort.Ints(v.CategoryIds)
+
+	if v.StartsAt != nil {
+		at := *v.StartsAt
+		v.StartsAt = &at
+	}
+
+	if v.EndsAt != nil {
+		at := *v.EndsAt
+		v.EndsAt = &at
+	}
+
+	return v
+}
+
+func (c *Coupon) Fetch(ctx context.Context) ([]model.Coupon, error) {
+	defer c.Data.rlock(ctx)()
+
+	result := make([]model.Coupon, 0)
+
+	for _, v := range c.Data.coupon {
+		if v.active {
+			result = append(result, copyCoupon(v.Coupon))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+
+		return result[i].Id > result[j].Id
+	})
+
+	return result, nil
+}
+
+func (c *Coupon) FindOne(ctx context.Context, couponID int) (*model.Coupon, error) {
+	defer c.Data.rlock(ctx)()
+
+	v, ok := c.Data.coupon[couponID]
+	if !ok || !v.active {
+		return nil, fmt.Errorf("%w: coupon %d", model.ErrNotFound, couponID)
+	}
+
+	result := copyCoupon(v.Coupon)
+
+	return &result, nil
+}
+
+func (c *Coupon) FindByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	defer c.Data.rlock(ctx)()
+
+	for _, v := range c.Data.coupon {
+		if v.active && v.Code == code {
+			result := copyCoupon(v.Coupon)
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: coupon %q", model.ErrNotFound, code)
+}
+
+// checkScope fails like a foreign key for courses and categories that
+// don't exist. The caller must hold the lock.
+func (c *Coupon) checkScope(data model.Coupon) error {
+	for _, courseID := range data.CourseIds {
+		if _, ok := c.Data.course[courseID]; !ok {
+			return fmt.Errorf("course %d does not exist", courseID)
+		}
+	}
+
+	for _, categoryID := range data.CategoryIds {
+		if _, ok := c.Data.category[categoryID]; !ok {
+			return fmt.Errorf("category %d does not exist", categoryID)
+		}
+	}
+
+	return nil
+}
+
+// codeInUse reports whether another active coupon has the code of data.
+func (c *Coupon) codeInUse(data model.Coupon) bool {
+	for _, v := range c.Data.coupon {
+		if v.active && v.Id != data.Id && v.Code == data.Code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Coupon) Store(ctx context.Context, data model.Coupon) (int, error) {
+	defer c.Data.lock(ctx)()
+
+	if err := c.checkScope(data); err != nil {
+		return 0, err
+	}
+
+	if c.codeInUse(data) {
+		return 0, fmt.Errorf("%w: code %s is in use", model.ErrConflict, data.Code)
+	}
+
+	data = copyCoupon(data)
+	data.Id = c.Data.nextID("coupon")
+	data.UsedCount = 0
+
+	c.Data.coupon[data.Id] = &coupon{Coupon: data, active: true}
+
+	return data.Id, nil
+}
+
+func (c *Coupon) Update(ctx context.Context, data model.Coupon) error {
+	defer c.Data.lock(ctx)()
+
+	if err := c.checkScope(data); err != nil {
+		return err
+	}
+
+	v, ok := c.Data.coupon[data.Id]
+	if !ok {
+		return nil
+	}
+
+	if v.active && c.codeInUse(data) {
+		return fmt.Errorf("%w: code %s is in use", model.ErrConflict, data.Code)
+	}
+
+	data = copyCoupon(data)
+	data.UsedCount = v.UsedCount
+	data.CreatedAt = v.CreatedAt
+
+	v.Coupon = data
+
+	return nil
+}
+
+func (c *Coupon) Delete(ctx context.Context, couponID int) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.coupon[couponID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
+
+func (c *Coupon) Redeem(ctx context.Context, data model.CouponRedemption) (bool, error) {
+	defer c.Data.lock(ctx)()
+
+	v, ok := c.Data.coupon[data.CouponId]
+	if !ok || !v.active || (v.MaxUses > 0 && v.UsedCount >= v.MaxUses) {
+		return false, nil
+	}
+
+	if _, ok := c.Data.order[data.OrderId]; !ok {
+		return false, fmt.Errorf("order %d does not exist", data.OrderId)
+	}
+
+	count := 0
+
+	for _, r := range c.Data.redemption {
+		if r.OrderId == data.OrderId {
+			return false, fmt.Errorf("duplicate redemption of order %d", data.OrderId)
+		}
+
+		if r.CouponId == data.CouponId && r.UserId == data.UserId {
+			count++
+		}
+	}
+
+	if v.MaxUsesPerUser > 0 && count >= v.MaxUsesPerUser {
+		return false, nil
+	}
+
+	v.UsedCount++
+
+	data.Id = c.Data.nextID("coupon_redemption")
+	c.Data.redemption[data.Id] = &data
+
+	return true, nil
+}
+
+func (c *Coupon) CountRedemption(ctx context.Context, couponID int, userID int) (int, error) {
+	defer c.Data.rlock(ctx)()
+
+	count := 0
+
+	for _, r := range c.Data.redemption {
+		if r.CouponId == couponID && r.UserId == userID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (c *Coupon) Release(ctx context.Context, orderID int) error {
+	defer c.Data.lock(ctx)()
+
+	for id, r := range c.Data.redemption {
+		if r.OrderId != orderID {
+			continue
+		}
+
+		if v, ok := c.Data.coupon[r.CouponId]; ok && v.UsedCount > 0 {
+			v.UsedCount--
+		}
+
+		delete(c.Data.redemption, id)
+	}
+
+	return nil
+}
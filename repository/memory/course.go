@@ -0,0 +1,362 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Course struct {
+	Data *Store
+}
+
+func NewCourseRepository(store *Store) repository.CourseRepository {
+	return &Course{
+		Data: store,
+	}
+}
+
+// activeCourse returns the active courses accepted by filter ordered by id.
+// The caller must hold the read lock.
+func (c *Course) activeCourse(filter func(*course) bool) []model.Course {
+	result := make([]model.Course, 0)
+
+	for _, v := range c.Data.course {
+		if v.active && filter(v) {
+			result = append(result, v.Course)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+func (c *Course) FindOne(ctx context.Context, courseID int) (*model.CourseDetail, error) {
+	defer c.Data.rlock(ctx)()
+
+	v, ok := c.Data.course[courseID]
+	if !ok || !v.active {
+		return nil, fmt.Errorf("%w: course %d", model.ErrNotFound, courseID)
+	}
+
+	cat, ok := c.Data.category[v.CategoryId]
+	if !ok {
+		return nil, fmt.Errorf("%w: category %d", model.ErrNotFound, v.CategoryId)
+	}
+
+	return &model.CourseDetail{
+		Id: v.Id,
+		Category: model.Category{
+			Id:   cat.Id,
+			Name: cat.Name,
+		},
+		Name:            v.Name,
+		Price:           v.Price,
+		EnrollmentCount: v.EnrollmentCount,
+		Version:         v.version,
+		Rating: model.RatingSummary{
+			Average: v.RatingAverage,
+			Count:   v.RatingCount,
+		},
+	}, nil
+}
+
+func (c *Course) Fetch(ctx context.Context) ([]model.Course, error) {
+	defer c.Data.rlock(ctx)()
+
+	return c.activeCourse(func(*course) bool { return true }), nil
+}
+
+func (c *Course) Store(ctx context.Context, data model.Course) (int, error) {
+	defer c.Data.lock(ctx)()
+
+	if _, ok := c.Data.category[data.CategoryId]; !ok {
+		return 0, fmt.Errorf("category %d does not exist", data.CategoryId)
+	}
+
+	data.Id = c.Data.nextID("course")
+	data.EnrollmentCount = 0
+	data.RatingAverage = 0
+	data.RatingCount = 0
+
+	c.Data.course[data.Id] = &course{Course: data, active: true, version: 1}
+
+	return data.Id, nil
+}
+
+func (c *Course) Update(ctx context.Context, data model.CourseUpdate, courseID int) error {
+	defer c.Data.lock(ctx)()
+
+	v, ok := c.Data.course[courseID]
+	if !ok || !v.active || v.version != data.Version {
+		return model.ErrConflict
+	}
+
+	if _, ok := c.Data.category[data.CategoryId]; !ok {
+		return fmt.Errorf("category %d does not exist", data.CategoryId)
+	}
+
+	v.CategoryId = data.CategoryId
+	v.Name = data.Name
+	v.Price = data.Price
+	v.version++
+
+	return nil
+}
+
+func (c *Course) Delete(ctx context.Context, courseID int) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.course[courseID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
+
+func (c *Course) Search(ctx context.Context, search string) ([]model.Course, error) {
+	defer c.Data.rlock(ctx)()
+
+	search = strings.ToLower(search)
+
+	return c.activeCourse(func(v *course) bool {
+		return strings.Contains(strings.ToLower(v.Name), search)
+	}), nil
+}
+
+func (c *Course) Sort(ctx context.Context, sortBy string) ([]model.Course, error) {
+	defer c.Data.rlock(ctx)()
+
+	switch sortBy {
+	case constant.SortLowPrice, constant.SortHighPrice:
+		result := c.activeCourse(func(*course) bool { return true })
+
+		sort.SliceStable(result, func(i, j int) bool {
+			if sortBy == constant.SortLowPrice {
+				return result[i].Price < result[j].Price
+			}
+
+			return result[i].Price > result[j].Price
+		})
+
+		return result, nil
+	case constant.SortFree:
+		return c.activeCourse(func(v *course) bool { return v.Price == 0 }), nil
+	case constant.SortRating:
+		result := c.activeCourse(func(*course) bool { return true })
+
+		sort.SliceStable(result, func(i, j int) bool {
+			if result[i].RatingAverage != result[j].RatingAverage {
+				return result[i].RatingAverage > result[j].RatingAverage
+			}
+
+			return result[i].RatingCount > result[j].RatingCount
+		})
+
+		return result, nil
+	default:
+		return nil, model.ErrBadParamInput
+	}
+}
+
+func (c *Course) Statistic(ctx context.Context) (*model.StatisticResponse, error) {
+	defer c.Data.rlock(ctx)()
+
+	statistic := model.StatisticResponse{}
+
+	for _, u := range c.Data.user {
+		if u.Role == 1 {
+			statistic.TotalUser++
+		}
+	}
+
+	for _, v := range c.Data.course {
+		statistic.TotalCourse++
+
+		if v.Price == 0 {
+			statistic.TotalCourseFree++
+		}
+	}
+
+	return &statistic, nil
+}
+
+func (c *Course) FetchCategory(ctx context.Context) ([]model.CategoryDetail, error) {
+	defer c.Data.rlock(ctx)()
+
+	result := make([]model.CategoryDetail, 0, len(c.Data.category))
+
+	for _, v := range c.Data.category {
+		result = append(result, model.CategoryDetail{
+			Id:              v.Id,
+			Name:            v.Name,
+			ParentId:        v.ParentId,
+			CourseCount:     v.courseCount,
+			EnrollmentCount: v.enrollmentCount,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (c *Course) FetchPopularCategory(ctx context.Context, limit int, since time.Time) ([]model.CategoryDetail, error) {
+	defer c.Data.rlock(ctx)()
+
+	recent := map[int]int{}
+	for _, e := range c.Data.enrollment {
+		v, ok := c.Data.course[e.courseID]
+		if ok && v.active && !e.createdAt.Before(since) {
+			recent[v.CategoryId]++
+		}
+	}
+
+	result := make([]model.CategoryDetail, 0, len(c.Data.category))
+
+	for _, v := range c.Data.category {
+		result = append(result, model.CategoryDetail{
+			Id:              v.Id,
+			Name:            v.Name,
+			ParentId:        v.ParentId,
+			CourseCount:     v.courseCount,
+			EnrollmentCount: recent[v.Id],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EnrollmentCount != result[j].EnrollmentCount {
+			return result[i].EnrollmentCount > result[j].EnrollmentCount
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	if limit < len(result) {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (c *Course) RecomputeCount(ctx context.Context) error {
+	defer c.Data.lock(ctx)()
+
+	enrollmentCount := map[int]int{}
+	for _, e := range c.Data.enrollment {
+		enrollmentCount[e.courseID]++
+	}
+
+	for _, v := range c.Data.course {
+		v.EnrollmentCount = enrollmentCount[v.Id]
+		v.RatingAverage, v.RatingCount = c.Data.rating(v.Id)
+	}
+
+	for _, cat := range c.Data.category {
+		cat.courseCount = 0
+		cat.enrollmentCount = 0
+	}
+
+	for _, v := range c.Data.course {
+		cat, ok := c.Data.category[v.CategoryId]
+		if !ok || !v.active {
+			continue
+		}
+
+		cat.courseCount++
+		cat.enrollmentCount += v.EnrollmentCount
+	}
+
+	return nil
+}
+
+func (c *Course) RecomputeCategoryCount(ctx context.Context, categoryIDs []int) error {
+	defer c.Data.lock(ctx)()
+
+	for _, id := range categoryIDs {
+		cat, ok := c.Data.category[id]
+		if !ok {
+			continue
+		}
+
+		cat.courseCount = 0
+		cat.enrollmentCount = 0
+
+		for _, v := range c.Data.course {
+			if v.CategoryId == id && v.active {
+				cat.courseCount++
+				cat.enrollmentCount += v.EnrollmentCount
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Course) FetchCategoryNode(ctx context.Context) ([]model.CategoryNode, error) {
+	defer c.Data.rlock(ctx)()
+
+	courseCount := map[int]int{}
+	for _, v := range c.Data.course {
+		if v.active {
+			courseCount[v.CategoryId]++
+		}
+	}
+
+	result := make([]model.CategoryNode, 0, len(c.Data.category))
+
+	for _, v := range c.Data.category {
+		result = append(result, model.CategoryNode{
+			Id:          v.Id,
+			Name:        v.Name,
+			ParentId:    v.ParentId,
+			CourseCount: courseCount[v.Id],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (c *Course) FetchByCategory(ctx context.Context, categoryIDs []int) ([]model.Course, error) {
+	defer c.Data.rlock(ctx)()
+
+	wanted := map[int]bool{}
+	for _, id := range categoryIDs {
+		wanted[id] = true
+	}
+
+	return c.activeCourse(func(v *course) bool {
+		return wanted[v.CategoryId]
+	}), nil
+}
+
+func (c *Course) StoreCategory(ctx context.Context, data model.Category) (int, error) {
+	defer c.Data.lock(ctx)()
+
+	if data.ParentId != 0 {
+		if _, ok := c.Data.category[data.ParentId]; !ok {
+			return 0, fmt.Errorf("category %d does not exist", data.ParentId)
+		}
+	}
+
+	data.Id = c.Data.nextID("category")
+
+	c.Data.category[data.Id] = &category{Category: data}
+
+	return data.Id, nil
+}
@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+func (c *Course) BumpVersion(ctx context.Context, courseID int) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.course[courseID]; ok && v.active {
+		v.version++
+	}
+
+	return nil
+}
+
+// activeSection returns the active sections accepted by filter in order,
+// without their lessons. The caller must hold the read lock.
+func (c *Course) activeSection(filter func(*section) bool) []model.Section {
+	result := make([]model.Section, 0)
+
+	for _, v := range c.Data.section {
+		if v.active && filter(v) {
+			s := v.Section
+			s.Lessons = []model.Lesson{}
+			result = append(result, s)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Position != result[j].Position {
+			return result[i].Position < result[j].Position
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+// activeLesson returns the active lessons of the active section sectionID
+// in order. The caller must hold the read lock.
+func (c *Course) activeLesson(sectionID int) []model.Lesson {
+	result := make([]model.Lesson, 0)
+
+	s, ok := c.Data.section[sectionID]
+	if !ok || !s.active {
+		return result
+	}
+
+	for _, v := range c.Data.lesson {
+		if v.active && v.SectionId == sectionID {
+			l := v.Lesson
+			l.CourseId = s.CourseId
+			result = append(result, l)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Position != result[j].Position {
+			return result[i].Position < result[j].Position
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+func (c *Course) FetchCurriculum(ctx context.Context, courseID int) ([]model.Section, error) {
+	defer c.Data.rlock(ctx)()
+
+	result := c.activeSection(func(v *section) bool { return v.CourseId == courseID })
+	for i := range result {
+		result[i].Lessons = c.activeLesson(result[i].Id)
+	}
+
+	return result, nil
+}
+
+func (c *Course) FindSection(ctx context.Context, sectionID int) (*model.Section, error) {
+	defer c.Data.rlock(ctx)()
+
+	v, ok := c.Data.section[sectionID]
+	if !ok || !v.active {
+		return nil, fmt.Errorf("%w: section %d", model.ErrNotFound, sectionID)
+	}
+
+	result := v.Section
+	result.Lessons = c.activeLesson(sectionID)
+
+	return &result, nil
+}
+
+func (c *Course) StoreSection(ctx context.Context, data model.Section) (int, error) {
+	defer c.Data.lock(ctx)()
+
+	if _, ok := c.Data.course[data.CourseId]; !ok {
+		return 0, fmt.Errorf("course %d does not exist", data.CourseId)
+	}
+
+	data.Id = c.Data.nextID("section")
+	data.Position = 1
+	data.Lessons = nil
+
+	for _, v := range c.Data.section {
+		if v.active && v.CourseId == data.CourseId && v.Position >= data.Position {
+			data.Position = v.Position + 1
+		}
+	}
+
+	c.Data.section[data.Id] = &section{Section: data, active: true}
+
+	return data.Id, nil
+}
+
+func (c *Course) UpdateSection(ctx context.Context, data model.Section) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.section[data.Id]; ok && v.active {
+		v.Title = data.Title
+	}
+
+	return nil
+}
+
+func (c *Course) DeleteSection(ctx context.Context, sectionID int) error {
+	defer c.Data.lock(ctx)()
+
+	for _, v := range c.Data.lesson {
+		if v.SectionId == sectionID {
+			v.active = false
+		}
+	}
+
+	if v, ok := c.Data.section[sectionID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
+
+func (c *Course) ReorderSection(ctx context.Context, courseID int, sectionIDs []int) error {
+	defer c.Data.lock(ctx)()
+
+	for i, id := range sectionIDs {
+		if v, ok := c.Data.section[id]; ok && v.CourseId == courseID {
+			v.Position = i + 1
+		}
+	}
+
+	return nil
+}
+
+func (c *Course) FindLesson(ctx context.Context, lessonID int) (*model.Lesson, error) {
+	defer c.Data.rlock(ctx)()
+
+	v, ok := c.Data.lesson[lessonID]
+	if !ok || !v.active {
+		return nil, fmt.Errorf("%w: lesson %d", model.ErrNotFound, lessonID)
+	}
+
+	s, ok := c.Data.section[v.SectionId]
+	if !ok || !s.active {
+		return nil, fmt.Errorf("%w: lesson %d", model.ErrNotFound, lessonID)
+	}
+
+	result := v.Lesson
+	result.CourseId = s.CourseId
+
+	return &result, nil
+}
+
+func (c *Course) StoreLesson(ctx context.Context, data model.Lesson) (int, error) {
+	defer c.Data.lock(ctx)()
+
+	if _, ok := c.Data.section[data.SectionId]; !ok {
+		return 0, fmt.Errorf("section %d does not exist", data.SectionId)
+	}
+
+	data.Id = c.Data.nextID("lesson")
+	data.Position = 1
+
+	for _, v := range c.Data.lesson {
+		if v.active && v.SectionId == data.SectionId && v.Position >= data.Position {
+			data.Position = v.Position + 1
+		}
+	}
+
+	c.Data.lesson[data.Id] = &lesson{Lesson: data, active: true}
+
+	return data.Id, nil
+}
+
+func (c *Course) UpdateLesson(ctx context.Context, data model.Lesson) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.lesson[data.Id]; ok && v.active {
+		v.Title = data.Title
+		v.Type = data.Type
+		v.Content = data.Content
+		v.Duration = data.Duration
+		v.FreePreview = data.FreePreview
+		v.Optional = data.Optional
+	}
+
+	return nil
+}
+
+func (c *Course) DeleteLesson(ctx context.Context, lessonID int) error {
+	defer c.Data.lock(ctx)()
+
+	if v, ok := c.Data.lesson[lessonID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
+
+func (c *Course) ReorderLesson(ctx context.Context, sectionID int, lessonIDs []int) error {
+	defer c.Data.lock(ctx)()
+
+	for i, id := range lessonIDs {
+		if v, ok := c.Data.lesson[id]; ok && v.SectionId == sectionID {
+			v.Position = i + 1
+		}
+	}
+
+	return nil
+}
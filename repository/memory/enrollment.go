@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Enrollment struct {
+	Data *Store
+}
+
+func NewEnrollmentRepository(store *Store) repository.EnrollmentRepository {
+	return &Enrollment{
+		Data: store,
+	}
+}
+
+// find returns the enrollment of the user in the course. The caller must
+// hold the read lock.
+func (e *Enrollment) find(userID int, courseID int) *enrollment {
+	for _, v := range e.Data.enrollment {
+		if v.userID == userID && v.courseID == courseID {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (e *Enrollment) FindOne(ctx context.Context, userID int, courseID int) (*model.Enrollment, error) {
+	defer e.Data.rlock(ctx)()
+
+	v := e.find(userID, courseID)
+	if v == nil {
+		return nil, fmt.Errorf("%w: enrollment of user %d in course %d", model.ErrNotFound, userID, courseID)
+	}
+
+	return &model.Enrollment{
+		Id:          v.id,
+		UserId:      v.userID,
+		CourseId:    v.courseID,
+		CreatedAt:   v.createdAt,
+		CompletedAt: v.completedAt,
+	}, nil
+}
+
+func (e *Enrollment) Store(ctx context.Context, data model.Enrollment) (int, error) {
+	defer e.Data.lock(ctx)()
+
+	if _, ok := e.Data.user[data.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	if _, ok := e.Data.course[data.CourseId]; !ok {
+		return 0, fmt.Errorf("course %d does not exist", data.CourseId)
+	}
+
+	if e.find(data.UserId, data.CourseId) != nil {
+		return 0, fmt.Errorf("duplicate enrollment of user %d in course %d", data.UserId, data.CourseId)
+	}
+
+	id := e.Data.nextID("enrollment")
+
+	e.Data.enrollment[id] = &enrollment{
+		id:        id,
+		userID:    data.UserId,
+		courseID:  data.CourseId,
+		createdAt: data.CreatedAt,
+	}
+
+	return id, nil
+}
+
+func (e *Enrollment) Delete(ctx context.Context, userID int, courseID int) error {
+	defer e.Data.lock(ctx)()
+
+	if v := e.find(userID, courseID); v != nil {
+		delete(e.Data.enrollment, v.id)
+	}
+
+	return nil
+}
+
+func (e *Enrollment) Complete(ctx context.Context, userID int, courseID int, at time.Time) (bool, error) {
+	defer e.Data.lock(ctx)()
+
+	v := e.find(userID, courseID)
+	if v == nil || v.completedAt != nil {
+		return false, nil
+	}
+
+	v.completedAt = &at
+
+	return true, nil
+}
+
+func (e *Enrollment) FetchByUser(ctx context.Context, userID int) ([]model.EnrolledCourse, error) {
+	defer e.Data.rlock(ctx)()
+
+	type row struct {
+		model.EnrolledCourse
+		id int
+	}
+
+	rows := []row{}
+
+	for _, v := range e.Data.enrollment {
+		c, ok := e.Data.course[v.courseID]
+		if v.userID != userID || !ok || !c.active {
+			continue
+		}
+
+		rows = append(rows, row{model.EnrolledCourse{Course: c.Course, EnrolledAt: v.createdAt}, v.id})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].EnrolledAt.Equal(rows[j].EnrolledAt) {
+			return rows[i].EnrolledAt.After(rows[j].EnrolledAt)
+		}
+
+		return rows[i].id > rows[j].id
+	})
+
+	result := make([]model.EnrolledCourse, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.EnrolledCourse)
+	}
+
+	return result, nil
+}
+
+func (e *Enrollment) FetchByCourse(ctx context.Context, courseID int) ([]model.RosterEntry, error) {
+	defer e.Data.rlock(ctx)()
+
+	type row struct {
+		model.RosterEntry
+		id int
+	}
+
+	rows := []row{}
+
+	for _, v := range e.Data.enrollment {
+		u, ok := e.Data.user[v.userID]
+		if v.courseID != courseID || !ok || !u.active {
+			continue
+		}
+
+		rows = append(rows, row{model.RosterEntry{UserId: u.Id, Name: u.Name, Email: u.Email, EnrolledAt: v.createdAt}, v.id})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].EnrolledAt.Equal(rows[j].EnrolledAt) {
+			return rows[i].EnrolledAt.Before(rows[j].EnrolledAt)
+		}
+
+		return rows[i].id < rows[j].id
+	})
+
+	result := make([]model.RosterEntry, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.RosterEntry)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,11 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/egaevan/online-learning/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.RunMemory(t)
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Notification struct {
+	Data *Store
+}
+
+func NewNotificationRepository(store *Store) repository.NotificationRepository {
+	return &Notification{
+		Data: store,
+	}
+}
+
+func (n *Notification) Store(ctx context.Context, data model.Notification) (int, error) {
+	defer n.Data.lock(ctx)()
+
+	if _, ok := n.Data.user[data.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	data.Id = n.Data.nextID("notification")
+	data.ReadAt = nil
+	n.Data.notification[data.Id] = &data
+
+	return data.Id, nil
+}
+
+func (n *Notification) FetchByUser(ctx context.Context, userID int) ([]model.Notification, error) {
+	defer n.Data.rlock(ctx)()
+
+	result := make([]model.Notification, 0)
+
+	for _, v := range n.Data.notification {
+		if v.UserId == userID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+
+		return result[i].Id > result[j].Id
+	})
+
+	return result, nil
+}
+
+func (n *Notification) MarkRead(ctx context.Context, userID int, notificationID int, at time.Time) (bool, error) {
+	defer n.Data.lock(ctx)()
+
+	v, ok := n.Data.notification[notificationID]
+	if !ok || v.UserId != userID || v.ReadAt != nil {
+		return false, nil
+	}
+
+	v.ReadAt = &at
+
+	return true, nil
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Order struct {
+	Data *Store
+}
+
+func NewOrderRepository(store *Store) repository.OrderRepository {
+	return &Order{
+		Data: store,
+	}
+}
+
+// row copies the order so callers can't change the stored one.
+func (o *Order) row(v *model.Order) model.Order {
+	result := *v
+	result.Items = append([]model.OrderItem{}, v.Items...)
+
+	if v.PaidAt != nil {
+		paidAt := *v.PaidAt
+		result.PaidAt = &paidAt
+	}
+
+	return result
+}
+
+func (o *Order) FindOne(ctx context.Context, orderID int) (*model.Order, error) {
+	defer o.Data.rlock(ctx)()
+
+	v, ok := o.Data.order[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: order %d", model.ErrNotFound, orderID)
+	}
+
+	result := o.row(v)
+
+	return &result, nil
+}
+
+func (o *Order) FetchByUser(ctx context.Context, userID int) ([]model.Order, error) {
+	defer o.Data.rlock(ctx)()
+
+	result := make([]model.Order, 0)
+
+	for _, v := range o.Data.order {
+		if v.UserId == userID {
+			result = append(result, o.row(v))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+
+		return result[i].Id > result[j].Id
+	})
+
+	return result, nil
+}
+
+func (o *Order) Store(ctx context.Context, data model.Order) (int, error) {
+	defer o.Data.lock(ctx)()
+
+	if _, ok := o.Data.user[data.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	data.Id = o.Data.nextID("orders")
+	data.PaidAt = nil
+	data.Items = append([]model.OrderItem{}, data.Items...)
+
+	for i := range data.Items {
+		if _, ok := o.Data.course[data.Items[i].CourseId]; !ok {
+			return 0, fmt.Errorf("course %d does not exist", data.Items[i].CourseId)
+		}
+
+		data.Items[i].Id = o.Data.nextID("order_item")
+	}
+
+	o.Data.order[data.Id] = &data
+
+	return data.Id, nil
+}
+
+func (o *Order) UpdateStatus(ctx context.Context, orderID int, from string, to string, at time.Time) (bool, error) {
+	defer o.Data.lock(ctx)()
+
+	v, ok := o.Data.order[orderID]
+	if !ok || v.Status != from {
+		return false, nil
+	}
+
+	v.Status = to
+	v.UpdatedAt = at
+
+	if to == constant.OrderPaid {
+		paidAt := at
+		v.PaidAt = &paidAt
+	}
+
+	return true, nil
+}
+
+func (o *Order) HasPaidCourse(ctx context.Context, userID int, courseID int) (bool, error) {
+	defer o.Data.rlock(ctx)()
+
+	for _, v := range o.Data.order {
+		if v.UserId != userID || v.Status != constant.OrderPaid {
+			continue
+		}
+
+		for _, item := range v.Items {
+			if item.CourseId == courseID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
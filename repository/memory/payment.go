@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Payment struct {
+	Data *Store
+}
+
+func NewPaymentRepository(store *Store) repository.PaymentRepository {
+	return &Payment{
+		Data: store,
+	}
+}
+
+func (p *Payment) FindOne(ctx context.Context, paymentID string) (*model.Payment, error) {
+	defer p.Data.rlock(ctx)()
+
+	v, ok := p.Data.payment[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: payment %s", model.ErrNotFound, paymentID)
+	}
+
+	result := *v
+
+	return &result, nil
+}
+
+func (p *Payment) FetchByOrder(ctx context.Context, orderID int) ([]model.Payment, error) {
+	defer p.Data.rlock(ctx)()
+
+	result := make([]model.Payment, 0)
+
+	for _, v := range p.Data.payment {
+		if v.OrderId == orderID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (p *Payment) Store(ctx context.Context, data model.Payment) error {
+	defer p.Data.lock(ctx)()
+
+	if _, ok := p.Data.order[data.OrderId]; !ok {
+		return fmt.Errorf("order %d does not exist", data.OrderId)
+	}
+
+	if _, ok := p.Data.payment[data.Id]; ok {
+		return fmt.Errorf("duplicate payment %s", data.Id)
+	}
+
+	p.Data.payment[data.Id] = &data
+
+	return nil
+}
+
+func (p *Payment) UpdateStatus(ctx context.Context, paymentID string, status string, at time.Time) error {
+	defer p.Data.lock(ctx)()
+
+	if v, ok := p.Data.payment[paymentID]; ok {
+		v.Status = status
+		v.UpdatedAt = at
+	}
+
+	return nil
+}
+
+func (p *Payment) StoreEvent(ctx context.Context, event model.PaymentEvent, receivedAt time.Time) (bool, error) {
+	defer p.Data.lock(ctx)()
+
+	if _, ok := p.Data.payment[event.PaymentId]; !ok {
+		return false, fmt.Errorf("payment %s does not exist", event.PaymentId)
+	}
+
+	if _, ok := p.Data.paymentEvent[event.Id]; ok {
+		return false, nil
+	}
+
+	p.Data.paymentEvent[event.Id] = event
+
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Progress struct {
+	Data *Store
+}
+
+func NewProgressRepository(store *Store) repository.ProgressRepository {
+	return &Progress{
+		Data: store,
+	}
+}
+
+func (p *Progress) Save(ctx context.Context, data model.LessonProgress) error {
+	defer p.Data.lock(ctx)()
+
+	for _, v := range p.Data.progress {
+		if v.UserId == data.UserId && v.LessonId == data.LessonId {
+			v.Position = data.Position
+			v.UpdatedAt = data.UpdatedAt
+			if v.CompletedAt == nil {
+				v.CompletedAt = data.CompletedAt
+			}
+
+			return nil
+		}
+	}
+
+	p.Data.progress[p.Data.nextID("progress")] = &data
+
+	return nil
+}
+
+// activeLesson reports whether the lesson and its section are active. The
+// caller must hold the read lock.
+func (p *Progress) activeLesson(lessonID int) (*lesson, bool) {
+	l, ok := p.Data.lesson[lessonID]
+	if !ok || !l.active {
+		return nil, false
+	}
+
+	s, ok := p.Data.section[l.SectionId]
+
+	return l, ok && s.active
+}
+
+// fetch returns the progress in active lessons accepted by filter, most
+// recently updated first. The caller must hold the read lock.
+func (p *Progress) fetch(filter func(*model.LessonProgress) bool) []model.LessonProgress {
+	type row struct {
+		model.LessonProgress
+		id int
+	}
+
+	rows := []row{}
+
+	for id, v := range p.Data.progress {
+		if _, ok := p.activeLesson(v.LessonId); ok && filter(v) {
+			rows = append(rows, row{*v, id})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].UpdatedAt.Equal(rows[j].UpdatedAt) {
+			return rows[i].UpdatedAt.After(rows[j].UpdatedAt)
+		}
+
+		return rows[i].id > rows[j].id
+	})
+
+	result := make([]model.LessonProgress, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.LessonProgress)
+	}
+
+	return result
+}
+
+func (p *Progress) FetchByUser(ctx context.Context, userID int) ([]model.LessonProgress, error) {
+	defer p.Data.rlock(ctx)()
+
+	return p.fetch(func(v *model.LessonProgress) bool {
+		return v.UserId == userID
+	}), nil
+}
+
+func (p *Progress) FetchByCourse(ctx context.Context, userID int, courseID int) ([]model.LessonProgress, error) {
+	defer p.Data.rlock(ctx)()
+
+	return p.fetch(func(v *model.LessonProgress) bool {
+		return v.UserId == userID && v.CourseId == courseID
+	}), nil
+}
+
+func (p *Progress) Summary(ctx context.Context, userID int) ([]model.CourseProgress, error) {
+	defer p.Data.rlock(ctx)()
+
+	required := map[int]int{}
+	for id, l := range p.Data.lesson {
+		if _, ok := p.activeLesson(id); ok && !l.Optional {
+			required[p.Data.section[l.SectionId].CourseId]++
+		}
+	}
+
+	completed := map[int]int{}
+	for _, v := range p.Data.progress {
+		if l, ok := p.activeLesson(v.LessonId); ok && !l.Optional && v.UserId == userID && v.CompletedAt != nil {
+			completed[p.Data.section[l.SectionId].CourseId]++
+		}
+	}
+
+	result := make([]model.CourseProgress, 0)
+
+	for _, e := range p.Data.enrollment {
+		c, ok := p.Data.course[e.courseID]
+		if e.userID != userID || !ok || !c.active {
+			continue
+		}
+
+		result = append(result, model.CourseProgress{
+			CourseId:         e.courseID,
+			CompletedAt:      e.completedAt,
+			RequiredLessons:  required[e.courseID],
+			CompletedLessons: completed[e.courseID],
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CourseId < result[j].CourseId
+	})
+
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Quiz struct {
+	Data *Store
+}
+
+func NewQuizRepository(store *Store) repository.QuizRepository {
+	return &Quiz{
+		Data: store,
+	}
+}
+
+// activeQuestion returns the active questions of the quiz in order. The
+// caller must hold the read lock.
+func (q *Quiz) activeQuestion(lessonID int) []model.Question {
+	result := make([]model.Question, 0)
+
+	for _, v := range q.Data.question {
+		if v.active && v.LessonId == lessonID {
+			result = append(result, v.Question)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Position != result[j].Position {
+			return result[i].Position < result[j].Position
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+func (q *Quiz) FindQuiz(ctx context.Context, lessonID int) (*model.Quiz, error) {
+	defer q.Data.rlock(ctx)()
+
+	v, ok := q.Data.quiz[lessonID]
+	l, found := q.Data.lesson[lessonID]
+	if !ok || !found || !l.active {
+		return nil, fmt.Errorf("%w: quiz %d", model.ErrNotFound, lessonID)
+	}
+
+	s, ok := q.Data.section[l.SectionId]
+	if !ok || !s.active {
+		return nil, fmt.Errorf("%w: quiz %d", model.ErrNotFound, lessonID)
+	}
+
+	result := *v
+	result.CourseId = s.CourseId
+	result.Questions = q.activeQuestion(lessonID)
+
+	return &result, nil
+}
+
+func (q *Quiz) SaveQuiz(ctx context.Context, data model.Quiz) error {
+	defer q.Data.lock(ctx)()
+
+	if _, ok := q.Data.lesson[data.LessonId]; !ok {
+		return fmt.Errorf("lesson %d does not exist", data.LessonId)
+	}
+
+	data.CourseId = 0
+	data.Questions = nil
+	q.Data.quiz[data.LessonId] = &data
+
+	return nil
+}
+
+func (q *Quiz) FindQuestion(ctx context.Context, questionID int) (*model.Question, error) {
+	defer q.Data.rlock(ctx)()
+
+	v, ok := q.Data.question[questionID]
+	if !ok || !v.active {
+		return nil, fmt.Errorf("%w: question %d", model.ErrNotFound, questionID)
+	}
+
+	result := v.Question
+
+	return &result, nil
+}
+
+func (q *Quiz) StoreQuestion(ctx context.Context, data model.Question) (int, error) {
+	defer q.Data.lock(ctx)()
+
+	if _, ok := q.Data.quiz[data.LessonId]; !ok {
+		return 0, fmt.Errorf("quiz %d does not exist", data.LessonId)
+	}
+
+	data.Id = q.Data.nextID("question")
+	data.Position = 1
+
+	for _, v := range q.Data.question {
+		if v.active && v.LessonId == data.LessonId && v.Position >= data.Position {
+			data.Position = v.Position + 1
+		}
+	}
+
+	q.Data.question[data.Id] = &question{Question: data, active: true}
+
+	return data.Id, nil
+}
+
+func (q *Quiz) UpdateQuestion(ctx context.Context, data model.Question) error {
+	defer q.Data.lock(ctx)()
+
+	if v, ok := q.Data.question[data.Id]; ok && v.active {
+		v.Type = data.Type
+		v.Prompt = data.Prompt
+		v.Options = data.Options
+		v.Answer = data.Answer
+		v.Points = data.Points
+		v.Feedback = data.Feedback
+	}
+
+	return nil
+}
+
+func (q *Quiz) DeleteQuestion(ctx context.Context, questionID int) error {
+	defer q.Data.lock(ctx)()
+
+	if v, ok := q.Data.question[questionID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
+
+func (q *Quiz) ReorderQuestion(ctx context.Context, lessonID int, questionIDs []int) error {
+	defer q.Data.lock(ctx)()
+
+	for i, id := range questionIDs {
+		if v, ok := q.Data.question[id]; ok && v.LessonId == lessonID {
+			v.Position = i + 1
+		}
+	}
+
+	return nil
+}
+
+func (q *Quiz) FindAttempt(ctx context.Context, attemptID int) (*model.QuizAttempt, error) {
+	defer q.Data.rlock(ctx)()
+
+	v, ok := q.Data.attempt[attemptID]
+	if !ok {
+		return nil, fmt.Errorf("%w: attempt %d", model.ErrNotFound, attemptID)
+	}
+
+	result := *v
+
+	return &result, nil
+}
+
+func (q *Quiz) StoreAttempt(ctx context.Context, data model.QuizAttempt) (int, error) {
+	defer q.Data.lock(ctx)()
+
+	if _, ok := q.Data.quiz[data.LessonId]; !ok {
+		return 0, fmt.Errorf("quiz %d does not exist", data.LessonId)
+	}
+
+	for _, v := range q.Data.attempt {
+		if v.UserId == data.UserId && v.LessonId == data.LessonId && v.Number == data.Number {
+			return 0, fmt.Errorf("%w: attempt %d of user %d at quiz %d exists", model.ErrConflict, data.Number, data.UserId, data.LessonId)
+		}
+	}
+
+	data.Id = q.Data.nextID("attempt")
+	data.SubmittedAt = nil
+	data.Responses = nil
+	data.Results = nil
+	data.Questions = nil
+
+	q.Data.attempt[data.Id] = &data
+
+	return data.Id, nil
+}
+
+func (q *Quiz) SubmitAttempt(ctx context.Context, data model.QuizAttempt) (bool, error) {
+	defer q.Data.lock(ctx)()
+
+	v, ok := q.Data.attempt[data.Id]
+	if !ok || v.SubmittedAt != nil {
+		return false, nil
+	}
+
+	v.SubmittedAt = data.SubmittedAt
+	v.Score = data.Score
+	v.MaxScore = data.MaxScore
+	v.Percentage = data.Percentage
+	v.Passed = data.Passed
+	v.Responses = data.Responses
+	v.Results = data.Results
+
+	return true, nil
+}
+
+func (q *Quiz) FetchAttempt(ctx context.Context, userID int, lessonID int) ([]model.QuizAttempt, error) {
+	defer q.Data.rlock(ctx)()
+
+	result := make([]model.QuizAttempt, 0)
+
+	for _, v := range q.Data.attempt {
+		if v.UserId == userID && v.LessonId == lessonID {
+			result = append(result, *v)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartedAt.Equal(result[j].StartedAt) {
+			return result[i].StartedAt.Before(result[j].StartedAt)
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Review struct {
+	Data *Store
+}
+
+func NewReviewRepository(store *Store) repository.ReviewRepository {
+	return &Review{
+		Data: store,
+	}
+}
+
+// row returns the review with the name of its author and its vote and
+// flag counts. The caller must hold the read lock.
+func (r *Review) row(v *review) model.Review {
+	result := v.Review
+	result.HelpfulCount = len(v.votes)
+	result.FlagCount = len(v.flags)
+
+	if u, ok := r.Data.user[v.UserId]; ok {
+		result.UserName = u.Name
+	}
+
+	if v.Reply != nil {
+		reply := *v.Reply
+		result.Reply = &reply
+	}
+
+	return result
+}
+
+// filter returns the reviews accepted by keep, unordered. The caller must
+// hold the read lock.
+func (r *Review) filter(keep func(*review) bool) []model.Review {
+	result := make([]model.Review, 0)
+
+	for _, v := range r.Data.review {
+		if keep(v) {
+			result = append(result, r.row(v))
+		}
+	}
+
+	return result
+}
+
+func (r *Review) FindOne(ctx context.Context, reviewID int) (*model.Review, error) {
+	defer r.Data.rlock(ctx)()
+
+	v, ok := r.Data.review[reviewID]
+	if !ok {
+		return nil, fmt.Errorf("%w: review %d", model.ErrNotFound, reviewID)
+	}
+
+	result := r.row(v)
+
+	return &result, nil
+}
+
+func (r *Review) FindByUser(ctx context.Context, userID int, courseID int) (*model.Review, error) {
+	defer r.Data.rlock(ctx)()
+
+	for _, v := range r.Data.review {
+		if v.UserId == userID && v.CourseId == courseID {
+			result := r.row(v)
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: review of user %d for course %d", model.ErrNotFound, userID, courseID)
+}
+
+func (r *Review) FetchByCourse(ctx context.Context, courseID int) ([]model.Review, error) {
+	defer r.Data.rlock(ctx)()
+
+	result := r.filter(func(v *review) bool { return v.CourseId == courseID })
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+
+		return result[i].Id > result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *Review) FetchFlagged(ctx context.Context) ([]model.Review, error) {
+	defer r.Data.rlock(ctx)()
+
+	result := r.filter(func(v *review) bool { return len(v.flags) > 0 })
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FlagCount != result[j].FlagCount {
+			return result[i].FlagCount > result[j].FlagCount
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *Review) Store(ctx context.Context, data model.Review) (int, error) {
+	defer r.Data.lock(ctx)()
+
+	if _, ok := r.Data.user[data.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", data.UserId)
+	}
+
+	if _, ok := r.Data.course[data.CourseId]; !ok {
+		return 0, fmt.Errorf("course %d does not exist", data.CourseId)
+	}
+
+	for _, v := range r.Data.review {
+		if v.UserId == data.UserId && v.CourseId == data.CourseId {
+			return 0, fmt.Errorf("duplicate review of user %d for course %d", data.UserId, data.CourseId)
+		}
+	}
+
+	data.Id = r.Data.nextID("review")
+	data.Hidden = false
+	data.Reply = nil
+	data.UserName = ""
+	data.HelpfulCount = 0
+	data.FlagCount = 0
+
+	r.Data.review[data.Id] = &review{Review: data, votes: map[int]time.Time{}, flags: map[int]model.ReviewFlag{}}
+
+	return data.Id, nil
+}
+
+func (r *Review) Update(ctx context.Context, data model.Review) error {
+	defer r.Data.lock(ctx)()
+
+	if v, ok := r.Data.review[data.Id]; ok {
+		v.Rating = data.Rating
+		v.Body = data.Body
+		v.UpdatedAt = data.UpdatedAt
+	}
+
+	return nil
+}
+
+func (r *Review) Delete(ctx context.Context, reviewID int) error {
+	defer r.Data.lock(ctx)()
+
+	delete(r.Data.review, reviewID)
+
+	return nil
+}
+
+func (r *Review) SaveReply(ctx context.Context, reviewID int, reply *model.ReviewReply) error {
+	defer r.Data.lock(ctx)()
+
+	v, ok := r.Data.review[reviewID]
+	if !ok {
+		return nil
+	}
+
+	if reply == nil {
+		v.Reply = nil
+		return nil
+	}
+
+	if _, ok := r.Data.user[reply.RepliedBy]; !ok {
+		return fmt.Errorf("user %d does not exist", reply.RepliedBy)
+	}
+
+	saved := *reply
+	v.Reply = &saved
+
+	return nil
+}
+
+func (r *Review) SetHidden(ctx context.Context, reviewID int, hidden bool) error {
+	defer r.Data.lock(ctx)()
+
+	if v, ok := r.Data.review[reviewID]; ok {
+		v.Hidden = hidden
+		v.flags = map[int]model.ReviewFlag{}
+	}
+
+	return nil
+}
+
+func (r *Review) Vote(ctx context.Context, reviewID int, userID int, at time.Time) error {
+	defer r.Data.lock(ctx)()
+
+	v, ok := r.Data.review[reviewID]
+	if !ok {
+		return fmt.Errorf("review %d does not exist", reviewID)
+	}
+
+	if _, ok := r.Data.user[userID]; !ok {
+		return fmt.Errorf("user %d does not exist", userID)
+	}
+
+	if _, ok := v.votes[userID]; !ok {
+		v.votes[userID] = at
+	}
+
+	return nil
+}
+
+func (r *Review) Unvote(ctx context.Context, reviewID int, userID int) error {
+	defer r.Data.lock(ctx)()
+
+	if v, ok := r.Data.review[reviewID]; ok {
+		delete(v.votes, userID)
+	}
+
+	return nil
+}
+
+func (r *Review) Flag(ctx context.Context, flag model.ReviewFlag) error {
+	defer r.Data.lock(ctx)()
+
+	v, ok := r.Data.review[flag.ReviewId]
+	if !ok {
+		return fmt.Errorf("review %d does not exist", flag.ReviewId)
+	}
+
+	if _, ok := r.Data.user[flag.UserId]; !ok {
+		return fmt.Errorf("user %d does not exist", flag.UserId)
+	}
+
+	v.flags[flag.UserId] = flag
+
+	return nil
+}
+
+func (r *Review) Distribution(ctx context.Context, courseID int) (map[int]int, error) {
+	defer r.Data.rlock(ctx)()
+
+	result := map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+
+	for _, v := range r.Data.review {
+		if v.CourseId == courseID && !v.Hidden {
+			result[v.Rating]++
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Review) RecomputeRating(ctx context.Context, courseID int) error {
+	defer r.Data.lock(ctx)()
+
+	if v, ok := r.Data.course[courseID]; ok {
+		v.RatingAverage, v.RatingCount = r.Data.rating(courseID)
+	}
+
+	return nil
+}
+
+// rating returns the average rating, rounded to two decimals, and the
+// number of the visible reviews of the course. The caller must hold the
+// read lock.
+func (s *Store) rating(courseID int) (float64, int) {
+	total, count := 0, 0
+
+	for _, v := range s.review {
+		if v.CourseId == courseID && !v.Hidden {
+			total += v.Rating
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, 0
+	}
+
+	return math.Round(float64(total)/float64(count)*100) / 100, count
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Stats struct {
+	Data *Store
+}
+
+func NewStatsRepository(store *Store) repository.StatsRepository {
+	return &Stats{
+		Data: store,
+	}
+}
+
+// PoolStats reports an empty pool, the memory storage has no connections.
+func (s *Stats) PoolStats(context.Context) (model.PoolStats, error) {
+	return model.PoolStats{}, nil
+}
+
+func (s *Stats) BusinessStats(ctx context.Context) (model.BusinessStats, error) {
+	defer s.Data.rlock(ctx)()
+
+	stats := model.BusinessStats{}
+
+	for _, v := range s.Data.course {
+		if v.active {
+			stats.ActiveCourses++
+		}
+	}
+
+	for _, v := range s.Data.user {
+		if v.active {
+			stats.RegisteredUsers++
+		}
+	}
+
+	return stats, nil
+}
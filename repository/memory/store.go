@@ -0,0 +1,325 @@
+// Package memory implements the repository interfaces on top of in-process
+// maps. It behaves like the SQL repositories and is meant for tests and the
+// --storage=memory demo mode.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type category struct {
+	model.Category
+	courseCount     int
+	enrollmentCount int
+}
+
+type course struct {
+	model.Course
+	active  bool
+	version int
+}
+
+type user struct {
+	model.User
+	active bool
+}
+
+type section struct {
+	model.Section
+	active bool
+}
+
+type lesson struct {
+	model.Lesson
+	active bool
+}
+
+type question struct {
+	model.Question
+	active bool
+}
+
+type enrollment struct {
+	id          int
+	userID      int
+	courseID    int
+	createdAt   time.Time
+	completedAt *time.Time
+}
+
+type coupon struct {
+	model.Coupon
+	active bool
+}
+
+// review keeps the votes and flags of a review by user.
+type review struct {
+	model.Review
+	votes map[int]time.Time
+	flags map[int]model.ReviewFlag
+}
+
+// Store holds every table. Repositories created from the same Store share
+// their data, like repositories sharing one database.
+type Store struct {
+	mu sync.RWMutex
+
+	category     map[int]*category
+	course       map[int]*course
+	user         map[int]*user
+	enrollment   map[int]*enrollment
+	section      map[int]*section
+	lesson       map[int]*lesson
+	progress     map[int]*model.LessonProgress
+	quiz         map[int]*model.Quiz
+	question     map[int]*question
+	attempt      map[int]*model.QuizAttempt
+	assignment   map[int]*model.Assignment
+	submission   map[int]*model.Submission
+	file         map[int]*model.SubmissionFile
+	notification map[int]*model.Notification
+	certificate  map[string]*model.Certificate
+	review       map[int]*review
+	// cart holds the time each course was added by user and course.
+	cart  map[int]map[int]time.Time
+	order map[int]*model.Order
+	// paymentEvent holds the ids of the handled webhook events.
+	payment      map[string]*model.Payment
+	paymentEvent map[string]model.PaymentEvent
+	coupon       map[int]*coupon
+	redemption   map[int]*model.CouponRedemption
+
+	lastID map[string]int
+}
+
+func NewStore() *Store {
+	return &Store{
+		category:     map[int]*category{},
+		course:       map[int]*course{},
+		user:         map[int]*user{},
+		enrollment:   map[int]*enrollment{},
+		section:      map[int]*section{},
+		lesson:       map[int]*lesson{},
+		progress:     map[int]*model.LessonProgress{},
+		quiz:         map[int]*model.Quiz{},
+		question:     map[int]*question{},
+		attempt:      map[int]*model.QuizAttempt{},
+		assignment:   map[int]*model.Assignment{},
+		submission:   map[int]*model.Submission{},
+		file:         map[int]*model.SubmissionFile{},
+		notification: map[int]*model.Notification{},
+		certificate:  map[string]*model.Certificate{},
+		review:       map[int]*review{},
+		cart:         map[int]map[int]time.Time{},
+		order:        map[int]*model.Order{},
+		payment:      map[string]*model.Payment{},
+		paymentEvent: map[string]model.PaymentEvent{},
+		coupon:       map[int]*coupon{},
+		redemption:   map[int]*model.CouponRedemption{},
+		lastID:       map[string]int{},
+	}
+}
+
+// nextID works like an auto increment column. The caller must hold the
+// write lock.
+func (s *Store) nextID(table string) int {
+	s.lastID[table]++
+
+	return s.lastID[table]
+}
+
+func (s *Store) inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{store: s}).(bool)
+
+	return ok
+}
+
+// lock takes the write lock unless ctx belongs to a transaction on this
+// store, which already holds it. The returned func releases the lock.
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTransaction(ctx) {
+		return func() {}
+	}
+
+	s.mu.Lock()
+
+	return s.mu.Unlock
+}
+
+// rlock is lock for readers.
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.inTransaction(ctx) {
+		return func() {}
+	}
+
+	s.mu.RLock()
+
+	return s.mu.RUnlock
+}
+
+// clone deep copies every table. The caller must hold the lock.
+func (s *Store) clone() *Store {
+	c := NewStore()
+
+	for id, v := range s.category {
+		row := *v
+		c.category[id] = &row
+	}
+
+	for id, v := range s.course {
+		row := *v
+		c.course[id] = &row
+	}
+
+	for id, v := range s.user {
+		row := *v
+		c.user[id] = &row
+	}
+
+	for id, v := range s.enrollment {
+		row := *v
+		c.enrollment[id] = &row
+	}
+
+	for id, v := range s.section {
+		row := *v
+		c.section[id] = &row
+	}
+
+	for id, v := range s.lesson {
+		row := *v
+		c.lesson[id] = &row
+	}
+
+	for id, v := range s.progress {
+		row := *v
+		c.progress[id] = &row
+	}
+
+	for id, v := range s.quiz {
+		row := *v
+		c.quiz[id] = &row
+	}
+
+	for id, v := range s.question {
+		row := *v
+		c.question[id] = &row
+	}
+
+	for id, v := range s.attempt {
+		row := *v
+		c.attempt[id] = &row
+	}
+
+	for id, v := range s.assignment {
+		row := *v
+		c.assignment[id] = &row
+	}
+
+	for id, v := range s.submission {
+		row := *v
+		c.submission[id] = &row
+	}
+
+	for id, v := range s.file {
+		row := *v
+		c.file[id] = &row
+	}
+
+	for id, v := range s.notification {
+		row := *v
+		c.notification[id] = &row
+	}
+
+	for id, v := range s.certificate {
+		row := *v
+		c.certificate[id] = &row
+	}
+
+	for id, v := range s.review {
+		row := *v
+		row.votes = map[int]time.Time{}
+		for user, at := range v.votes {
+			row.votes[user] = at
+		}
+
+		row.flags = map[int]model.ReviewFlag{}
+		for user, flag := range v.flags {
+			row.flags[user] = flag
+		}
+
+		c.review[id] = &row
+	}
+
+	for user, items := range s.cart {
+		c.cart[user] = map[int]time.Time{}
+		for course, at := range items {
+			c.cart[user][course] = at
+		}
+	}
+
+	for id, v := range s.order {
+		row := *v
+		row.Items = append([]model.OrderItem(nil), v.Items...)
+		c.order[id] = &row
+	}
+
+	for id, v := range s.payment {
+		row := *v
+		c.payment[id] = &row
+	}
+
+	for id, v := range s.paymentEvent {
+		c.paymentEvent[id] = v
+	}
+
+	for id, v := range s.coupon {
+		row := *v
+		row.CourseIds = append([]int(nil), v.CourseIds...)
+		row.CategoryIds = append([]int(nil), v.CategoryIds...)
+		c.coupon[id] = &row
+	}
+
+	for id, v := range s.redemption {
+		row := *v
+		c.redemption[id] = &row
+	}
+
+	for table, id := range s.lastID {
+		c.lastID[table] = id
+	}
+
+	return c
+}
+
+// restore replaces every table with the ones from snapshot. The caller must
+// hold the write lock.
+func (s *Store) restore(snapshot *Store) {
+	s.category = snapshot.category
+	s.course = snapshot.course
+	s.user = snapshot.user
+	s.enrollment = snapshot.enrollment
+	s.section = snapshot.section
+	s.lesson = snapshot.lesson
+	s.progress = snapshot.progress
+	s.quiz = snapshot.quiz
+	s.question = snapshot.question
+	s.attempt = snapshot.attempt
+	s.assignment = snapshot.assignment
+	s.submission = snapshot.submission
+	s.file = snapshot.file
+	s.notification = snapshot.notification
+	s.certificate = snapshot.certificate
+	s.review = snapshot.review
+	s.cart = snapshot.cart
+	s.order = snapshot.order
+	s.payment = snapshot.payment
+	s.paymentEvent = snapshot.paymentEvent
+	s.coupon = snapshot.coupon
+	s.redemption = snapshot.redemption
+	s.lastID = snapshot.lastID
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/egaevan/online-learning/repository"
+)
+
+type txKey struct {
+	store *Store
+}
+
+// Transaction holds the store's write lock for the whole unit of work, so
+// transactions are serialized, and restores a snapshot when it fails.
+type Transaction struct {
+	Data *Store
+}
+
+func NewTransactor(store *Store) repository.Transactor {
+	return &Transaction{
+		Data: store,
+	}
+}
+
+func (t *Transaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if t.Data.inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	t.Data.mu.Lock()
+	defer t.Data.mu.Unlock()
+
+	snapshot := t.Data.clone()
+
+	defer func() {
+		if p := recover(); p != nil {
+			t.Data.restore(snapshot)
+			panic(p)
+		}
+
+		if err != nil {
+			t.Data.restore(snapshot)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{store: t.Data}, true))
+}
+
+// WithinTransactionOptions ignores opts, serialized transactions meet every
+// isolation level.
+func (t *Transaction) WithinTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	return t.WithinTransaction(ctx, fn)
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type User struct {
+	Data *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &User{
+		Data: store,
+	}
+}
+
+func (u *User) FindOne(ctx context.Context, email string) (model.User, error) {
+	defer u.Data.rlock(ctx)()
+
+	for _, v := range u.Data.user {
+		if v.active && v.Email == email {
+			return v.User, nil
+		}
+	}
+
+	return model.User{}, fmt.Errorf("%w: user %s", model.ErrNotFound, email)
+}
+
+func (u *User) FindByID(ctx context.Context, userID int) (model.User, error) {
+	defer u.Data.rlock(ctx)()
+
+	v, ok := u.Data.user[userID]
+	if !ok || !v.active {
+		return model.User{}, fmt.Errorf("%w: user %d", model.ErrNotFound, userID)
+	}
+
+	result := v.User
+	result.Password = ""
+
+	return result, nil
+}
+
+func (u *User) Fetch(context.Context) error {
+	return nil
+}
+
+func (u *User) Store(ctx context.Context, data model.User) error {
+	pass, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	defer u.Data.lock(ctx)()
+
+	// The email column is unique, deleted users included.
+	for _, v := range u.Data.user {
+		if v.Email == data.Email {
+			return fmt.Errorf("duplicate email %s", data.Email)
+		}
+	}
+
+	data.Id = u.Data.nextID("user")
+	data.Password = string(pass)
+	data.Token = ""
+
+	u.Data.user[data.Id] = &user{User: data, active: true}
+
+	return nil
+}
+
+func (u *User) Update(context.Context) error {
+	return nil
+}
+
+func (u *User) Delete(ctx context.Context, userID int) error {
+	defer u.Data.lock(ctx)()
+
+	if v, ok := u.Data.user[userID]; ok {
+		v.active = false
+	}
+
+	return nil
+}
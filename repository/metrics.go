@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/egaevan/online-learning/metrics"
+)
+
+var queryDuration = metrics.NewHistogramVec(
+	"online_learning_db_query_duration_seconds",
+	"Duration of database queries by repository method.",
+	nil, "method",
+)
+
+// queryCaller names the method that ran a query, e.g. Course.FindOne, by
+// skipping the Database frames.
+func queryCaller() string {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		if !strings.Contains(frame.Function, ".(*Database).") {
+			name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+			name = name[strings.Index(name, ".")+1:]
+
+			return strings.NewReplacer("(*", "", ")", "").Replace(name)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// RegisterPoolMetrics exposes the connection pool of db as gauges, and the
+// waits for a connection, which only grow, as counters.
+func RegisterPoolMetrics(db *Database) {
+	gauges := map[string]func() float64{
+		"online_learning_db_pool_max_open_connections": func() float64 { return float64(db.Stats().MaxOpenConnections) },
+		"online_learning_db_pool_open_connections":     func() float64 { return float64(db.Stats().OpenConnections) },
+		"online_learning_db_pool_in_use_connections":   func() float64 { return float64(db.Stats().InUse) },
+		"online_learning_db_pool_idle_connections":     func() float64 { return float64(db.Stats().Idle) },
+	}
+
+	counters := map[string]func() float64{
+		"online_learning_db_pool_waits_total":        func() float64 { return float64(db.Stats().WaitCount) },
+		"online_learning_db_pool_wait_seconds_total": func() float64 { return db.Stats().WaitDuration.Seconds() },
+	}
+
+	for name, fn := range gauges {
+		metrics.DefaultRegistry.Register(metrics.NewGaugeFunc(name, poolHelp(name), fn))
+	}
+
+	for name, fn := range counters {
+		metrics.DefaultRegistry.Register(metrics.NewCounterFunc(name, poolHelp(name), fn))
+	}
+}
+
+// poolHelp derives the help text from the metric name.
+func poolHelp(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "online_learning_db_pool_"), "_total")
+
+	return "Connection pool " + strings.ReplaceAll(name, "_", " ") + "."
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Notification struct {
+	DB *Database
+}
+
+func NewNotificationRepository(db *Database) NotificationRepository {
+	return &Notification{
+		DB: db,
+	}
+}
+
+func (n *Notification) Store(ctx context.Context, notification model.Notification) (int, error) {
+	query := `
+				INSERT INTO notification
+					(user_id, type, subject, body, link, created_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := n.DB.InsertContext(ctx, query,
+		notification.UserId, notification.Type, notification.Subject, notification.Body, notification.Link, notification.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// FetchByUser returns the notifications of the user, newest first.
+func (n *Notification) FetchByUser(ctx context.Context, userID int) (result []model.Notification, err error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				type,
+				subject,
+				body,
+				link,
+				created_at,
+				read_at
+			FROM
+				notification
+			WHERE
+				user_id = ?
+			ORDER BY
+				created_at DESC, id DESC`
+
+	rows, err := n.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Notification, 0)
+
+	for rows.Next() {
+		t := model.Notification{}
+		err = rows.Scan(
+			&t.Id,
+			&t.UserId,
+			&t.Type,
+			&t.Subject,
+			&t.Body,
+			&t.Link,
+			&t.CreatedAt,
+			&t.ReadAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+// MarkRead marks a notification of the user as read at the given time
+// unless it was read already, and reports whether it did.
+func (n *Notification) MarkRead(ctx context.Context, userID int, notificationID int, at time.Time) (bool, error) {
+	query := `
+				UPDATE
+					notification
+				SET
+					read_at = ?
+				WHERE
+					id = ? AND user_id = ? AND read_at IS NULL
+			`
+
+	res, err := n.DB.ExecContext(ctx, query, at, notificationID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+type Order struct {
+	DB *Database
+}
+
+func NewOrderRepository(db *Database) OrderRepository {
+	return &Order{
+		DB: db,
+	}
+}
+
+// fetchOrder returns the orders matching where, newest first, with their
+// items.
+func (o *Order) fetchOrder(ctx context.Context, where string, args ...interface{}) (result []model.Order, err error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				status,
+				coupon_code,
+				subtotal,
+				discount,
+				total,
+				currency,
+				created_at,
+				updated_at,
+				paid_at
+			FROM
+				orders
+			WHERE
+				%s
+			ORDER BY
+				created_at DESC, id DESC`
+
+	rows, err := o.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Order, 0)
+
+	for rows.Next() {
+		t := model.Order{Items: []model.OrderItem{}}
+		err = rows.Scan(
+			&t.Id,
+			&t.UserId,
+			&t.Status,
+			&t.Coupon,
+			&t.Subtotal.Amount,
+			&t.Discount.Amount,
+			&t.Total.Amount,
+			&t.Total.Currency,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.PaidAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		t.Subtotal.Currency = t.Total.Currency
+		t.Discount.Currency = t.Total.Currency
+
+		result = append(result, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	items, err := o.fetchItem(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		if v, ok := items[result[i].Id]; ok {
+			result[i].Items = v
+		}
+	}
+
+	return result, nil
+}
+
+// fetchItem returns the items of the orders matching where by order id.
+func (o *Order) fetchItem(ctx context.Context, where string, args ...interface{}) (result map[int][]model.OrderItem, err error) {
+	query := `
+			SELECT
+				order_item.id,
+				order_item.order_id,
+				order_item.course_id,
+				order_item.course_name,
+				order_item.price,
+				order_item.discount,
+				order_item.currency
+			FROM
+				order_item
+			WHERE
+				order_item.order_id IN (SELECT id FROM orders WHERE %s)
+			ORDER BY
+				order_item.id ASC`
+
+	rows, err := o.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = map[int][]model.OrderItem{}
+
+	for rows.Next() {
+		var orderID int
+
+		t := model.OrderItem{}
+		err = rows.Scan(
+			&t.Id,
+			&orderID,
+			&t.CourseId,
+			&t.CourseName,
+			&t.Price.Amount,
+			&t.Discount.Amount,
+			&t.Price.Currency,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		t.Discount.Currency = t.Price.Currency
+
+		result[orderID] = append(result[orderID], t)
+	}
+
+	return result, rows.Err()
+}
+
+func (o *Order) FindOne(ctx context.Context, orderID int) (*model.Order, error) {
+	orders, err := o.fetchOrder(ctx, `id = ?`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: order %d", model.ErrNotFound, orderID)
+	}
+
+	return &orders[0], nil
+}
+
+// FetchByUser returns the orders of the user, newest first.
+func (o *Order) FetchByUser(ctx context.Context, userID int) ([]model.Order, error) {
+	return o.fetchOrder(ctx, `user_id = ?`, userID)
+}
+
+// Store inserts the order with its items. Run it in a transaction, so an
+// order is never stored without its items.
+func (o *Order) Store(ctx context.Context, order model.Order) (int, error) {
+	query := `
+				INSERT INTO orders
+					(user_id, status, coupon_code, subtotal, discount, total, currency, created_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := o.DB.InsertContext(ctx, query,
+		order.UserId, order.Status, order.Coupon, order.Subtotal.Amount, order.Discount.Amount, order.Total.Amount, order.Total.Currency, order.CreatedAt, order.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range order.Items {
+		query := `
+					INSERT INTO order_item
+						(order_id, course_id, course_name, price, discount, currency)
+					VALUES
+						(?, ?, ?, ?, ?, ?)
+				`
+
+		_, err := o.DB.InsertContext(ctx, query, id, item.CourseId, item.CourseName, item.Price.Amount, item.Discount.Amount, item.Price.Currency)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), nil
+}
+
+// UpdateStatus moves the order from one status to another at the given
+// time, and reports whether it did. Nothing changes when the order is not
+// in the from status anymore. Paid orders get their payment time.
+func (o *Order) UpdateStatus(ctx context.Context, orderID int, from string, to string, at time.Time) (bool, error) {
+	query := `
+				UPDATE
+					orders
+				SET
+					status = ?,
+					updated_at = ?
+				WHERE
+					id = ? AND status = ?
+			`
+	args := []interface{}{to, at, orderID, from}
+
+	if to == constant.OrderPaid {
+		query = `
+				UPDATE
+					orders
+				SET
+					status = ?,
+					updated_at = ?,
+					paid_at = ?
+				WHERE
+					id = ? AND status = ?
+			`
+		args = []interface{}{to, at, at, orderID, from}
+	}
+
+	res, err := o.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// HasPaidCourse reports whether the user has a paid order for the course.
+func (o *Order) HasPaidCourse(ctx context.Context, userID int, courseID int) (bool, error) {
+	query := `
+			SELECT
+				COUNT(*)
+			FROM
+				order_item
+			JOIN
+				orders ON orders.id = order_item.order_id
+			WHERE
+				orders.user_id = ? AND orders.status = ? AND order_item.course_id = ?`
+
+	var count int
+
+	err := o.DB.QueryRowContext(ctx, query, userID, constant.OrderPaid, courseID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
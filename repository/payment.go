@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Payment struct {
+	DB *Database
+}
+
+func NewPaymentRepository(db *Database) PaymentRepository {
+	return &Payment{
+		DB: db,
+	}
+}
+
+// eventQuery records a webhook event, recording it again changes nothing.
+var eventQuery = map[Dialect]string{
+	MySQL: `
+				INSERT IGNORE INTO payment_event
+					(id, payment_id, type, created_at, received_at)
+				VALUES
+					(?, ?, ?, ?, ?)
+			`,
+	PostgreSQL: eventOnConflict,
+	SQLite:     eventOnConflict,
+}
+
+const eventOnConflict = `
+				INSERT INTO payment_event
+					(id, payment_id, type, created_at, received_at)
+				VALUES
+					(?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO NOTHING
+			`
+
+// fetchPayment returns the payments matching where, newest first.
+func (p *Payment) fetchPayment(ctx context.Context, where string, args ...interface{}) (result []model.Payment, err error) {
+	query := `
+			SELECT
+				id,
+				order_id,
+				amount,
+				currency,
+				status,
+				created_at,
+				updated_at
+			FROM
+				payment
+			WHERE
+				%s
+			ORDER BY
+				created_at DESC, id ASC`
+
+	rows, err := p.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Payment, 0)
+
+	for rows.Next() {
+		t := model.Payment{}
+		err = rows.Scan(
+			&t.Id,
+			&t.OrderId,
+			&t.Amount.Amount,
+			&t.Amount.Currency,
+			&t.Status,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (p *Payment) FindOne(ctx context.Context, paymentID string) (*model.Payment, error) {
+	payments, err := p.fetchPayment(ctx, `id = ?`, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("%w: payment %s", model.ErrNotFound, paymentID)
+	}
+
+	return &payments[0], nil
+}
+
+// FetchByOrder returns the payments of the order, newest first.
+func (p *Payment) FetchByOrder(ctx context.Context, orderID int) ([]model.Payment, error) {
+	return p.fetchPayment(ctx, `order_id = ?`, orderID)
+}
+
+// Store inserts the payment with the id the provider gave it.
+func (p *Payment) Store(ctx context.Context, payment model.Payment) error {
+	query := `
+				INSERT INTO payment
+					(id, order_id, amount, currency, status, created_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+			`
+
+	_, err := p.DB.ExecContext(ctx, query,
+		payment.Id, payment.OrderId, payment.Amount.Amount, payment.Amount.Currency, payment.Status, payment.CreatedAt, payment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *Payment) UpdateStatus(ctx context.Context, paymentID string, status string, at time.Time) error {
+	_, err := p.DB.ExecContext(ctx, `UPDATE payment SET status = ?, updated_at = ? WHERE id = ?`, status, at, paymentID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StoreEvent records a webhook event as handled and reports whether it is
+// new.
+func (p *Payment) StoreEvent(ctx context.Context, event model.PaymentEvent, receivedAt time.Time) (bool, error) {
+	res, err := p.DB.ExecContext(ctx, eventQuery[p.DB.Dialect], event.Id, event.PaymentId, event.Type, event.CreatedAt, receivedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
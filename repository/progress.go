@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Progress struct {
+	DB *Database
+}
+
+func NewProgressRepository(db *Database) ProgressRepository {
+	return &Progress{
+		DB: db,
+	}
+}
+
+// saveProgressQuery inserts the progress of a lesson or updates the existing
+// row. started_at is kept from the first write and completed_at, once set,
+// is never cleared.
+var saveProgressQuery = map[Dialect]string{
+	MySQL: `
+				INSERT INTO lesson_progress
+					(user_id, lesson_id, course_id, position, started_at, completed_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					position = VALUES(position),
+					completed_at = COALESCE(completed_at, VALUES(completed_at)),
+					updated_at = VALUES(updated_at)
+			`,
+	PostgreSQL: saveProgressOnConflict,
+	SQLite:     saveProgressOnConflict,
+}
+
+const saveProgressOnConflict = `
+				INSERT INTO lesson_progress
+					(user_id, lesson_id, course_id, position, started_at, completed_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (user_id, lesson_id) DO UPDATE SET
+					position = excluded.position,
+					completed_at = COALESCE(lesson_progress.completed_at, excluded.completed_at),
+					updated_at = excluded.updated_at
+			`
+
+// Save records the progress in one statement, so clients may send the same
+// update again and again.
+func (p *Progress) Save(ctx context.Context, progress model.LessonProgress) error {
+	_, err := p.DB.ExecContext(ctx, saveProgressQuery[p.DB.Dialect],
+		progress.UserId, progress.LessonId, progress.CourseId, progress.Position,
+		progress.StartedAt, progress.CompletedAt, progress.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// fetch returns the progress of the user in active lessons matching where,
+// most recently updated first.
+func (p *Progress) fetch(ctx context.Context, where string, args ...interface{}) (result []model.LessonProgress, err error) {
+	query := `
+			SELECT
+				lesson_progress.user_id,
+				lesson_progress.lesson_id,
+				lesson_progress.course_id,
+				lesson_progress.position,
+				lesson_progress.started_at,
+				lesson_progress.completed_at,
+				lesson_progress.updated_at
+			FROM
+				lesson_progress
+			JOIN
+				lesson ON lesson.id = lesson_progress.lesson_id AND lesson.flag_aktif = 1
+			JOIN
+				section ON section.id = lesson.section_id AND section.flag_aktif = 1
+			WHERE
+				%s
+			ORDER BY
+				lesson_progress.updated_at DESC, lesson_progress.id DESC`
+
+	rows, err := p.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.LessonProgress, 0)
+
+	for rows.Next() {
+		t := model.LessonProgress{}
+		err = rows.Scan(
+			&t.UserId,
+			&t.LessonId,
+			&t.CourseId,
+			&t.Position,
+			&t.StartedAt,
+			&t.CompletedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (p *Progress) FetchByUser(ctx context.Context, userID int) ([]model.LessonProgress, error) {
+	return p.fetch(ctx, `lesson_progress.user_id = ?`, userID)
+}
+
+func (p *Progress) FetchByCourse(ctx context.Context, userID int, courseID int) ([]model.LessonProgress, error) {
+	return p.fetch(ctx, `lesson_progress.user_id = ? AND lesson_progress.course_id = ?`, userID, courseID)
+}
+
+// Summary counts the required lessons of every active course the user is
+// enrolled in, and how many of them the user completed.
+func (p *Progress) Summary(ctx context.Context, userID int) (result []model.CourseProgress, err error) {
+	query := `
+			SELECT
+				enrollment.course_id,
+				enrollment.completed_at,
+				(
+					SELECT
+						COUNT(lesson.id)
+					FROM
+						lesson
+					JOIN
+						section ON section.id = lesson.section_id
+					WHERE
+						section.course_id = enrollment.course_id AND section.flag_aktif = 1
+						AND lesson.flag_aktif = 1 AND lesson.optional = 0
+				),
+				(
+					SELECT
+						COUNT(lesson.id)
+					FROM
+						lesson_progress
+					JOIN
+						lesson ON lesson.id = lesson_progress.lesson_id
+					JOIN
+						section ON section.id = lesson.section_id
+					WHERE
+						lesson_progress.user_id = enrollment.user_id AND section.course_id = enrollment.course_id
+						AND section.flag_aktif = 1 AND lesson.flag_aktif = 1 AND lesson.optional = 0
+						AND lesson_progress.completed_at IS NOT NULL
+				)
+			FROM
+				enrollment
+			JOIN
+				course ON course.id = enrollment.course_id
+			WHERE
+				enrollment.user_id = ? AND course.flag_aktif = 1
+			ORDER BY
+				enrollment.course_id ASC`
+
+	rows, err := p.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.CourseProgress, 0)
+
+	for rows.Next() {
+		t := model.CourseProgress{}
+		err = rows.Scan(
+			&t.CourseId,
+			&t.CompletedAt,
+			&t.RequiredLessons,
+			&t.CompletedLessons,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,464 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Quiz struct {
+	DB *Database
+}
+
+func NewQuizRepository(db *Database) QuizRepository {
+	return &Quiz{
+		DB: db,
+	}
+}
+
+// saveQuizQuery inserts the settings of a quiz or updates the existing row.
+var saveQuizQuery = map[Dialect]string{
+	MySQL: `
+				INSERT INTO quiz
+					(lesson_id, time_limit, max_attempts, pass_score, score_policy)
+				VALUES
+					(?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					time_limit = VALUES(time_limit),
+					max_attempts = VALUES(max_attempts),
+					pass_score = VALUES(pass_score),
+					score_policy = VALUES(score_policy)
+			`,
+	PostgreSQL: saveQuizOnConflict,
+	SQLite:     saveQuizOnConflict,
+}
+
+const saveQuizOnConflict = `
+				INSERT INTO quiz
+					(lesson_id, time_limit, max_attempts, pass_score, score_policy)
+				VALUES
+					(?, ?, ?, ?, ?)
+				ON CONFLICT (lesson_id) DO UPDATE SET
+					time_limit = excluded.time_limit,
+					max_attempts = excluded.max_attempts,
+					pass_score = excluded.pass_score,
+					score_policy = excluded.score_policy
+			`
+
+// jsonColumn encodes v for the TEXT columns holding options, answer keys,
+// responses and results.
+func jsonColumn(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// FindQuiz returns the settings of the quiz of an active lesson with its
+// active questions in order.
+func (q *Quiz) FindQuiz(ctx context.Context, lessonID int) (*model.Quiz, error) {
+	query := `
+			SELECT
+				quiz.lesson_id,
+				section.course_id,
+				quiz.time_limit,
+				quiz.max_attempts,
+				quiz.pass_score,
+				quiz.score_policy
+			FROM
+				quiz
+			JOIN
+				lesson ON lesson.id = quiz.lesson_id AND lesson.flag_aktif = 1
+			JOIN
+				section ON section.id = lesson.section_id AND section.flag_aktif = 1
+			WHERE
+				quiz.lesson_id = ?`
+
+	quiz := model.Quiz{}
+
+	err := q.DB.QueryRowContext(ctx, query, lessonID).Scan(&quiz.LessonId, &quiz.CourseId, &quiz.TimeLimit, &quiz.MaxAttempts, &quiz.PassScore, &quiz.ScorePolicy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: quiz %d", model.ErrNotFound, lessonID)
+		}
+
+		return nil, err
+	}
+
+	quiz.Questions, err = q.fetchQuestion(ctx, `lesson_id = ?`, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quiz, nil
+}
+
+func (q *Quiz) SaveQuiz(ctx context.Context, quiz model.Quiz) error {
+	_, err := q.DB.ExecContext(ctx, saveQuizQuery[q.DB.Dialect],
+		quiz.LessonId, quiz.TimeLimit, quiz.MaxAttempts, quiz.PassScore, quiz.ScorePolicy)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// fetchQuestion returns the active questions matching where, ordered by
+// position.
+func (q *Quiz) fetchQuestion(ctx context.Context, where string, args ...interface{}) (result []model.Question, err error) {
+	query := `
+			SELECT
+				id,
+				lesson_id,
+				type,
+				prompt,
+				options,
+				answer,
+				points,
+				feedback,
+				position
+			FROM
+				quiz_question
+			WHERE
+				%s AND flag_aktif = 1
+			ORDER BY
+				position ASC, id ASC`
+
+	rows, err := q.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Question, 0)
+
+	for rows.Next() {
+		var options, answer string
+
+		t := model.Question{}
+		err = rows.Scan(
+			&t.Id,
+			&t.LessonId,
+			&t.Type,
+			&t.Prompt,
+			&options,
+			&answer,
+			&t.Points,
+			&t.Feedback,
+			&t.Position,
+		)
+
+		if err == nil {
+			err = json.Unmarshal([]byte(options), &t.Options)
+		}
+
+		if err == nil {
+			err = json.Unmarshal([]byte(answer), &t.Answer)
+		}
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (q *Quiz) FindQuestion(ctx context.Context, questionID int) (*model.Question, error) {
+	questions, err := q.fetchQuestion(ctx, `id = ?`, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("%w: question %d", model.ErrNotFound, questionID)
+	}
+
+	return &questions[0], nil
+}
+
+// StoreQuestion appends the question after the last question of its quiz.
+func (q *Quiz) StoreQuestion(ctx context.Context, question model.Question) (int, error) {
+	query := `SELECT COALESCE(MAX(position), 0) FROM quiz_question WHERE lesson_id = ? AND flag_aktif = 1`
+	query2 := `
+				INSERT INTO quiz_question
+					(lesson_id, type, prompt, options, answer, points, feedback, position)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	options, err := jsonColumn(question.Options)
+	if err != nil {
+		return 0, err
+	}
+
+	answer, err := jsonColumn(question.Answer)
+	if err != nil {
+		return 0, err
+	}
+
+	var last int
+
+	err = q.DB.QueryRowContext(ctx, query, question.LessonId).Scan(&last)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := q.DB.InsertContext(ctx, query2,
+		question.LessonId, question.Type, question.Prompt, options, answer, question.Points, question.Feedback, last+1)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (q *Quiz) UpdateQuestion(ctx context.Context, question model.Question) error {
+	query := `
+				UPDATE
+					quiz_question
+				SET
+					type = ?,
+					prompt = ?,
+					options = ?,
+					answer = ?,
+					points = ?,
+					feedback = ?
+				WHERE
+					id = ? AND flag_aktif = 1
+			`
+
+	options, err := jsonColumn(question.Options)
+	if err != nil {
+		return err
+	}
+
+	answer, err := jsonColumn(question.Answer)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.DB.ExecContext(ctx, query,
+		question.Type, question.Prompt, options, answer, question.Points, question.Feedback, question.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q *Quiz) DeleteQuestion(ctx context.Context, questionID int) error {
+	query := `
+				UPDATE
+					quiz_question
+				SET
+					flag_aktif = 0
+				WHERE
+					id = ?
+			`
+
+	_, err := q.DB.ExecContext(ctx, query, questionID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReorderQuestion numbers the questions of the quiz in the order of
+// questionIDs.
+func (q *Quiz) ReorderQuestion(ctx context.Context, lessonID int, questionIDs []int) error {
+	query := `
+				UPDATE
+					quiz_question
+				SET
+					position = ?
+				WHERE
+					id = ? AND lesson_id = ?
+			`
+
+	for i, id := range questionIDs {
+		_, err := q.DB.ExecContext(ctx, query, i+1, id, lessonID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchAttempt returns the attempts matching where, oldest first.
+func (q *Quiz) fetchAttempt(ctx context.Context, where string, args ...interface{}) (result []model.QuizAttempt, err error) {
+	query := `
+			SELECT
+				id,
+				user_id,
+				lesson_id,
+				course_id,
+				number,
+				started_at,
+				expires_at,
+				submitted_at,
+				score,
+				max_score,
+				percentage,
+				passed,
+				responses,
+				results
+			FROM
+				quiz_attempt
+			WHERE
+				%s
+			ORDER BY
+				started_at ASC, id ASC`
+
+	rows, err := q.DB.QueryContext(ctx, fmt.Sprintf(query, where), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.QuizAttempt, 0)
+
+	for rows.Next() {
+		var responses, results string
+
+		t := model.QuizAttempt{}
+		err = rows.Scan(
+			&t.Id,
+			&t.UserId,
+			&t.LessonId,
+			&t.CourseId,
+			&t.Number,
+			&t.StartedAt,
+			&t.ExpiresAt,
+			&t.SubmittedAt,
+			&t.Score,
+			&t.MaxScore,
+			&t.Percentage,
+			&t.Passed,
+			&responses,
+			&results,
+		)
+
+		if err == nil {
+			err = json.Unmarshal([]byte(responses), &t.Responses)
+		}
+
+		if err == nil {
+			err = json.Unmarshal([]byte(results), &t.Results)
+		}
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (q *Quiz) FindAttempt(ctx context.Context, attemptID int) (*model.QuizAttempt, error) {
+	attempts, err := q.fetchAttempt(ctx, `id = ?`, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attempts) == 0 {
+		return nil, fmt.Errorf("%w: attempt %d", model.ErrNotFound, attemptID)
+	}
+
+	return &attempts[0], nil
+}
+
+// StoreAttempt stores a started attempt. The number of the attempt is
+// unique per user and quiz, taking a number twice returns
+// model.ErrConflict.
+func (q *Quiz) StoreAttempt(ctx context.Context, attempt model.QuizAttempt) (int, error) {
+	query := `
+				INSERT INTO quiz_attempt
+					(user_id, lesson_id, course_id, number, started_at, expires_at, responses, results)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := q.DB.InsertContext(ctx, query,
+		attempt.UserId, attempt.LessonId, attempt.CourseId, attempt.Number, attempt.StartedAt, attempt.ExpiresAt, "null", "null")
+	if q.DB.Dialect.IsDuplicate(err) {
+		return 0, fmt.Errorf("%w: attempt %d of user %d at quiz %d exists", model.ErrConflict, attempt.Number, attempt.UserId, attempt.LessonId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// SubmitAttempt stores the grade of an attempt unless it was submitted
+// already, and reports whether it did.
+func (q *Quiz) SubmitAttempt(ctx context.Context, attempt model.QuizAttempt) (bool, error) {
+	query := `
+				UPDATE
+					quiz_attempt
+				SET
+					submitted_at = ?,
+					score = ?,
+					max_score = ?,
+					percentage = ?,
+					passed = ?,
+					responses = ?,
+					results = ?
+				WHERE
+					id = ? AND submitted_at IS NULL
+			`
+
+	responses, err := jsonColumn(attempt.Responses)
+	if err != nil {
+		return false, err
+	}
+
+	results, err := jsonColumn(attempt.Results)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := q.DB.ExecContext(ctx, query,
+		attempt.SubmittedAt, attempt.Score, attempt.MaxScore, attempt.Percentage, boolInt(attempt.Passed), responses, results, attempt.Id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (q *Quiz) FetchAttempt(ctx context.Context, userID int, lessonID int) ([]model.QuizAttempt, error) {
+	return q.fetchAttempt(ctx, `user_id = ? AND lesson_id = ?`, userID, lessonID)
+}
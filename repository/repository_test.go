@@ -0,0 +1,13 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/egaevan/online-learning/repository/repotest"
+)
+
+// TestRepositories always runs against SQLite. MySQL and PostgreSQL run
+// when repotest.MySQLDSNEnv and repotest.PostgreSQLDSNEnv are set.
+func TestRepositories(t *testing.T) {
+	repotest.RunSQL(t)
+}
@@ -0,0 +1,189 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testAssignment(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Assignment
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	courseID := storeCourse(t, repos.Course, category, "Learn Go", 0)
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	first, err := repos.Course.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Intro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := repos.Course.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := repos.Course.StoreLesson(ctx, model.Lesson{SectionId: second, Title: "Final project", Type: constant.LessonAssignment, Content: "Build a CLI"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	essay, err := repos.Course.StoreLesson(ctx, model.Lesson{SectionId: first, Title: "Essay", Type: constant.LessonAssignment, Content: "Why Go?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindAssignment(ctx, project); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindAssignment before SaveAssignment returned %v, want ErrNotFound", err)
+	}
+
+	due := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rubric := []model.Criterion{
+		{Title: "Works", Description: "The CLI runs.", Points: 6},
+		{Title: "Style", Points: 4},
+	}
+
+	if err := repo.SaveAssignment(ctx, model.Assignment{LessonId: project, DueAt: due, Rubric: rubric[:1], MaxScore: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SaveAssignment(ctx, model.Assignment{LessonId: project, DueAt: due, Rubric: rubric, MaxScore: 10, PenaltyPerDay: 10, MaxPenalty: 50, CutoffDays: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SaveAssignment(ctx, model.Assignment{LessonId: essay, DueAt: due.Add(-24 * time.Hour), Rubric: rubric[1:], MaxScore: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	assignment, err := repo.FindAssignment(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assignment.CourseId != courseID || assignment.Title != "Final project" || !assignment.DueAt.Equal(due) || assignment.MaxScore != 10 ||
+		assignment.PenaltyPerDay != 10 || assignment.MaxPenalty != 50 || assignment.CutoffDays != 7 {
+		t.Errorf("FindAssignment = %+v, want the settings of the second SaveAssignment", assignment)
+	}
+
+	if len(assignment.Rubric) != 2 || assignment.Rubric[0].Description != "The CLI runs." || assignment.Rubric[1].Points != 4 {
+		t.Errorf("rubric of FindAssignment = %+v", assignment.Rubric)
+	}
+
+	assignments, err := repo.FetchAssignment(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(assignments) != 2 || assignments[0].LessonId != essay || assignments[1].LessonId != project {
+		t.Errorf("FetchAssignment = %+v, want the essay then the project", assignments)
+	}
+
+	submittedAt := due.Add(30 * time.Hour)
+
+	id, err := repo.StoreSubmission(ctx, model.Submission{UserId: ann, LessonId: project, CourseId: courseID, SubmittedAt: submittedAt, DaysLate: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.StoreSubmission(ctx, model.Submission{UserId: ann, LessonId: project, CourseId: courseID, SubmittedAt: submittedAt}); err == nil {
+		t.Error("StoreSubmission accepted a second submission of the user")
+	}
+
+	for _, name := range []string{"main.go", "README.md"} {
+		_, err := repo.StoreFile(ctx, model.SubmissionFile{SubmissionId: id, Name: name, ContentType: "text/plain", Size: 42, Key: "submission/" + name})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	submission, err := repo.FindUserSubmission(ctx, ann, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if submission.Id != id || !submission.SubmittedAt.Equal(submittedAt) || submission.DaysLate != 2 || submission.GradedAt != nil || submission.Scores != nil {
+		t.Errorf("FindUserSubmission = %+v, want the ungraded submission", submission)
+	}
+
+	if len(submission.Files) != 2 || submission.Files[0].Name != "main.go" || submission.Files[1].Key != "submission/README.md" || submission.Files[1].Size != 42 {
+		t.Fatalf("files of FindUserSubmission = %+v", submission.Files)
+	}
+
+	file, err := repo.FindFile(ctx, submission.Files[1].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.SubmissionId != id || file.Name != "README.md" || file.ContentType != "text/plain" {
+		t.Errorf("FindFile = %+v", file)
+	}
+
+	if err := repo.DeleteFile(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindFile(ctx, file.Id); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindFile of a deleted file returned %v, want ErrNotFound", err)
+	}
+
+	resubmittedAt := due.Add(-time.Hour)
+
+	done, err := repo.Resubmit(ctx, model.Submission{Id: id, SubmittedAt: resubmittedAt})
+	if err != nil || !done {
+		t.Fatalf("Resubmit = %v, %v, want true", done, err)
+	}
+
+	gradedAt := due.Add(48 * time.Hour)
+	submission.Scores = []model.CriterionScore{{Criterion: 0, Points: 5, Comment: "Crashes on empty input."}, {Criterion: 1, Points: 4}}
+	submission.RawScore, submission.Penalty, submission.Score = 9, 0, 9
+	submission.Comment = "Nice work."
+	submission.GradedAt = &gradedAt
+	submission.GradedBy = bob
+
+	if err := repo.GradeSubmission(ctx, *submission); err != nil {
+		t.Fatal(err)
+	}
+
+	if done, err := repo.Resubmit(ctx, model.Submission{Id: id, SubmittedAt: gradedAt}); err != nil || done {
+		t.Errorf("Resubmit of a graded submission = %v, %v, want false", done, err)
+	}
+
+	submission, err = repo.FindSubmission(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !submission.SubmittedAt.Equal(resubmittedAt) || submission.DaysLate != 0 || len(submission.Files) != 0 || submission.GradedAt == nil ||
+		!submission.GradedAt.Equal(gradedAt) || submission.GradedBy != bob || submission.Score != 9 || submission.Comment != "Nice work." ||
+		len(submission.Scores) != 2 || submission.Scores[0].Comment != "Crashes on empty input." {
+		t.Errorf("FindSubmission after grading = %+v", submission)
+	}
+
+	if _, err := repo.FindSubmission(ctx, id+1000); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindSubmission of a missing submission returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.FindUserSubmission(ctx, bob, project); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindUserSubmission without a submission returned %v, want ErrNotFound", err)
+	}
+
+	other, err := repo.StoreSubmission(ctx, model.Submission{UserId: bob, LessonId: essay, CourseId: courseID, SubmittedAt: due.Add(-2 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submissions, err := repo.FetchSubmission(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(submissions) != 2 || submissions[0].Id != other || submissions[1].Id != id || submissions[1].Score != 9 {
+		t.Errorf("FetchSubmission = %+v, want both submissions oldest first", submissions)
+	}
+}
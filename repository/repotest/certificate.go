@@ -0,0 +1,115 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+func testCertificate(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Certificate
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	golang := storeCourse(t, repos.Course, category, "Go Basics", 0)
+	rust := storeCourse(t, repos.Course, category, "Rust Basics", 0)
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first := model.Certificate{
+		Id:          strings.Repeat("a1", 16),
+		UserId:      ann,
+		CourseId:    golang,
+		StudentName: "Ann",
+		CourseName:  "Go Basics",
+		CompletedAt: at,
+		IssuedAt:    at.Add(time.Minute),
+		Sha256:      strings.Repeat("0f", 32),
+	}
+
+	second := first
+	second.Id = strings.Repeat("b2", 16)
+	second.CourseId = rust
+	second.CourseName = "Rust Basics"
+	second.IssuedAt = at.Add(time.Hour)
+
+	for _, c := range []model.Certificate{first, second} {
+		if err := repo.Store(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicate := first
+	duplicate.Id = strings.Repeat("c3", 16)
+
+	if err := repo.Store(ctx, duplicate); err == nil {
+		t.Error("Store accepted a second certificate of the user for the course")
+	}
+
+	got, err := repo.FindOne(ctx, first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.UserId != ann || got.CourseId != golang || got.StudentName != "Ann" || got.CourseName != "Go Basics" ||
+		!got.CompletedAt.Equal(at) || !got.IssuedAt.Equal(first.IssuedAt) || got.Sha256 != first.Sha256 || got.RevokedAt != nil {
+		t.Errorf("FindOne = %+v, want %+v", got, first)
+	}
+
+	if _, err := repo.FindOne(ctx, strings.Repeat("d4", 16)); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing certificate returned %v, want ErrNotFound", err)
+	}
+
+	got, err = repo.FindByCourse(ctx, ann, rust)
+	if err != nil || got.Id != second.Id {
+		t.Errorf("FindByCourse = %+v, %v, want the Rust certificate", got, err)
+	}
+
+	if _, err := repo.FindByCourse(ctx, bob, rust); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByCourse of a user without certificate returned %v, want ErrNotFound", err)
+	}
+
+	certificates, err := repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(certificates) != 2 || certificates[0].Id != second.Id || certificates[1].Id != first.Id {
+		t.Errorf("FetchByUser = %+v, want both certificates newest first", certificates)
+	}
+
+	revokedAt := at.Add(24 * time.Hour)
+
+	if done, err := repo.Revoke(ctx, first.Id, revokedAt, "plagiarism"); err != nil || !done {
+		t.Fatalf("Revoke = %v, %v, want true", done, err)
+	}
+
+	if done, err := repo.Revoke(ctx, first.Id, revokedAt.Add(time.Hour), "again"); err != nil || done {
+		t.Errorf("second Revoke = %v, %v, want false", done, err)
+	}
+
+	if done, err := repo.Revoke(ctx, strings.Repeat("d4", 16), revokedAt, "missing"); err != nil || done {
+		t.Errorf("Revoke of a missing certificate = %v, %v, want false", done, err)
+	}
+
+	got, err = repo.FindOne(ctx, first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.RevokedAt == nil || !got.RevokedAt.Equal(revokedAt) || got.RevokeReason != "plagiarism" {
+		t.Errorf("revoked certificate = %+v, want revoked at %v for plagiarism", got, revokedAt)
+	}
+
+	certificates, err = repo.FetchByUser(ctx, bob)
+	if err != nil || len(certificates) != 0 {
+		t.Errorf("FetchByUser of a user without certificates = %+v, %v, want none", certificates, err)
+	}
+}
@@ -0,0 +1,234 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testCoupon(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Coupon
+
+	programming := storeCategory(t, repos.Course, "Programming", 0)
+	design := storeCategory(t, repos.Course, "Design", 0)
+	golang := storeCourse(t, repos.Course, programming, "Go Basics", 1500)
+	rust := storeCourse(t, repos.Course, programming, "Rust Basics", 2500)
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ends := at.Add(30 * 24 * time.Hour)
+
+	spring := model.Coupon{
+		Code:           "SPRING",
+		Type:           constant.CouponPercent,
+		Value:          20,
+		CourseIds:      []int{rust, golang},
+		CategoryIds:    []int{design},
+		StartsAt:       &at,
+		EndsAt:         &ends,
+		MaxUses:        2,
+		MaxUsesPerUser: 1,
+		MinAmount:      1000,
+		CreatedAt:      at,
+		UpdatedAt:      at,
+	}
+
+	springID, err := repo.Store(ctx, spring)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	welcomeID, err := repo.Store(ctx, model.Coupon{
+		Code:      "WELCOME",
+		Type:      constant.CouponFixed,
+		Value:     500,
+		CreatedAt: at.Add(time.Hour),
+		UpdatedAt: at.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.FindOne(ctx, springID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Code != "SPRING" || got.Type != constant.CouponPercent || got.Value != 20 || got.MaxUses != 2 || got.MaxUsesPerUser != 1 || got.MinAmount != 1000 ||
+		got.UsedCount != 0 || got.StartsAt == nil || !got.StartsAt.Equal(at) || got.EndsAt == nil || !got.EndsAt.Equal(ends) || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindOne = %+v, want the SPRING coupon", got)
+	}
+
+	wantCourses := []int{golang, rust}
+	if golang > rust {
+		wantCourses = []int{rust, golang}
+	}
+
+	if !reflect.DeepEqual(got.CourseIds, wantCourses) || !reflect.DeepEqual(got.CategoryIds, []int{design}) {
+		t.Errorf("FindOne scope = %v and %v, want %v and %v", got.CourseIds, got.CategoryIds, wantCourses, []int{design})
+	}
+
+	welcome, err := repo.FindByCode(ctx, "WELCOME")
+	if err != nil || welcome.Id != welcomeID || welcome.StartsAt != nil || welcome.EndsAt != nil || len(welcome.CourseIds) != 0 || len(welcome.CategoryIds) != 0 {
+		t.Errorf("FindByCode = %+v, %v, want WELCOME covering everything at any time", welcome, err)
+	}
+
+	if _, err := repo.FindByCode(ctx, "MISSING"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByCode of a missing code returned %v, want ErrNotFound", err)
+	}
+
+	coupons, err := repo.Fetch(ctx)
+	if err != nil || len(coupons) != 2 || coupons[0].Id != welcomeID || coupons[1].Id != springID {
+		t.Errorf("Fetch = %+v, %v, want both coupons newest first", coupons, err)
+	}
+
+	order := func(user int) int {
+		t.Helper()
+
+		id, err := repos.Order.Store(ctx, model.Order{
+			UserId:    user,
+			Status:    constant.OrderPending,
+			Total:     model.Money{Amount: 1200, Currency: "USD"},
+			CreatedAt: at,
+			UpdatedAt: at,
+			Items:     []model.OrderItem{{CourseId: golang, CourseName: "Go Basics", Price: model.Money{Amount: 1500, Currency: "USD"}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return id
+	}
+
+	redeem := func(user int, orderID int) bool {
+		t.Helper()
+
+		done, err := repo.Redeem(ctx, model.CouponRedemption{
+			CouponId:  springID,
+			UserId:    user,
+			OrderId:   orderID,
+			Discount:  model.Money{Amount: 300, Currency: "USD"},
+			CreatedAt: at,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return done
+	}
+
+	annOrder := order(ann)
+
+	if !redeem(ann, annOrder) || !redeem(bob, order(bob)) {
+		t.Fatal("Redeem below the limit did nothing")
+	}
+
+	// The coupon is used up.
+	if redeem(bob, order(bob)) {
+		t.Error("Redeem past max_uses was done")
+	}
+
+	for _, c := range []struct {
+		user, want int
+	}{
+		{ann, 1},
+		{bob, 1},
+	} {
+		count, err := repo.CountRedemption(ctx, springID, c.user)
+		if err != nil || count != c.want {
+			t.Errorf("CountRedemption(%d) = %d, %v, want %d", c.user, count, err, c.want)
+		}
+	}
+
+	if got, err := repo.FindOne(ctx, springID); err != nil || got.UsedCount != 2 {
+		t.Errorf("FindOne after redemptions = %+v, %v, want 2 uses", got, err)
+	}
+
+	if err := repo.Release(ctx, annOrder); err != nil {
+		t.Fatal(err)
+	}
+
+	// Releasing again changes nothing.
+	if err := repo.Release(ctx, annOrder); err != nil {
+		t.Fatal(err)
+	}
+
+	// The coupon has a use left, but not for Bob.
+	if redeem(bob, order(bob)) {
+		t.Error("Redeem past max_uses_per_user was done")
+	}
+
+	if got, err := repo.FindOne(ctx, springID); err != nil || got.UsedCount != 1 {
+		t.Errorf("FindOne after Release = %+v, %v, want 1 use", got, err)
+	}
+
+	if count, err := repo.CountRedemption(ctx, springID, ann); err != nil || count != 0 {
+		t.Errorf("CountRedemption after Release = %d, %v, want 0", count, err)
+	}
+
+	spring.Id = springID
+	spring.Code = "SUMMER"
+	spring.Value = 30
+	spring.CourseIds = []int{golang}
+	spring.CategoryIds = nil
+	spring.EndsAt = nil
+	spring.UpdatedAt = at.Add(2 * time.Hour)
+
+	if err := repo.Update(ctx, spring); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindByCode(ctx, "SUMMER")
+	if err != nil || got.Id != springID || got.Value != 30 || got.EndsAt != nil || got.UsedCount != 1 || !got.CreatedAt.Equal(at) || !got.UpdatedAt.Equal(spring.UpdatedAt) ||
+		!reflect.DeepEqual(got.CourseIds, []int{golang}) || len(got.CategoryIds) != 0 {
+		t.Errorf("FindByCode after Update = %+v, %v, want SUMMER keeping its use", got, err)
+	}
+
+	taken := *welcome
+	taken.Code = "SUMMER"
+
+	if err := repo.Update(ctx, taken); !errors.Is(err, model.ErrConflict) {
+		t.Errorf("Update to the code of another coupon returned %v, want ErrConflict", err)
+	}
+
+	taken.Id = 0
+
+	if _, err := repo.Store(ctx, taken); !errors.Is(err, model.ErrConflict) {
+		t.Errorf("Store of a code in use returned %v, want ErrConflict", err)
+	}
+
+	if err := repo.Delete(ctx, welcomeID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindOne(ctx, welcomeID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a deleted coupon returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.FindByCode(ctx, "WELCOME"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByCode of a deleted coupon returned %v, want ErrNotFound", err)
+	}
+
+	coupons, err = repo.Fetch(ctx)
+	if err != nil || len(coupons) != 1 || coupons[0].Id != springID {
+		t.Errorf("Fetch after Delete = %+v, %v, want SUMMER only", coupons, err)
+	}
+
+	done, err := repo.Redeem(ctx, model.CouponRedemption{CouponId: welcomeID, UserId: ann, OrderId: order(ann), CreatedAt: at})
+	if err != nil || done {
+		t.Errorf("Redeem of a deleted coupon = %v, %v, want nothing done", done, err)
+	}
+
+	// The code of a deleted coupon can be taken again.
+	if _, err := repo.Store(ctx, model.Coupon{Code: "WELCOME", Type: constant.CouponFixed, Value: 300, CreatedAt: at, UpdatedAt: at}); err != nil {
+		t.Errorf("Store of the code of a deleted coupon returned %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func sectionTitles(sections []model.Section) []string {
+	result := make([]string, 0, len(sections))
+	for _, s := range sections {
+		result = append(result, s.Title)
+	}
+
+	return result
+}
+
+func testCurriculum(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Course
+
+	category := storeCategory(t, repo, "Programming", 0)
+	courseID := storeCourse(t, repo, category, "Learn Go", 0)
+	otherID := storeCourse(t, repo, category, "Rust", 0)
+
+	intro, err := repo.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Intro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basics, err := repo.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Basics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.StoreSection(ctx, model.Section{CourseId: otherID, Title: "Other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	welcome, err := repo.StoreLesson(ctx, model.Lesson{SectionId: intro, Title: "Welcome", Type: constant.LessonVideo, Content: "https://example.com/welcome.mp4", Duration: 90, FreePreview: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setup, err := repo.StoreLesson(ctx, model.Lesson{SectionId: intro, Title: "Setup", Type: constant.LessonText, Content: "# Setup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lesson, err := repo.FindLesson(ctx, welcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := model.Lesson{Id: welcome, SectionId: intro, CourseId: courseID, Title: "Welcome", Type: constant.LessonVideo, Content: "https://example.com/welcome.mp4", Duration: 90, FreePreview: true, Position: 1}
+	if *lesson != want {
+		t.Errorf("FindLesson = %+v, want %+v", *lesson, want)
+	}
+
+	curriculum, err := repo.FetchCurriculum(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(curriculum) != 2 || curriculum[0].Id != intro || curriculum[1].Id != basics {
+		t.Fatalf("FetchCurriculum returned %v, want Intro and Basics", sectionTitles(curriculum))
+	}
+
+	if len(curriculum[0].Lessons) != 2 || curriculum[0].Lessons[0].Id != welcome || curriculum[0].Lessons[1].Id != setup || len(curriculum[1].Lessons) != 0 {
+		t.Errorf("FetchCurriculum lessons = %+v", curriculum)
+	}
+
+	if err := repo.ReorderSection(ctx, courseID, []int{basics, intro}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.ReorderLesson(ctx, intro, []int{setup, welcome}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.UpdateLesson(ctx, model.Lesson{Id: welcome, Title: "Hello", Type: constant.LessonLink, Content: "https://example.com", FreePreview: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.UpdateSection(ctx, model.Section{Id: basics, Title: "Go Basics"}); err != nil {
+		t.Fatal(err)
+	}
+
+	section, err := repo.FindSection(ctx, intro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(section.Lessons) != 2 || section.Lessons[0].Id != setup || section.Lessons[1].Title != "Hello" || section.Lessons[1].FreePreview {
+		t.Errorf("FindSection after reorder and update = %+v", section)
+	}
+
+	curriculum, err = repo.FetchCurriculum(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(curriculum) != 2 || curriculum[0].Title != "Go Basics" || curriculum[1].Id != intro {
+		t.Errorf("FetchCurriculum after reorder returned %v, want Go Basics and Intro", sectionTitles(curriculum))
+	}
+
+	if err := repo.DeleteLesson(ctx, setup); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindLesson(ctx, setup); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindLesson of a deleted lesson returned %v, want ErrNotFound", err)
+	}
+
+	next, err := repo.StoreLesson(ctx, model.Lesson{SectionId: intro, Title: "Next", Type: constant.LessonText, Content: "next"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lesson, err := repo.FindLesson(ctx, next); err != nil || lesson.Position != 3 {
+		t.Errorf("FindLesson of an appended lesson = %+v, %v, want position 3", lesson, err)
+	}
+
+	if err := repo.DeleteSection(ctx, intro); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindSection(ctx, intro); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindSection of a deleted section returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.FindLesson(ctx, welcome); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindLesson in a deleted section returned %v, want ErrNotFound", err)
+	}
+
+	detail, err := repo.FindOne(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.BumpVersion(ctx, courseID); err != nil {
+		t.Fatal(err)
+	}
+
+	bumped, err := repo.FindOne(ctx, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bumped.Version != detail.Version+1 {
+		t.Errorf("BumpVersion left version %d, want %d", bumped.Version, detail.Version+1)
+	}
+}
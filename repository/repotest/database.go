@@ -0,0 +1,117 @@
+// Package repotest holds the behaviour every repository implementation has
+// to share. The suites take *testing.T so any test package can run them
+// against its own implementation.
+package repotest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/config"
+	"github.com/egaevan/online-learning/migration"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+// DSN environment variables for the server based dialects. The dialect is
+// skipped when its variable is empty.
+const (
+	MySQLDSNEnv      = "ONLINE_LEARNING_TEST_MYSQL_DSN"
+	PostgreSQLDSNEnv = "ONLINE_LEARNING_TEST_POSTGRES_DSN"
+)
+
+// OpenDatabase returns an empty, fully migrated database of the given
+// dialect. SQLite uses a file in a temporary directory.
+func OpenDatabase(t *testing.T, dialect repository.Dialect) *repository.Database {
+	t.Helper()
+
+	var (
+		db  *repository.Database
+		err error
+	)
+
+	switch dialect {
+	case repository.SQLite:
+		db, err = config.GetDatabase(model.DatabaseConfig{
+			Driver:   string(dialect),
+			Database: filepath.Join(t.TempDir(), "test.db"),
+		})
+	case repository.MySQL:
+		db, err = openDSN(t, dialect, MySQLDSNEnv)
+	case repository.PostgreSQL:
+		db, err = openDSN(t, dialect, PostgreSQLDSNEnv)
+	default:
+		t.Fatalf("unsupported dialect %s", dialect)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	// Start from an empty schema even if a previous run left data behind.
+	if err := migrator.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the suites with a query timeout, as the server does.
+	db.QueryTimeout = 5 * time.Second
+
+	return db
+}
+
+func openDSN(t *testing.T, dialect repository.Dialect, env string) (*repository.Database, error) {
+	dsn := os.Getenv(env)
+	if dsn == "" {
+		t.Skipf("%s is not set", env)
+	}
+
+	return repository.OpenDatabase(dialect, dsn)
+}
+
+// RunSQL runs the repository suites against every SQL dialect.
+func RunSQL(t *testing.T) {
+	for _, dialect := range []repository.Dialect{repository.MySQL, repository.PostgreSQL, repository.SQLite} {
+		dialect := dialect
+
+		t.Run(string(dialect), func(t *testing.T) {
+			Run(t, func(t *testing.T) Repositories {
+				db := OpenDatabase(t, dialect)
+
+				return Repositories{
+					Course:       repository.NewCourseRepository(db),
+					User:         repository.NewUserRepository(db),
+					Enrollment:   repository.NewEnrollmentRepository(db),
+					Progress:     repository.NewProgressRepository(db),
+					Quiz:         repository.NewQuizRepository(db),
+					Assignment:   repository.NewAssignmentRepository(db),
+					Notification: repository.NewNotificationRepository(db),
+					Certificate:  repository.NewCertificateRepository(db),
+					Review:       repository.NewReviewRepository(db),
+					Cart:         repository.NewCartRepository(db),
+					Order:        repository.NewOrderRepository(db),
+					Payment:      repository.NewPaymentRepository(db),
+					Coupon:       repository.NewCouponRepository(db),
+					Transactor:   repository.NewTransactor(db, 3, 10*time.Millisecond),
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+func storeUser(t *testing.T, repo repository.UserRepository, name string, email string) int {
+	t.Helper()
+
+	ctx := context.Background()
+
+	if err := repo.Store(ctx, model.User{Name: name, Email: email, Password: "secret", Role: 2}); err != nil {
+		t.Fatalf("Store(%s): %v", email, err)
+	}
+
+	user, err := repo.FindOne(ctx, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user.Id
+}
+
+func testEnrollment(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Enrollment
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	goID := storeCourse(t, repos.Course, category, "Learn Go", 0)
+	rustID := storeCourse(t, repos.Course, category, "Rust", 100)
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, e := range []model.Enrollment{
+		{UserId: ann, CourseId: goID, CreatedAt: start},
+		{UserId: ann, CourseId: rustID, CreatedAt: start.Add(time.Hour)},
+		{UserId: bob, CourseId: goID, CreatedAt: start.Add(2 * time.Hour)},
+	} {
+		if _, err := repo.Store(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.Store(ctx, model.Enrollment{UserId: ann, CourseId: goID, CreatedAt: start}); err == nil {
+		t.Error("Store accepted a second enrollment of the same user in the same course")
+	}
+
+	enrollment, err := repo.FindOne(ctx, ann, goID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if enrollment.Id == 0 || enrollment.UserId != ann || enrollment.CourseId != goID || !enrollment.CreatedAt.Equal(start) {
+		t.Errorf("FindOne = %+v, want Ann in Learn Go at %s", enrollment, start)
+	}
+
+	if _, err := repo.FindOne(ctx, bob, rustID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing enrollment returned %v, want ErrNotFound", err)
+	}
+
+	course, err := repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 2 || course[0].Id != rustID || course[1].Id != goID || course[1].Name != "Learn Go" {
+		t.Errorf("FetchByUser = %+v, want Rust then Learn Go", course)
+	}
+
+	roster, err := repo.FetchByCourse(ctx, goID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(roster) != 2 || roster[0].UserId != ann || roster[0].Email != "ann@example.com" || roster[1].UserId != bob {
+		t.Errorf("FetchByCourse = %+v, want Ann then Bob", roster)
+	}
+
+	if err := repos.Course.Delete(ctx, rustID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Delete(ctx, ann, goID); err != nil {
+		t.Fatal(err)
+	}
+
+	course, err = repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 0 {
+		t.Errorf("FetchByUser after unenrolling and deleting the courses = %+v, want none", course)
+	}
+}
@@ -0,0 +1,34 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/egaevan/online-learning/repository/memory"
+)
+
+// Memory returns in-memory repositories sharing a fresh store.
+func Memory(t *testing.T) Repositories {
+	store := memory.NewStore()
+
+	return Repositories{
+		Course:       memory.NewCourseRepository(store),
+		User:         memory.NewUserRepository(store),
+		Enrollment:   memory.NewEnrollmentRepository(store),
+		Progress:     memory.NewProgressRepository(store),
+		Quiz:         memory.NewQuizRepository(store),
+		Assignment:   memory.NewAssignmentRepository(store),
+		Notification: memory.NewNotificationRepository(store),
+		Certificate:  memory.NewCertificateRepository(store),
+		Review:       memory.NewReviewRepository(store),
+		Cart:         memory.NewCartRepository(store),
+		Order:        memory.NewOrderRepository(store),
+		Payment:      memory.NewPaymentRepository(store),
+		Coupon:       memory.NewCouponRepository(store),
+		Transactor:   memory.NewTransactor(store),
+	}
+}
+
+// RunMemory runs the repository suites against the in-memory repositories.
+func RunMemory(t *testing.T) {
+	Run(t, Memory)
+}
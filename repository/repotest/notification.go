@@ -0,0 +1,71 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testNotification(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Notification
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	ids := make([]int, 0, 2)
+	for i, subject := range []string{"Essay was graded", "Final project was graded"} {
+		id, err := repo.Store(ctx, model.Notification{UserId: ann, Type: constant.NotificationGrade, Subject: subject, Body: "You scored 9 of 10 points.", Link: "/submission/1", CreatedAt: at.Add(time.Duration(i) * time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	notifications, err := repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifications) != 2 || notifications[0].Id != ids[1] || notifications[1].Subject != "Essay was graded" || notifications[0].ReadAt != nil {
+		t.Fatalf("FetchByUser = %+v, want both unread notifications newest first", notifications)
+	}
+
+	if n := notifications[1]; n.Type != constant.NotificationGrade || n.Body != "You scored 9 of 10 points." || n.Link != "/submission/1" || !n.CreatedAt.Equal(at) {
+		t.Errorf("stored notification = %+v", n)
+	}
+
+	if done, err := repo.MarkRead(ctx, bob, ids[0], at); err != nil || done {
+		t.Errorf("MarkRead of another user = %v, %v, want false", done, err)
+	}
+
+	readAt := at.Add(2 * time.Hour)
+
+	if done, err := repo.MarkRead(ctx, ann, ids[0], readAt); err != nil || !done {
+		t.Fatalf("MarkRead = %v, %v, want true", done, err)
+	}
+
+	if done, err := repo.MarkRead(ctx, ann, ids[0], readAt.Add(time.Hour)); err != nil || done {
+		t.Errorf("second MarkRead = %v, %v, want false", done, err)
+	}
+
+	notifications, err = repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifications) != 2 || notifications[1].ReadAt == nil || !notifications[1].ReadAt.Equal(readAt) || notifications[0].ReadAt != nil {
+		t.Errorf("FetchByUser after MarkRead = %+v, want the first notification read", notifications)
+	}
+
+	notifications, err = repo.FetchByUser(ctx, bob)
+	if err != nil || len(notifications) != 0 {
+		t.Errorf("FetchByUser of a user without notifications = %+v, %v, want none", notifications, err)
+	}
+}
@@ -0,0 +1,185 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testOrder(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	cart := repos.Cart
+	repo := repos.Order
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	golang := storeCourse(t, repos.Course, category, "Go Basics", 1500)
+	rust := storeCourse(t, repos.Course, category, "Rust Basics", 2500)
+	removed := storeCourse(t, repos.Course, category, "Removed", 900)
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Adding a course twice keeps the first time.
+	for i, courseID := range []int{rust, golang, rust, removed} {
+		if err := cart.Add(ctx, ann, courseID, at.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repos.Course.Delete(ctx, removed); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := cart.Fetch(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[0].CourseId != rust || items[0].CourseName != "Rust Basics" || items[0].CategoryId != category || items[0].Price.Amount != 2500 || !items[0].AddedAt.Equal(at) || items[1].CourseId != golang {
+		t.Errorf("Fetch = %+v, want Rust then Go without the deleted course", items)
+	}
+
+	if err := cart.Remove(ctx, ann, rust); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err = cart.Fetch(ctx, ann)
+	if err != nil || len(items) != 1 || items[0].CourseId != golang {
+		t.Errorf("Fetch after Remove = %+v, %v, want Go only", items, err)
+	}
+
+	if err := cart.Clear(ctx, ann); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err = cart.Fetch(ctx, ann)
+	if err != nil || len(items) != 0 {
+		t.Errorf("Fetch after Clear = %+v, %v, want an empty cart", items, err)
+	}
+
+	order := model.Order{
+		UserId:    ann,
+		Status:    constant.OrderPending,
+		Coupon:    "SPRING",
+		Subtotal:  model.Money{Amount: 4000, Currency: "USD"},
+		Discount:  model.Money{Amount: 500, Currency: "USD"},
+		Total:     model.Money{Amount: 3500, Currency: "USD"},
+		CreatedAt: at,
+		UpdatedAt: at,
+		Items: []model.OrderItem{
+			{CourseId: golang, CourseName: "Go Basics", Price: model.Money{Amount: 1500, Currency: "USD"}, Discount: model.Money{Currency: "USD"}},
+			{CourseId: rust, CourseName: "Rust Basics", Price: model.Money{Amount: 2500, Currency: "USD"}, Discount: model.Money{Amount: 500, Currency: "USD"}},
+		},
+	}
+
+	first, err := repo.Store(ctx, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order.Items = order.Items[:1]
+	order.Coupon = ""
+	order.Subtotal.Amount = 1500
+	order.Discount.Amount = 0
+	order.Total.Amount = 1500
+	order.CreatedAt = at.Add(time.Hour)
+
+	second, err := repo.Store(ctx, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.FindOne(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != first || got.UserId != ann || got.Status != constant.OrderPending || got.Coupon != "SPRING" ||
+		got.Subtotal != (model.Money{Amount: 4000, Currency: "USD"}) || got.Discount != (model.Money{Amount: 500, Currency: "USD"}) || got.Total != (model.Money{Amount: 3500, Currency: "USD"}) ||
+		!got.CreatedAt.Equal(at) || got.PaidAt != nil || len(got.Items) != 2 {
+		t.Fatalf("FindOne = %+v, want the pending order of Ann", got)
+	}
+
+	if item := got.Items[1]; item.Id == 0 || item.CourseId != rust || item.CourseName != "Rust Basics" || item.Price != (model.Money{Amount: 2500, Currency: "USD"}) ||
+		item.Discount != (model.Money{Amount: 500, Currency: "USD"}) {
+		t.Errorf("FindOne item = %+v, want the snapshot of Rust Basics", item)
+	}
+
+	if _, err := repo.FindOne(ctx, second+100); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing order returned %v, want ErrNotFound", err)
+	}
+
+	orders, err := repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 2 || orders[0].Id != second || len(orders[0].Items) != 1 || orders[1].Id != first || len(orders[1].Items) != 2 {
+		t.Errorf("FetchByUser = %+v, want both orders newest first with their items", orders)
+	}
+
+	orders, err = repo.FetchByUser(ctx, bob)
+	if err != nil || len(orders) != 0 {
+		t.Errorf("FetchByUser of another user = %+v, %v, want no orders", orders, err)
+	}
+
+	paid, err := repo.HasPaidCourse(ctx, ann, rust)
+	if err != nil || paid {
+		t.Errorf("HasPaidCourse before payment = %v, %v, want false", paid, err)
+	}
+
+	paidAt := at.Add(2 * time.Hour)
+
+	done, err := repo.UpdateStatus(ctx, first, constant.OrderPending, constant.OrderPaid, paidAt)
+	if err != nil || !done {
+		t.Fatalf("UpdateStatus to paid = %v, %v, want done", done, err)
+	}
+
+	// The order is not pending anymore.
+	done, err = repo.UpdateStatus(ctx, first, constant.OrderPending, constant.OrderFailed, paidAt)
+	if err != nil || done {
+		t.Errorf("UpdateStatus from a stale status = %v, %v, want nothing done", done, err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.Status != constant.OrderPaid || got.PaidAt == nil || !got.PaidAt.Equal(paidAt) || !got.UpdatedAt.Equal(paidAt) {
+		t.Errorf("FindOne after payment = %+v, %v, want paid at %v", got, err, paidAt)
+	}
+
+	for _, c := range []struct {
+		user, course int
+		want         bool
+	}{
+		{ann, rust, true},
+		{ann, golang, true},
+		{bob, rust, false},
+		{ann, removed, false},
+	} {
+		paid, err := repo.HasPaidCourse(ctx, c.user, c.course)
+		if err != nil || paid != c.want {
+			t.Errorf("HasPaidCourse(%d, %d) = %v, %v, want %v", c.user, c.course, paid, err, c.want)
+		}
+	}
+
+	refundedAt := paidAt.Add(time.Hour)
+
+	if done, err := repo.UpdateStatus(ctx, first, constant.OrderPaid, constant.OrderRefunded, refundedAt); err != nil || !done {
+		t.Fatalf("UpdateStatus to refunded = %v, %v, want done", done, err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.Status != constant.OrderRefunded || got.PaidAt == nil || !got.PaidAt.Equal(paidAt) || !got.UpdatedAt.Equal(refundedAt) {
+		t.Errorf("FindOne after refund = %+v, %v, want refunded keeping the payment time", got, err)
+	}
+
+	paid, err = repo.HasPaidCourse(ctx, ann, rust)
+	if err != nil || paid {
+		t.Errorf("HasPaidCourse after refund = %v, %v, want false", paid, err)
+	}
+}
@@ -0,0 +1,113 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testPayment(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Payment
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	golang := storeCourse(t, repos.Course, category, "Go Basics", 1500)
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	amount := model.Money{Amount: 1500, Currency: "USD"}
+
+	orderID, err := repos.Order.Store(ctx, model.Order{
+		UserId:    ann,
+		Status:    constant.OrderPending,
+		Total:     amount,
+		CreatedAt: at,
+		UpdatedAt: at,
+		Items:     []model.OrderItem{{CourseId: golang, CourseName: "Go Basics", Price: amount}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, id := range []string{"pi_first", "pi_second"} {
+		err := repo.Store(ctx, model.Payment{
+			Id:        id,
+			OrderId:   orderID,
+			Amount:    amount,
+			Status:    constant.PaymentCreated,
+			CreatedAt: at.Add(time.Duration(i) * time.Minute),
+			UpdatedAt: at.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := repo.FindOne(ctx, "pi_first")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != "pi_first" || got.OrderId != orderID || got.Amount != amount || got.Status != constant.PaymentCreated || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindOne = %+v, want the created payment of the order", got)
+	}
+
+	if _, err := repo.FindOne(ctx, "pi_missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing payment returned %v, want ErrNotFound", err)
+	}
+
+	payments, err := repo.FetchByOrder(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payments) != 2 || payments[0].Id != "pi_second" || payments[1].Id != "pi_first" {
+		t.Errorf("FetchByOrder = %+v, want both payments newest first", payments)
+	}
+
+	payments, err = repo.FetchByOrder(ctx, orderID+1)
+	if err != nil || len(payments) != 0 {
+		t.Errorf("FetchByOrder of another order = %+v, %v, want no payments", payments, err)
+	}
+
+	updatedAt := at.Add(time.Hour)
+
+	if err := repo.UpdateStatus(ctx, "pi_first", constant.PaymentSucceeded, updatedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, "pi_first")
+	if err != nil || got.Status != constant.PaymentSucceeded || !got.UpdatedAt.Equal(updatedAt) || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindOne after UpdateStatus = %+v, %v, want succeeded at %v", got, err, updatedAt)
+	}
+
+	event := model.PaymentEvent{
+		Id:        "evt_1",
+		Type:      constant.EventPaymentSucceeded,
+		PaymentId: "pi_first",
+		Amount:    amount,
+		CreatedAt: at,
+	}
+
+	stored, err := repo.StoreEvent(ctx, event, updatedAt)
+	if err != nil || !stored {
+		t.Fatalf("StoreEvent = %v, %v, want stored", stored, err)
+	}
+
+	// Providers send an event again until it is accepted.
+	stored, err = repo.StoreEvent(ctx, event, updatedAt.Add(time.Minute))
+	if err != nil || stored {
+		t.Errorf("StoreEvent of a known event = %v, %v, want not stored", stored, err)
+	}
+
+	event.Id = "evt_2"
+
+	stored, err = repo.StoreEvent(ctx, event, updatedAt)
+	if err != nil || !stored {
+		t.Errorf("StoreEvent of another event = %v, %v, want stored", stored, err)
+	}
+}
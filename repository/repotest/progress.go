@@ -0,0 +1,124 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testProgress(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Progress
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	courseID := storeCourse(t, repos.Course, category, "Learn Go", 0)
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+
+	sectionID, err := repos.Course.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Intro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lessons := make([]int, 0, 3)
+	for _, l := range []model.Lesson{
+		{SectionId: sectionID, Title: "Welcome", Type: constant.LessonText, Content: "Hello"},
+		{SectionId: sectionID, Title: "Setup", Type: constant.LessonText, Content: "# Setup"},
+		{SectionId: sectionID, Title: "Extras", Type: constant.LessonText, Content: "More", Optional: true},
+	} {
+		id, err := repos.Course.StoreLesson(ctx, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lessons = append(lessons, id)
+	}
+
+	if lesson, err := repos.Course.FindLesson(ctx, lessons[2]); err != nil || !lesson.Optional {
+		t.Errorf("FindLesson of an optional lesson = %+v, %v", lesson, err)
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, err := repos.Enrollment.Store(ctx, model.Enrollment{UserId: ann, CourseId: courseID, CreatedAt: start}); err != nil {
+		t.Fatal(err)
+	}
+
+	save := func(lessonID int, position int, completed bool, at time.Time) {
+		t.Helper()
+
+		p := model.LessonProgress{UserId: ann, LessonId: lessonID, CourseId: courseID, Position: position, StartedAt: at, UpdatedAt: at}
+		if completed {
+			p.CompletedAt = &at
+		}
+
+		if err := repo.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	save(lessons[0], 10, false, start)
+	save(lessons[0], 10, false, start)
+	save(lessons[0], 30, true, start.Add(time.Minute))
+	save(lessons[0], 5, false, start.Add(2*time.Minute))
+	save(lessons[2], 0, true, start.Add(3*time.Minute))
+
+	progress, err := repo.FetchByCourse(ctx, ann, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(progress) != 2 || progress[0].LessonId != lessons[2] || progress[1].LessonId != lessons[0] {
+		t.Fatalf("FetchByCourse = %+v, want Extras then Welcome", progress)
+	}
+
+	welcome := progress[1]
+	if welcome.Position != 5 || !welcome.StartedAt.Equal(start) || !welcome.UpdatedAt.Equal(start.Add(2*time.Minute)) ||
+		welcome.CompletedAt == nil || !welcome.CompletedAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("progress of Welcome = %+v, want position 5, started at the first write and still completed", welcome)
+	}
+
+	summary, err := repo.Summary(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(summary) != 1 || summary[0].CourseId != courseID || summary[0].RequiredLessons != 2 || summary[0].CompletedLessons != 1 || summary[0].CompletedAt != nil {
+		t.Errorf("Summary = %+v, want 1 of 2 required lessons of Learn Go", summary)
+	}
+
+	finish := start.Add(time.Hour)
+
+	done, err := repos.Enrollment.Complete(ctx, ann, courseID, finish)
+	if err != nil || !done {
+		t.Fatalf("Complete = %v, %v, want true", done, err)
+	}
+
+	if done, err := repos.Enrollment.Complete(ctx, ann, courseID, finish.Add(time.Hour)); err != nil || done {
+		t.Errorf("second Complete = %v, %v, want false", done, err)
+	}
+
+	enrollment, err := repos.Enrollment.FindOne(ctx, ann, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if enrollment.CompletedAt == nil || !enrollment.CompletedAt.Equal(finish) {
+		t.Errorf("FindOne after Complete = %+v, want completed at %s", enrollment, finish)
+	}
+
+	if err := repos.Course.DeleteLesson(ctx, lessons[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err = repo.FetchByUser(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(progress) != 1 || progress[0].LessonId != lessons[0] {
+		t.Errorf("FetchByUser after deleting Extras = %+v, want Welcome only", progress)
+	}
+}
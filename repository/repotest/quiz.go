@@ -0,0 +1,185 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testQuiz(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Quiz
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	courseID := storeCourse(t, repos.Course, category, "Learn Go", 0)
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+
+	sectionID, err := repos.Course.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Intro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lessonID, err := repos.Course.StoreLesson(ctx, model.Lesson{SectionId: sectionID, Title: "Check", Type: constant.LessonQuiz, Content: "Ten minutes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindQuiz(ctx, lessonID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindQuiz before SaveQuiz returned %v, want ErrNotFound", err)
+	}
+
+	if err := repo.SaveQuiz(ctx, model.Quiz{LessonId: lessonID, TimeLimit: 600, MaxAttempts: 3, PassScore: 50, ScorePolicy: constant.ScoreBest}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SaveQuiz(ctx, model.Quiz{LessonId: lessonID, TimeLimit: 300, MaxAttempts: 2, PassScore: 80, ScorePolicy: constant.ScoreLatest}); err != nil {
+		t.Fatal(err)
+	}
+
+	truth, number := true, 3.14
+
+	questions := make([]int, 0, 3)
+	for _, q := range []model.Question{
+		{LessonId: lessonID, Type: constant.QuestionSingleChoice, Prompt: "Keyword for a loop?", Options: []string{"for", "while"}, Answer: &model.AnswerKey{Choices: []int{0}}, Points: 1},
+		{LessonId: lessonID, Type: constant.QuestionTrueFalse, Prompt: "Go has generics.", Answer: &model.AnswerKey{Truth: &truth}, Points: 1, Feedback: "Since Go 1.18."},
+		{LessonId: lessonID, Type: constant.QuestionNumeric, Prompt: "Pi?", Answer: &model.AnswerKey{Number: &number, Tolerance: 0.01}, Points: 2},
+	} {
+		id, err := repo.StoreQuestion(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		questions = append(questions, id)
+	}
+
+	quiz, err := repo.FindQuiz(ctx, lessonID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quiz.LessonId != lessonID || quiz.CourseId != courseID || quiz.TimeLimit != 300 || quiz.MaxAttempts != 2 || quiz.PassScore != 80 || quiz.ScorePolicy != constant.ScoreLatest {
+		t.Errorf("FindQuiz = %+v, want the settings of the second SaveQuiz", quiz)
+	}
+
+	if len(quiz.Questions) != 3 || quiz.Questions[0].Id != questions[0] || quiz.Questions[2].Position != 3 {
+		t.Fatalf("questions of FindQuiz = %+v, want the 3 questions in order", quiz.Questions)
+	}
+
+	single := quiz.Questions[0]
+	if len(single.Options) != 2 || single.Options[1] != "while" || single.Answer == nil || len(single.Answer.Choices) != 1 || single.Answer.Choices[0] != 0 {
+		t.Errorf("single choice question = %+v, want its options and answer key", single)
+	}
+
+	if key := quiz.Questions[2].Answer; key == nil || key.Number == nil || *key.Number != 3.14 || key.Tolerance != 0.01 {
+		t.Errorf("answer key of the numeric question = %+v", key)
+	}
+
+	single.Prompt = "Loop keyword?"
+	single.Options = []string{"loop", "for", "while"}
+	single.Answer = &model.AnswerKey{Choices: []int{1}}
+	single.Points = 3
+
+	if err := repo.UpdateQuestion(ctx, single); err != nil {
+		t.Fatal(err)
+	}
+
+	question, err := repo.FindQuestion(ctx, questions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if question.Prompt != "Loop keyword?" || len(question.Options) != 3 || question.Answer.Choices[0] != 1 || question.Points != 3 {
+		t.Errorf("FindQuestion after UpdateQuestion = %+v", question)
+	}
+
+	if err := repo.ReorderQuestion(ctx, lessonID, []int{questions[2], questions[0], questions[1]}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.DeleteQuestion(ctx, questions[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindQuestion(ctx, questions[1]); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindQuestion of a deleted question returned %v, want ErrNotFound", err)
+	}
+
+	quiz, err = repo.FindQuiz(ctx, lessonID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(quiz.Questions) != 2 || quiz.Questions[0].Id != questions[2] || quiz.Questions[1].Id != questions[0] {
+		t.Errorf("questions after reordering and deleting = %+v, want Pi then the loop question", quiz.Questions)
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := start.Add(5 * time.Minute)
+
+	first, err := repo.StoreAttempt(ctx, model.QuizAttempt{UserId: ann, LessonId: lessonID, CourseId: courseID, Number: 1, StartedAt: start, ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := repo.StoreAttempt(ctx, model.QuizAttempt{UserId: ann, LessonId: lessonID, CourseId: courseID, Number: 2, StartedAt: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent starts can't take the same attempt number.
+	_, err = repo.StoreAttempt(ctx, model.QuizAttempt{UserId: ann, LessonId: lessonID, CourseId: courseID, Number: 2, StartedAt: start.Add(time.Hour)})
+	if !errors.Is(err, model.ErrConflict) {
+		t.Errorf("StoreAttempt of a taken number returned %v, want ErrConflict", err)
+	}
+
+	attempt, err := repo.FindAttempt(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempt.UserId != ann || attempt.CourseId != courseID || attempt.Number != 1 || !attempt.StartedAt.Equal(start) || attempt.ExpiresAt == nil ||
+		!attempt.ExpiresAt.Equal(expiresAt) || attempt.SubmittedAt != nil || attempt.Responses != nil || attempt.Results != nil {
+		t.Errorf("FindAttempt = %+v, want the started attempt", attempt)
+	}
+
+	submittedAt := start.Add(4 * time.Minute)
+	attempt.SubmittedAt = &submittedAt
+	attempt.Score, attempt.MaxScore, attempt.Percentage, attempt.Passed = 2, 5, 40, false
+	attempt.Responses = []model.Response{{QuestionId: questions[2], Number: &number}}
+	attempt.Results = []model.QuestionResult{
+		{QuestionId: questions[2], Correct: true, Points: 2, MaxPoints: 2},
+		{QuestionId: questions[0], MaxPoints: 3, Feedback: "Go only has for."},
+	}
+
+	done, err := repo.SubmitAttempt(ctx, *attempt)
+	if err != nil || !done {
+		t.Fatalf("SubmitAttempt = %v, %v, want true", done, err)
+	}
+
+	if done, err := repo.SubmitAttempt(ctx, model.QuizAttempt{Id: first, SubmittedAt: &submittedAt, Score: 5}); err != nil || done {
+		t.Errorf("second SubmitAttempt = %v, %v, want false", done, err)
+	}
+
+	if _, err := repo.FindAttempt(ctx, second+1000); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindAttempt of a missing attempt returned %v, want ErrNotFound", err)
+	}
+
+	attempts, err := repo.FetchAttempt(ctx, ann, lessonID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attempts) != 2 || attempts[0].Id != first || attempts[1].Id != second || attempts[1].ExpiresAt != nil {
+		t.Fatalf("FetchAttempt = %+v, want both attempts oldest first", attempts)
+	}
+
+	graded := attempts[0]
+	if graded.SubmittedAt == nil || !graded.SubmittedAt.Equal(submittedAt) || graded.Score != 2 || graded.MaxScore != 5 || graded.Percentage != 40 || graded.Passed ||
+		len(graded.Responses) != 1 || *graded.Responses[0].Number != 3.14 || len(graded.Results) != 2 || graded.Results[1].Feedback != "Go only has for." {
+		t.Errorf("submitted attempt = %+v, want the first grade", graded)
+	}
+}
@@ -0,0 +1,212 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func testReview(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Review
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	golang := storeCourse(t, repos.Course, category, "Go Basics", 0)
+	rust := storeCourse(t, repos.Course, category, "Rust Basics", 0)
+
+	ann := storeUser(t, repos.User, "Ann", "ann@example.com")
+	bob := storeUser(t, repos.User, "Bob", "bob@example.com")
+	eve := storeUser(t, repos.User, "Eve", "eve@example.com")
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	store := func(userID, courseID, rating int, created time.Time) int {
+		t.Helper()
+
+		id, err := repo.Store(ctx, model.Review{CourseId: courseID, UserId: userID, Rating: rating, Body: "Review", CreatedAt: created, UpdatedAt: created})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return id
+	}
+
+	first := store(ann, golang, 5, at)
+	second := store(bob, golang, 2, at.Add(time.Hour))
+	store(ann, rust, 4, at)
+
+	if _, err := repo.Store(ctx, model.Review{CourseId: golang, UserId: ann, Rating: 1, CreatedAt: at, UpdatedAt: at}); err == nil {
+		t.Error("Store accepted a second review of the user for the course")
+	}
+
+	got, err := repo.FindOne(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Id != first || got.CourseId != golang || got.UserId != ann || got.UserName != "Ann" || got.Rating != 5 || got.Body != "Review" || got.Hidden || got.Reply != nil || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindOne = %+v, want the review of Ann", got)
+	}
+
+	if _, err := repo.FindOne(ctx, first+100); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing review returned %v, want ErrNotFound", err)
+	}
+
+	got, err = repo.FindByUser(ctx, bob, golang)
+	if err != nil || got.Id != second {
+		t.Errorf("FindByUser = %+v, %v, want the review of Bob", got, err)
+	}
+
+	if _, err := repo.FindByUser(ctx, bob, rust); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByUser without review returned %v, want ErrNotFound", err)
+	}
+
+	edited := at.Add(2 * time.Hour)
+	if err := repo.Update(ctx, model.Review{Id: first, Rating: 4, Body: "Edited", UpdatedAt: edited}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.Rating != 4 || got.Body != "Edited" || !got.UpdatedAt.Equal(edited) || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindOne after Update = %+v, %v, want the edited review", got, err)
+	}
+
+	// Voting twice counts once.
+	for _, userID := range []int{bob, eve, eve} {
+		if err := repo.Vote(ctx, first, userID, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Unvote(ctx, first, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.HelpfulCount != 1 {
+		t.Errorf("FindOne after voting = %+v, %v, want 1 helpful vote", got, err)
+	}
+
+	for _, flag := range []model.ReviewFlag{
+		{ReviewId: second, UserId: ann, Reason: "Spam", CreatedAt: at},
+		{ReviewId: second, UserId: ann, Reason: "Offensive", CreatedAt: at},
+		{ReviewId: second, UserId: eve, Reason: "Spam", CreatedAt: at},
+		{ReviewId: first, UserId: bob, Reason: "Spam", CreatedAt: at},
+	} {
+		if err := repo.Flag(ctx, flag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	flagged, err := repo.FetchFlagged(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flagged) != 2 || flagged[0].Id != second || flagged[0].FlagCount != 2 || flagged[1].Id != first || flagged[1].FlagCount != 1 {
+		t.Errorf("FetchFlagged = %+v, want the review of Bob with 2 flags first", flagged)
+	}
+
+	if err := repo.RecomputeRating(ctx, golang); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := repos.Course.FindOne(ctx, golang)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if detail.Rating.Average != 3 || detail.Rating.Count != 2 {
+		t.Errorf("course rating = %+v, want 3 from 2 reviews", detail.Rating)
+	}
+
+	distribution, err := repo.Distribution(ctx, golang)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[int]int{1: 0, 2: 1, 3: 0, 4: 1, 5: 0}; !reflect.DeepEqual(distribution, want) {
+		t.Errorf("Distribution = %v, want %v", distribution, want)
+	}
+
+	// Hiding the review resolves its flags and takes it out of the rating.
+	if err := repo.SetHidden(ctx, second, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.RecomputeRating(ctx, golang); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, second)
+	if err != nil || !got.Hidden || got.FlagCount != 0 {
+		t.Errorf("FindOne after SetHidden = %+v, %v, want hidden without flags", got, err)
+	}
+
+	detail, err = repos.Course.FindOne(ctx, golang)
+	if err != nil || detail.Rating.Average != 4 || detail.Rating.Count != 1 {
+		t.Errorf("course rating after hiding = %+v, %v, want 4 from 1 review", detail.Rating, err)
+	}
+
+	distribution, err = repo.Distribution(ctx, golang)
+	if err != nil || distribution[2] != 0 || distribution[4] != 1 {
+		t.Errorf("Distribution after hiding = %v, %v, want the hidden review left out", distribution, err)
+	}
+
+	reviews, err := repo.FetchByCourse(ctx, golang)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reviews) != 2 || reviews[0].Id != second || reviews[1].Id != first {
+		t.Errorf("FetchByCourse = %+v, want both reviews newest first", reviews)
+	}
+
+	if err := repo.RecomputeRating(ctx, rust); err != nil {
+		t.Fatal(err)
+	}
+
+	course, err := repos.Course.Sort(ctx, constant.SortRating)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalNames(course, "Go Basics", "Rust Basics") || course[0].RatingAverage != 4 || course[0].RatingCount != 1 {
+		t.Errorf("Sort rating returned %+v, want the courses with their rating", course)
+	}
+
+	reply := model.ReviewReply{Body: "Thanks", RepliedBy: eve, RepliedAt: at}
+	if err := repo.SaveReply(ctx, first, &reply); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.Reply == nil || got.Reply.Body != "Thanks" || got.Reply.RepliedBy != eve || !got.Reply.RepliedAt.Equal(at) {
+		t.Errorf("FindOne after SaveReply = %+v, %v, want the reply", got, err)
+	}
+
+	if err := repo.SaveReply(ctx, first, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = repo.FindOne(ctx, first)
+	if err != nil || got.Reply != nil {
+		t.Errorf("FindOne after clearing the reply = %+v, %v, want no reply", got, err)
+	}
+
+	if err := repo.Delete(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindOne(ctx, first); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a deleted review returned %v, want ErrNotFound", err)
+	}
+
+	// The user can review the course again.
+	store(ann, golang, 3, edited)
+}
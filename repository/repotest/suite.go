@@ -0,0 +1,528 @@
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Repositories is one set of repositories sharing the same storage.
+type Repositories struct {
+	Course       repository.CourseRepository
+	User         repository.UserRepository
+	Enrollment   repository.EnrollmentRepository
+	Progress     repository.ProgressRepository
+	Quiz         repository.QuizRepository
+	Assignment   repository.AssignmentRepository
+	Notification repository.NotificationRepository
+	Certificate  repository.CertificateRepository
+	Review       repository.ReviewRepository
+	Cart         repository.CartRepository
+	Order        repository.OrderRepository
+	Payment      repository.PaymentRepository
+	Coupon       repository.CouponRepository
+	Transactor   repository.Transactor
+}
+
+// Factory returns repositories backed by fresh, empty storage.
+type Factory func(t *testing.T) Repositories
+
+// Run runs every repository suite with repositories from newRepositories.
+func Run(t *testing.T, newRepositories Factory) {
+	t.Run("Category", func(t *testing.T) {
+		testCategory(t, newRepositories(t))
+	})
+
+	t.Run("Course", func(t *testing.T) {
+		testCourse(t, newRepositories(t))
+	})
+
+	t.Run("Curriculum", func(t *testing.T) {
+		testCurriculum(t, newRepositories(t))
+	})
+
+	t.Run("Enrollment", func(t *testing.T) {
+		testEnrollment(t, newRepositories(t))
+	})
+
+	t.Run("Progress", func(t *testing.T) {
+		testProgress(t, newRepositories(t))
+	})
+
+	t.Run("Quiz", func(t *testing.T) {
+		testQuiz(t, newRepositories(t))
+	})
+
+	t.Run("Assignment", func(t *testing.T) {
+		testAssignment(t, newRepositories(t))
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		testNotification(t, newRepositories(t))
+	})
+
+	t.Run("Certificate", func(t *testing.T) {
+		testCertificate(t, newRepositories(t))
+	})
+
+	t.Run("Review", func(t *testing.T) {
+		testReview(t, newRepositories(t))
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		testOrder(t, newRepositories(t))
+	})
+
+	t.Run("Payment", func(t *testing.T) {
+		testPayment(t, newRepositories(t))
+	})
+
+	t.Run("Coupon", func(t *testing.T) {
+		testCoupon(t, newRepositories(t))
+	})
+
+	t.Run("Statistic", func(t *testing.T) {
+		testStatistic(t, newRepositories(t))
+	})
+
+	t.Run("User", func(t *testing.T) {
+		testUser(t, newRepositories(t))
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		testTransaction(t, newRepositories(t))
+	})
+}
+
+func storeCategory(t *testing.T, repo repository.CourseRepository, name string, parentID int) int {
+	t.Helper()
+
+	id, err := repo.StoreCategory(context.Background(), model.Category{Name: name, ParentId: parentID})
+	if err != nil {
+		t.Fatalf("StoreCategory(%s): %v", name, err)
+	}
+
+	return id
+}
+
+func storeCourse(t *testing.T, repo repository.CourseRepository, categoryID int, name string, price int) int {
+	t.Helper()
+
+	id, err := repo.Store(context.Background(), model.Course{CategoryId: categoryID, Name: name, Price: price})
+	if err != nil {
+		t.Fatalf("Store(%s): %v", name, err)
+	}
+
+	return id
+}
+
+func courseNames(course []model.Course) []string {
+	result := make([]string, 0, len(course))
+	for _, c := range course {
+		result = append(result, c.Name)
+	}
+
+	return result
+}
+
+func equalNames(got []model.Course, want ...string) bool {
+	names := courseNames(got)
+	if len(names) != len(want) {
+		return false
+	}
+
+	for i := range names {
+		if names[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testCategory(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Course
+
+	programming := storeCategory(t, repo, "Programming", 0)
+	golang := storeCategory(t, repo, "Go", programming)
+	design := storeCategory(t, repo, "Design", 0)
+
+	storeCourse(t, repo, golang, "Concurrency in Go", 100)
+	storeCourse(t, repo, golang, "Go Basics", 0)
+	removed := storeCourse(t, repo, programming, "Removed", 0)
+
+	if err := repo.Delete(ctx, removed); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := repo.FetchCategoryNode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 3 {
+		t.Fatalf("FetchCategoryNode returned %d nodes, want 3", len(nodes))
+	}
+
+	for _, n := range nodes {
+		switch n.Id {
+		case programming:
+			if n.ParentId != 0 || n.CourseCount != 0 {
+				t.Errorf("Programming node = %+v, want root without active courses", n)
+			}
+		case golang:
+			if n.ParentId != programming || n.CourseCount != 2 {
+				t.Errorf("Go node = %+v, want child of %d with 2 courses", n, programming)
+			}
+		case design:
+			if n.ParentId != 0 || n.CourseCount != 0 {
+				t.Errorf("Design node = %+v, want empty root", n)
+			}
+		default:
+			t.Errorf("unexpected node %+v", n)
+		}
+	}
+
+	course, err := repo.FetchByCategory(ctx, []int{programming, golang})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 2 {
+		t.Errorf("FetchByCategory returned %v, want the two active Go courses", courseNames(course))
+	}
+
+	course, err = repo.FetchByCategory(ctx, nil)
+	if err != nil || len(course) != 0 {
+		t.Errorf("FetchByCategory(nil) = %v, %v, want empty result", course, err)
+	}
+
+	if err := repo.RecomputeCount(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	category, err := repo.FetchCategory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range category {
+		if c.Id == golang && c.CourseCount != 2 {
+			t.Errorf("Go course count = %d after RecomputeCount, want 2", c.CourseCount)
+		}
+
+		if c.Id == programming && c.CourseCount != 0 {
+			t.Errorf("Programming course count = %d after RecomputeCount, want 0", c.CourseCount)
+		}
+	}
+
+	storeCourse(t, repo, design, "Figma Basics", 900)
+	storeCourse(t, repo, golang, "Go Testing", 900)
+
+	// Only the listed categories are counted again.
+	if err := repo.RecomputeCategoryCount(ctx, []int{design}); err != nil {
+		t.Fatal(err)
+	}
+
+	category, err = repo.FetchCategory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range category {
+		if c.Id == design && c.CourseCount != 1 {
+			t.Errorf("Design course count = %d after RecomputeCategoryCount, want 1", c.CourseCount)
+		}
+
+		if c.Id == golang && c.CourseCount != 2 {
+			t.Errorf("Go course count = %d after RecomputeCategoryCount of Design, want 2", c.CourseCount)
+		}
+	}
+
+	popular, err := repo.FetchPopularCategory(ctx, 2, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(popular) != 2 {
+		t.Errorf("FetchPopularCategory returned %d categories, want the limit of 2", len(popular))
+	}
+}
+
+func testCourse(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Course
+
+	category := storeCategory(t, repo, "Programming", 0)
+	other := storeCategory(t, repo, "Design", 0)
+
+	goID := storeCourse(t, repo, category, "Learn Go", 300)
+	storeCourse(t, repo, category, "Free Golang Tour", 0)
+	storeCourse(t, repo, category, "Rust", 200)
+
+	detail, err := repo.FindOne(ctx, goID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if detail.Id != goID || detail.Name != "Learn Go" || detail.Price != 300 || detail.Category.Id != category || detail.Category.Name != "Programming" {
+		t.Errorf("FindOne = %+v, want Learn Go in Programming", detail)
+	}
+
+	if _, err := repo.FindOne(ctx, goID+1000); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing course returned %v, want ErrNotFound", err)
+	}
+
+	course, err := repo.Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 3 {
+		t.Errorf("Fetch returned %v, want 3 courses", courseNames(course))
+	}
+
+	course, err = repo.Search(ctx, "GO")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 2 {
+		t.Errorf("Search(GO) returned %v, want the two Go courses", courseNames(course))
+	}
+
+	course, err = repo.Search(ctx, "%' OR 1=1 --")
+	if err != nil || len(course) != 0 {
+		t.Errorf("Search with SQL in the term = %v, %v, want empty result", courseNames(course), err)
+	}
+
+	course, err = repo.Sort(ctx, constant.SortLowPrice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalNames(course, "Free Golang Tour", "Rust", "Learn Go") {
+		t.Errorf("Sort low returned %v", courseNames(course))
+	}
+
+	course, err = repo.Sort(ctx, constant.SortHighPrice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalNames(course, "Learn Go", "Rust", "Free Golang Tour") {
+		t.Errorf("Sort high returned %v", courseNames(course))
+	}
+
+	course, err = repo.Sort(ctx, constant.SortFree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalNames(course, "Free Golang Tour") {
+		t.Errorf("Sort free returned %v", courseNames(course))
+	}
+
+	if _, err := repo.Sort(ctx, "price; DROP TABLE course"); !errors.Is(err, model.ErrBadParamInput) {
+		t.Errorf("Sort with an unknown key returned %v, want ErrBadParamInput", err)
+	}
+
+	version := detail.Version
+
+	err = repo.Update(ctx, model.CourseUpdate{CategoryId: other, Name: "Learn Go Fast", Price: 350, Version: version}, goID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err = repo.FindOne(ctx, goID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if detail.Name != "Learn Go Fast" || detail.Price != 350 || detail.Category.Id != other || detail.Version != version+1 {
+		t.Errorf("FindOne after Update = %+v, want the new values at version %d", detail, version+1)
+	}
+
+	err = repo.Update(ctx, model.CourseUpdate{CategoryId: other, Name: "Stale", Price: 1, Version: version}, goID)
+	if !errors.Is(err, model.ErrConflict) {
+		t.Errorf("Update with a stale version returned %v, want ErrConflict", err)
+	}
+
+	if err := repo.Delete(ctx, goID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindOne(ctx, goID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a deleted course returned %v, want ErrNotFound", err)
+	}
+
+	course, err = repo.Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(course) != 2 {
+		t.Errorf("Fetch after Delete returned %v, want 2 courses", courseNames(course))
+	}
+}
+
+func testStatistic(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	category := storeCategory(t, repos.Course, "Programming", 0)
+	storeCourse(t, repos.Course, category, "Paid", 100)
+	storeCourse(t, repos.Course, category, "Free", 0)
+
+	for _, u := range []model.User{
+		{Name: "Admin", Email: "admin@example.com", Password: "secret", Role: 1},
+		{Name: "Student", Email: "student@example.com", Password: "secret", Role: 2},
+	} {
+		if err := repos.User.Store(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stat, err := repos.Course.Statistic(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := model.StatisticResponse{TotalUser: 1, TotalCourse: 2, TotalCourseFree: 1}
+	if *stat != want {
+		t.Errorf("Statistic = %+v, want %+v", *stat, want)
+	}
+}
+
+func testUser(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.User
+
+	err := repo.Store(ctx, model.User{Name: "Student", Email: "student@example.com", Password: "secret", Phone: 812345678, Role: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Store(ctx, model.User{Name: "Copy", Email: "student@example.com", Password: "secret"}); err == nil {
+		t.Error("Store accepted a duplicate email")
+	}
+
+	user, err := repo.FindOne(ctx, "student@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Id == 0 || user.Name != "Student" || user.Phone != 812345678 || user.Role != 2 {
+		t.Errorf("FindOne = %+v", user)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("secret")) != nil {
+		t.Error("FindOne did not return the bcrypt hash of the password")
+	}
+
+	byID, err := repo.FindByID(ctx, user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if byID.Id != user.Id || byID.Name != "Student" || byID.Email != "student@example.com" || byID.Role != 2 || byID.Password != "" {
+		t.Errorf("FindByID = %+v, want the user without password", byID)
+	}
+
+	if _, err := repo.FindByID(ctx, user.Id+100); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByID of a missing user returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.FindOne(ctx, "missing@example.com"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a missing user returned %v, want ErrNotFound", err)
+	}
+
+	if err := repo.Delete(ctx, user.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindOne(ctx, "student@example.com"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindOne of a deleted user returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.FindByID(ctx, user.Id); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("FindByID of a deleted user returned %v, want ErrNotFound", err)
+	}
+}
+
+func testTransaction(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	err := repos.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := repos.Course.StoreCategory(ctx, model.Category{Name: "Rolled back"}); err != nil {
+			return err
+		}
+
+		// A nested unit of work joins the outer transaction.
+		return repos.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if _, err := repos.Course.StoreCategory(ctx, model.Category{Name: "Nested"}); err != nil {
+				return err
+			}
+
+			return errAbort
+		})
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTransaction returned %v, want the error of fn", err)
+	}
+
+	nodes, err := repos.Course.FetchCategoryNode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("rolled back transaction left %d categories behind", len(nodes))
+	}
+
+	var id int
+	err = repos.Transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		id, err = repos.Course.StoreCategory(ctx, model.Category{Name: "Committed"})
+		if err != nil {
+			return err
+		}
+
+		// Reads inside the transaction see its own writes.
+		nodes, err := repos.Course.FetchCategoryNode(ctx)
+		if err == nil && len(nodes) != 1 {
+			t.Errorf("FetchCategoryNode inside the transaction returned %d categories, want 1", len(nodes))
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err = repos.Course.FetchCategoryNode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 1 || nodes[0].Id != id {
+		t.Errorf("committed transaction left %+v, want the committed category", nodes)
+	}
+	// A read only transaction sees what was committed before it.
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+	err = repos.Transactor.WithinTransactionOptions(ctx, opts, func(ctx context.Context) (err error) {
+		nodes, err = repos.Course.FetchCategoryNode(ctx)
+		return err
+	})
+	if err != nil || len(nodes) != 1 || nodes[0].Id != id {
+		t.Errorf("read only transaction read %+v, %v, want the committed category", nodes, err)
+	}
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Review struct {
+	DB *Database
+}
+
+func NewReviewRepository(db *Database) ReviewRepository {
+	return &Review{
+		DB: db,
+	}
+}
+
+// voteQuery records a helpfulness vote, voting again changes nothing.
+var voteQuery = map[Dialect]string{
+	MySQL: `
+				INSERT IGNORE INTO review_vote
+					(review_id, user_id, created_at)
+				VALUES
+					(?, ?, ?)
+			`,
+	PostgreSQL: voteOnConflict,
+	SQLite:     voteOnConflict,
+}
+
+const voteOnConflict = `
+				INSERT INTO review_vote
+					(review_id, user_id, created_at)
+				VALUES
+					(?, ?, ?)
+				ON CONFLICT (review_id, user_id) DO NOTHING
+			`
+
+// flagQuery records a flag or replaces the reason of the flag the user
+// raised before.
+var flagQuery = map[Dialect]string{
+	MySQL: `
+				INSERT INTO review_flag
+					(review_id, user_id, reason, created_at)
+				VALUES
+					(?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					reason = VALUES(reason),
+					created_at = VALUES(created_at)
+			`,
+	PostgreSQL: flagOnConflict,
+	SQLite:     flagOnConflict,
+}
+
+const flagOnConflict = `
+				INSERT INTO review_flag
+					(review_id, user_id, reason, created_at)
+				VALUES
+					(?, ?, ?, ?)
+				ON CONFLICT (review_id, user_id) DO UPDATE SET
+					reason = excluded.reason,
+					created_at = excluded.created_at
+			`
+
+// fetchReview returns the reviews matching where in the given order, with
+// the name of their author and their vote and flag counts.
+func (r *Review) fetchReview(ctx context.Context, where string, order string, args ...interface{}) (result []model.Review, err error) {
+	query := `
+			SELECT
+				review.id,
+				review.course_id,
+				review.user_id,
+				users.name,
+				review.rating,
+				review.body,
+				(SELECT COUNT(*) FROM review_vote WHERE review_vote.review_id = review.id),
+				(SELECT COUNT(*) FROM review_flag WHERE review_flag.review_id = review.id),
+				review.hidden,
+				review.reply,
+				review.replied_by,
+				review.replied_at,
+				review.created_at,
+				review.updated_at
+			FROM
+				review
+			JOIN
+				users ON users.id = review.user_id
+			WHERE
+				%s
+			ORDER BY
+				%s`
+
+	rows, err := r.DB.QueryContext(ctx, fmt.Sprintf(query, where, order), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = make([]model.Review, 0)
+
+	for rows.Next() {
+		var (
+			reply     sql.NullString
+			repliedBy sql.NullInt64
+			repliedAt *time.Time
+		)
+
+		t := model.Review{}
+		err = rows.Scan(
+			&t.Id,
+			&t.CourseId,
+			&t.UserId,
+			&t.UserName,
+			&t.Rating,
+			&t.Body,
+			&t.HelpfulCount,
+			&t.FlagCount,
+			&t.Hidden,
+			&reply,
+			&repliedBy,
+			&repliedAt,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		if reply.Valid && repliedAt != nil {
+			t.Reply = &model.ReviewReply{
+				Body:      reply.String,
+				RepliedBy: int(repliedBy.Int64),
+				RepliedAt: *repliedAt,
+			}
+		}
+
+		result = append(result, t)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *Review) FindOne(ctx context.Context, reviewID int) (*model.Review, error) {
+	reviews, err := r.fetchReview(ctx, `review.id = ?`, `review.id ASC`, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reviews) == 0 {
+		return nil, fmt.Errorf("%w: review %d", model.ErrNotFound, reviewID)
+	}
+
+	return &reviews[0], nil
+}
+
+// FindByUser returns the review of the user for the course.
+func (r *Review) FindByUser(ctx context.Context, userID int, courseID int) (*model.Review, error) {
+	reviews, err := r.fetchReview(ctx, `review.user_id = ? AND review.course_id = ?`, `review.id ASC`, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reviews) == 0 {
+		return nil, fmt.Errorf("%w: review of user %d for course %d", model.ErrNotFound, userID, courseID)
+	}
+
+	return &reviews[0], nil
+}
+
+// FetchByCourse returns every review of the course, hidden ones included,
+// newest first.
+func (r *Review) FetchByCourse(ctx context.Context, courseID int) ([]model.Review, error) {
+	return r.fetchReview(ctx, `review.course_id = ?`, `review.created_at DESC, review.id DESC`, courseID)
+}
+
+// FetchFlagged returns the reviews with open flags, most flagged first.
+func (r *Review) FetchFlagged(ctx context.Context) ([]model.Review, error) {
+	return r.fetchReview(ctx,
+		`EXISTS (SELECT 1 FROM review_flag WHERE review_flag.review_id = review.id)`,
+		`(SELECT COUNT(*) FROM review_flag WHERE review_flag.review_id = review.id) DESC, review.id ASC`)
+}
+
+// Store inserts the review. A user has one review per course, storing a
+// second one fails.
+func (r *Review) Store(ctx context.Context, review model.Review) (int, error) {
+	query := `
+				INSERT INTO review
+					(course_id, user_id, rating, body, created_at, updated_at)
+				VALUES
+					(?, ?, ?, ?, ?, ?)
+			`
+
+	id, err := r.DB.InsertContext(ctx, query,
+		review.CourseId, review.UserId, review.Rating, review.Body, review.CreatedAt, review.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Update saves the rating and text of the review.
+func (r *Review) Update(ctx context.Context, review model.Review) error {
+	query := `
+				UPDATE
+					review
+				SET
+					rating = ?,
+					body = ?,
+					updated_at = ?
+				WHERE
+					id = ?
+			`
+
+	_, err := r.DB.ExecContext(ctx, query, review.Rating, review.Body, review.UpdatedAt, review.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the review with its votes and flags.
+func (r *Review) Delete(ctx context.Context, reviewID int) error {
+	queries := []string{
+		`DELETE FROM review_vote WHERE review_id = ?`,
+		`DELETE FROM review_flag WHERE review_id = ?`,
+		`DELETE FROM review WHERE id = ?`,
+	}
+
+	for _, query := range queries {
+		if _, err := r.DB.ExecContext(ctx, query, reviewID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveReply sets the reply to the review, nil removes it.
+func (r *Review) SaveReply(ctx context.Context, reviewID int, reply *model.ReviewReply) error {
+	query := `
+				UPDATE
+					review
+				SET
+					reply = ?,
+					replied_by = ?,
+					replied_at = ?
+				WHERE
+					id = ?
+			`
+
+	var args []interface{}
+	if reply == nil {
+		args = []interface{}{nil, nil, nil, reviewID}
+	} else {
+		args = []interface{}{reply.Body, reply.RepliedBy, reply.RepliedAt, reviewID}
+	}
+
+	_, err := r.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetHidden hides or shows the review. Moderating a review resolves its
+// flags, they are removed.
+func (r *Review) SetHidden(ctx context.Context, reviewID int, hidden bool) error {
+	_, err := r.DB.ExecContext(ctx, `UPDATE review SET hidden = ? WHERE id = ?`, boolInt(hidden), reviewID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `DELETE FROM review_flag WHERE review_id = ?`, reviewID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Vote marks the review as helpful for the user.
+func (r *Review) Vote(ctx context.Context, reviewID int, userID int, at time.Time) error {
+	_, err := r.DB.ExecContext(ctx, voteQuery[r.DB.Dialect], reviewID, userID, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Unvote takes the helpfulness vote of the user back.
+func (r *Review) Unvote(ctx context.Context, reviewID int, userID int) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM review_vote WHERE review_id = ? AND user_id = ?`, reviewID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Review) Flag(ctx context.Context, flag model.ReviewFlag) error {
+	_, err := r.DB.ExecContext(ctx, flagQuery[r.DB.Dialect], flag.ReviewId, flag.UserId, flag.Reason, flag.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Distribution counts the visible reviews of the course per rating, with
+// every rating from 1 to 5 present.
+func (r *Review) Distribution(ctx context.Context, courseID int) (result map[int]int, err error) {
+	query := `
+			SELECT
+				rating,
+				COUNT(*)
+			FROM
+				review
+			WHERE
+				course_id = ? AND hidden = 0
+			GROUP BY
+				rating`
+
+	rows, err := r.DB.QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logger(ctx).Error(errRow)
+		}
+	}()
+
+	result = map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+
+	for rows.Next() {
+		var rating, count int
+		if err = rows.Scan(&rating, &count); err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		result[rating] = count
+	}
+
+	return result, rows.Err()
+}
+
+// RecomputeRating stores the average rating and the number of visible
+// reviews on the course.
+func (r *Review) RecomputeRating(ctx context.Context, courseID int) error {
+	_, err := r.DB.ExecContext(ctx, recomputeRatingQuery+`
+				WHERE
+					id = ?
+			`, courseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recomputeRatingQuery updates the rating columns of the courses it is
+// given a WHERE clause for.
+const recomputeRatingQuery = `
+				UPDATE
+					course
+				SET
+					rating_count = (
+						SELECT COUNT(review.id) FROM review WHERE review.course_id = course.id AND review.hidden = 0
+					),
+					rating_average = COALESCE((
+						SELECT ROUND(AVG(review.rating), 2) FROM review WHERE review.course_id = course.id AND review.hidden = 0
+					), 0)`
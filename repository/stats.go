@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+type Stats struct {
+	DB *Database
+}
+
+func NewStatsRepository(db *Database) StatsRepository {
+	return &Stats{
+		DB: db,
+	}
+}
+
+func (s *Stats) PoolStats(context.Context) (model.PoolStats, error) {
+	stats := s.DB.Stats()
+
+	return model.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       model.Duration(stats.WaitDuration),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}, nil
+}
+
+func (s *Stats) BusinessStats(ctx context.Context) (model.BusinessStats, error) {
+	query := `SELECT COUNT(id) FROM course WHERE flag_aktif = 1`
+	query2 := `SELECT COUNT(id) FROM users WHERE flag_aktif = 1`
+
+	stats := model.BusinessStats{}
+
+	err := s.DB.QueryRowContext(ctx, query).Scan(&stats.ActiveCourses)
+	if err != nil {
+		return stats, err
+	}
+
+	err = s.DB.QueryRowContext(ctx, query2).Scan(&stats.RegisteredUsers)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
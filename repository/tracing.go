@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/egaevan/online-learning/repository")
+
+var dbSystem = map[Dialect]string{
+	MySQL:      semconv.DBSystemMySQL.Value.AsString(),
+	PostgreSQL: semconv.DBSystemPostgreSQL.Value.AsString(),
+	SQLite:     semconv.DBSystemSqlite.Value.AsString(),
+}
+
+// startQuery starts a span named after the repository method running the
+// query, e.g. Course.FindOne. The returned function ends the span and
+// records the query duration.
+func (d *Database) startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	method := queryCaller()
+	start := time.Now()
+
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String(dbSystem[d.Dialect]),
+			semconv.DBStatementKey.String(query),
+		),
+	)
+
+	return ctx, func(err error) {
+		queryDuration.With(method).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}
+}
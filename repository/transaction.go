@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Transactor runs a unit of work. Repository calls made with the context
+// passed to fn share one transaction, which is committed when fn returns nil
+// and rolled back otherwise. Nested calls join the outer transaction.
+// WithinTransactionOptions begins it with the isolation level and access
+// mode of opts, nested calls keep the ones of the outer transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error
+}
+
+type txKey struct {
+	db *Database
+}
+
+type Transaction struct {
+	DB *Database
+	// MaxRetries is how many times a transaction aborted by a deadlock is
+	// run again. Every retry waits RetryDelay longer than the previous one.
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+func NewTransactor(db *Database, maxRetries int, retryDelay time.Duration) Transactor {
+	return &Transaction{
+		DB:         db,
+		MaxRetries: maxRetries,
+		RetryDelay: retryDelay,
+	}
+}
+
+func (t *Transaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.WithinTransactionOptions(ctx, nil, fn)
+}
+
+func (t *Transaction) WithinTransactionOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{db: t.DB}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := t.run(ctx, opts, fn)
+		if err == nil || attempt >= t.MaxRetries || !t.DB.Dialect.IsRetryable(err) {
+			return err
+		}
+
+		logger(ctx).Warnf("retrying transaction after attempt %d: %v", attempt+1, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.RetryDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func (t *Transaction) run(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	ctx, span := tracer.Start(ctx, "transaction")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}()
+
+	tx, err := t.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil && errRollback != sql.ErrTxDone {
+				logger(ctx).Error(errRollback)
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{db: t.DB}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -3,26 +3,25 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
-	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/egaevan/online-learning/model"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	DB *sql.DB
+	DB *Database
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
+func NewUserRepository(db *Database) UserRepository {
 	return &User{
 		DB: db,
 	}
 }
 
-func (u *User) FindOne(ctx context.Context, email, password string) (model.User, error) {
+// FindOne returns the active user with the given email, including the
+// password hash.
+func (u *User) FindOne(ctx context.Context, email string) (model.User, error) {
 	query := `
 			SELECT 
 				id,
@@ -32,9 +31,9 @@ func (u *User) FindOne(ctx context.Context, email, password string) (model.User,
 				phone,
 			    role
 			FROM 
-				user
+				users
 			WHERE
-				email = ?`
+				email = ? AND flag_aktif = 1`
 
 	user := model.User{}
 	err := u.DB.QueryRowContext(ctx, query, email).Scan(
@@ -43,38 +42,41 @@ func (u *User) FindOne(ctx context.Context, email, password string) (model.User,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return user, fmt.Errorf("data not found %s", err.Error())
+			return user, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
 		}
 		return user, err
 	}
 
-	expiresAt := time.Now().Add(time.Minute * 100000).Unix()
-
-	errf := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if errf != nil && errf == bcrypt.ErrMismatchedHashAndPassword { //Password does not match!
-		return user, errors.New("invalid password")
-	}
-
-	tk := &model.Token{
-		UserID: user.Id,
-		Name:   user.Name,
-		Email:  user.Email,
-		Role:   user.Role,
-		StandardClaims: &jwt.StandardClaims{
-			ExpiresAt: expiresAt,
-		},
-	}
+	return user, nil
+}
 
-	token := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tk)
+// FindByID returns the active user with the given id, without the password
+// hash.
+func (u *User) FindByID(ctx context.Context, userID int) (model.User, error) {
+	query := `
+			SELECT 
+				id,
+				name,
+				email,
+				phone,
+			    role
+			FROM 
+				users
+			WHERE
+				id = ? AND flag_aktif = 1`
 
-	tokenString, error := token.SignedString([]byte("secret"))
-	if error != nil {
+	user := model.User{}
+	err := u.DB.QueryRowContext(ctx, query, userID).Scan(
+		&user.Id, &user.Name, &user.Email, &user.Phone, &user.Role,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, fmt.Errorf("%w: %s", model.ErrNotFound, err.Error())
+		}
 		return user, err
 	}
 
-	user.Token = tokenString
-
-	return user, err
+	return user, nil
 }
 
 func (u *User) Fetch(context.Context) error {
@@ -90,14 +92,14 @@ func (u *User) Store(ctx context.Context, user model.User) error {
 	user.Password = string(pass)
 
 	query := `
-				INSERT INTO user 
-					(id, name, email, password, phone, role)
+				INSERT INTO users 
+					(name, email, password, phone, role)
 				VALUES
-					(?, ?, ?, ?, ?, ?)
+					(?, ?, ?, ?, ?)
 			`
 
 	_, err = u.DB.ExecContext(ctx, query,
-		user.Id, user.Name, user.Email, user.Password, user.Phone, user.Role)
+		user.Name, user.Email, user.Password, user.Phone, user.Role)
 	if err != nil {
 		return err
 	}
@@ -112,7 +114,7 @@ func (u *User) Update(context.Context) error {
 func (u *User) Delete(ctx context.Context, userID int) error {
 	query := `
 				UPDATE 
-					user
+					users
 				SET
 					flag_aktif = 0
 				WHERE
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+// Local keeps files in a directory on the local disk.
+type Local struct {
+	Dir string
+}
+
+// NewLocal returns a store in dir, creating the directory if needed.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		Dir: dir,
+	}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("invalid file key %q", key)
+	}
+
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+// Save writes to a temporary file first, so readers never see a partial
+// file.
+func (l *Local) Save(ctx context.Context, key string, r io.Reader) (int64, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := io.Copy(tmp, r)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: file %s", model.ErrNotFound, key)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
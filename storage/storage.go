@@ -0,0 +1,31 @@
+// Package storage keeps uploaded files. FileStore hides where they live,
+// Local keeps them in a directory on disk.
+package storage
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+)
+
+// FileStore saves, reads and deletes files by key. Keys are slash
+// separated relative paths such as submission/7/3f2a.
+type FileStore interface {
+	// Save writes the content of r under key, replacing any file with the
+	// same key, and returns its size.
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns the file under key, model.ErrNotFound when there is
+	// none. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file under key. Deleting a missing file is no
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey reports whether key is a clean relative path that stays inside
+// the store.
+func validKey(key string) bool {
+	return key != "" && path.Clean(key) == key && !path.IsAbs(key) &&
+		key != ".." && !strings.HasPrefix(key, "../") && !strings.Contains(key, "\\")
+}
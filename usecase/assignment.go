@@ -0,0 +1,619 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	"github.com/egaevan/online-learning/storage"
+)
+
+type Assignment struct {
+	AssignmentRepo repository.AssignmentRepository
+	CourseRepo     repository.CourseRepository
+	EnrollmentRepo repository.EnrollmentRepository
+	Transactor     repository.Transactor
+	// Files keeps the uploaded files, MaxFileSize is the largest one
+	// accepted in bytes.
+	Files       storage.FileStore
+	MaxFileSize int64
+	// Lessons completes the assignment lesson once the submission is graded.
+	Lessons LessonCompleter
+	// Notifier tells students about their grades.
+	Notifier Notifier
+}
+
+func NewAssignment(assignmentRepo repository.AssignmentRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, transactor repository.Transactor, files storage.FileStore, maxFileSize int64, lessons LessonCompleter, notifier Notifier) AssignmentUsecae {
+	return &Assignment{
+		AssignmentRepo: assignmentRepo,
+		CourseRepo:     courseRepo,
+		EnrollmentRepo: enrollmentRepo,
+		Transactor:     transactor,
+		Files:          files,
+		MaxFileSize:    maxFileSize,
+		Lessons:        lessons,
+		Notifier:       notifier,
+	}
+}
+
+// validAssignment checks the settings of the assignment and fills in its
+// max score.
+func validAssignment(a *model.Assignment) bool {
+	if a.DueAt.IsZero() || len(a.Rubric) == 0 {
+		return false
+	}
+
+	if a.PenaltyPerDay < 0 || a.PenaltyPerDay > 100 || a.MaxPenalty < 0 || a.MaxPenalty > 100 || a.CutoffDays < 0 {
+		return false
+	}
+
+	a.MaxScore = 0
+
+	for _, c := range a.Rubric {
+		if c.Title == "" || c.Points <= 0 {
+			return false
+		}
+
+		a.MaxScore += c.Points
+	}
+
+	a.DueAt = a.DueAt.UTC().Truncate(time.Second)
+
+	return true
+}
+
+// daysLate counts the started days from due to at, 0 when at is not after
+// due.
+func daysLate(due time.Time, at time.Time) int {
+	if !at.After(due) {
+		return 0
+	}
+
+	day := 24 * time.Hour
+
+	return int((at.Sub(due) + day - 1) / day)
+}
+
+// latePenalty is the percentage taken off a submission days late.
+func latePenalty(a model.Assignment, days int) int {
+	limit := a.MaxPenalty
+	if limit == 0 {
+		limit = 100
+	}
+
+	penalty := days * a.PenaltyPerDay
+	if penalty > limit {
+		penalty = limit
+	}
+
+	return penalty
+}
+
+// validScores checks that scores grade every criterion of the rubric once,
+// within its points, and sorts them by criterion.
+func validScores(rubric []model.Criterion, scores []model.CriterionScore) bool {
+	if len(scores) != len(rubric) {
+		return false
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Criterion < scores[j].Criterion
+	})
+
+	for i, s := range scores {
+		if s.Criterion != i || s.Points < 0 || s.Points > rubric[i].Points {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fileName keeps the base name of an uploaded file, whatever the path
+// separator of the client.
+func fileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return "file"
+	}
+
+	return name
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// enrolled returns model.ErrForbidden unless the user is enrolled in the
+// course.
+func (a *Assignment) enrolled(ctx context.Context, userID int, courseID int) error {
+	_, err := a.EnrollmentRepo.FindOne(ctx, userID, courseID)
+	if errors.Is(err, model.ErrNotFound) {
+		return model.ErrForbidden
+	}
+
+	return err
+}
+
+// saveFiles writes the uploads to the file store. On error the files
+// written so far are deleted again.
+func (a *Assignment) saveFiles(ctx context.Context, userID int, lessonID int, uploads []model.Upload) ([]model.SubmissionFile, error) {
+	result := make([]model.SubmissionFile, 0, len(uploads))
+
+	for _, u := range uploads {
+		key, err := randomKey()
+		if err != nil {
+			a.deleteFiles(ctx, result)
+			return nil, err
+		}
+
+		file := model.SubmissionFile{
+			Name:        fileName(u.Name),
+			ContentType: u.ContentType,
+			Key:         fmt.Sprintf("submission/%d/%d/%s", lessonID, userID, key),
+		}
+
+		if file.ContentType == "" {
+			file.ContentType = "application/octet-stream"
+		}
+
+		file.Size, err = a.Files.Save(ctx, file.Key, io.LimitReader(u.Body, a.MaxFileSize+1))
+		if err == nil && file.Size > a.MaxFileSize {
+			err = fmt.Errorf("%w: %s is larger than %d bytes", model.ErrTooLarge, file.Name, a.MaxFileSize)
+		}
+
+		result = append(result, file)
+
+		if err != nil {
+			a.deleteFiles(ctx, result)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// deleteFiles removes files from the file store. Failures are only logged,
+// a leftover file does no harm.
+func (a *Assignment) deleteFiles(ctx context.Context, files []model.SubmissionFile) {
+	for _, f := range files {
+		if err := a.Files.Delete(ctx, f.Key); err != nil {
+			logger(ctx).Error(err)
+		}
+	}
+}
+
+// GetAssignment returns the assignment of a lesson to admins and the users
+// allowed to follow the lesson. Like lessons, assignments of free previews
+// are public.
+func (a *Assignment) GetAssignment(ctx context.Context, lessonID int, user *model.Token) (*model.Assignment, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.GetAssignment")
+	defer span.End()
+
+	lesson, err := a.CourseRepo.FindLesson(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	assignment, err := a.AssignmentRepo.FindAssignment(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if lesson.FreePreview || (user != nil && user.Role == constant.RoleAdmin) {
+		return assignment, nil
+	}
+
+	if user == nil {
+		return nil, model.ErrForbidden
+	}
+
+	if err := a.enrolled(ctx, user.UserID, lesson.CourseId); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// SaveAssignment sets the due date, rubric and late penalty rules of a
+// lesson of type assignment. Submissions graded before keep their grade.
+func (a *Assignment) SaveAssignment(ctx context.Context, lessonID int, assignment model.Assignment) (*model.Assignment, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.SaveAssignment")
+	defer span.End()
+
+	if !validAssignment(&assignment) {
+		return nil, model.ErrBadParamInput
+	}
+
+	var result *model.Assignment
+
+	err := a.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		lesson, err := a.CourseRepo.FindLesson(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		if lesson.Type != constant.LessonAssignment {
+			return fmt.Errorf("%w: lesson %d is no assignment", model.ErrBadParamInput, lessonID)
+		}
+
+		assignment.LessonId = lessonID
+
+		if err := a.AssignmentRepo.SaveAssignment(ctx, assignment); err != nil {
+			return err
+		}
+
+		result, err = a.AssignmentRepo.FindAssignment(ctx, lessonID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Submit stores the files of an enrolled user as the submission to the
+// assignment. Submitting again before the submission is graded replaces
+// its files. Submissions more than the cutoff days late are refused.
+func (a *Assignment) Submit(ctx context.Context, userID int, lessonID int, uploads []model.Upload) (*model.Submission, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.Submit")
+	defer span.End()
+
+	if len(uploads) == 0 || len(uploads) > constant.MaxSubmissionFiles {
+		return nil, model.ErrBadParamInput
+	}
+
+	assignment, err := a.AssignmentRepo.FindAssignment(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if err := a.enrolled(ctx, userID, assignment.CourseId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	late := daysLate(assignment.DueAt, now)
+	if assignment.CutoffDays > 0 && late > assignment.CutoffDays {
+		return nil, model.ErrPastDeadline
+	}
+
+	// Spare the upload when the submission can't be replaced anyway.
+	current, err := a.AssignmentRepo.FindUserSubmission(ctx, userID, lessonID)
+	if err == nil && current.GradedAt != nil {
+		return nil, model.ErrForbidden
+	}
+
+	files, err := a.saveFiles(ctx, userID, lessonID, uploads)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	var (
+		result   *model.Submission
+		replaced []model.SubmissionFile
+	)
+
+	err = a.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		submission := model.Submission{
+			UserId:      userID,
+			LessonId:    lessonID,
+			CourseId:    assignment.CourseId,
+			SubmittedAt: now,
+			DaysLate:    late,
+		}
+
+		current, err := a.AssignmentRepo.FindUserSubmission(ctx, userID, lessonID)
+
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+			submission.Id, err = a.AssignmentRepo.StoreSubmission(ctx, submission)
+			if err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			submission.Id = current.Id
+
+			done, err := a.AssignmentRepo.Resubmit(ctx, submission)
+			if err != nil {
+				return err
+			}
+
+			if !done {
+				return fmt.Errorf("%w: submission %d is graded", model.ErrForbidden, current.Id)
+			}
+
+			if err := a.AssignmentRepo.DeleteFile(ctx, current.Id); err != nil {
+				return err
+			}
+
+			replaced = current.Files
+		}
+
+		for _, f := range files {
+			f.SubmissionId = submission.Id
+
+			if _, err := a.AssignmentRepo.StoreFile(ctx, f); err != nil {
+				return err
+			}
+		}
+
+		result, err = a.AssignmentRepo.FindSubmission(ctx, submission.Id)
+
+		return err
+	})
+	if err != nil {
+		a.deleteFiles(ctx, files)
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	a.deleteFiles(ctx, replaced)
+
+	if late > 0 {
+		submissions.With("late").Inc()
+	} else {
+		submissions.With("on_time").Inc()
+	}
+
+	return result, nil
+}
+
+// GetMySubmission returns the submission of the user to the assignment.
+func (a *Assignment) GetMySubmission(ctx context.Context, userID int, lessonID int) (*model.Submission, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.GetMySubmission")
+	defer span.End()
+
+	result, err := a.AssignmentRepo.FindUserSubmission(ctx, userID, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetSubmission returns a submission to admins and to its user.
+func (a *Assignment) GetSubmission(ctx context.Context, submissionID int, user *model.Token) (*model.Submission, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.GetSubmission")
+	defer span.End()
+
+	result, err := a.AssignmentRepo.FindSubmission(ctx, submissionID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if user.Role != constant.RoleAdmin && result.UserId != user.UserID {
+		return nil, fmt.Errorf("%w: submission %d of another user", model.ErrNotFound, submissionID)
+	}
+
+	return result, nil
+}
+
+// OpenFile returns a file of a submission and its content to admins and to
+// the user of the submission. The caller closes the content.
+func (a *Assignment) OpenFile(ctx context.Context, submissionID int, fileID int, user *model.Token) (*model.SubmissionFile, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.OpenFile")
+	defer span.End()
+
+	if _, err := a.GetSubmission(ctx, submissionID, user); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := a.AssignmentRepo.FindFile(ctx, fileID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, nil, err
+	}
+
+	if file.SubmissionId != submissionID {
+		return nil, nil, fmt.Errorf("%w: file %d of another submission", model.ErrNotFound, fileID)
+	}
+
+	content, err := a.Files.Open(ctx, file.Key)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, nil, err
+	}
+
+	return file, content, nil
+}
+
+// GradeSubmission grades a submission with points for every criterion of
+// the rubric, less the late penalty. Grading again replaces the grade. The
+// assignment lesson is completed and the student is notified.
+func (a *Assignment) GradeSubmission(ctx context.Context, graderID int, submissionID int, grade model.GradeRequest) (*model.Submission, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.GradeSubmission")
+	defer span.End()
+
+	var (
+		result     *model.Submission
+		assignment *model.Assignment
+	)
+
+	err := a.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		submission, err := a.AssignmentRepo.FindSubmission(ctx, submissionID)
+		if err != nil {
+			return err
+		}
+
+		assignment, err = a.AssignmentRepo.FindAssignment(ctx, submission.LessonId)
+		if err != nil {
+			return err
+		}
+
+		if !validScores(assignment.Rubric, grade.Scores) {
+			return model.ErrBadParamInput
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		submission.Scores = grade.Scores
+		submission.RawScore = 0
+		for _, s := range grade.Scores {
+			submission.RawScore += s.Points
+		}
+
+		submission.Penalty = latePenalty(*assignment, submission.DaysLate)
+		submission.Score = submission.RawScore * (100 - submission.Penalty) / 100
+		submission.Comment = grade.Comment
+		submission.GradedAt = &now
+		submission.GradedBy = graderID
+
+		if err := a.AssignmentRepo.GradeSubmission(ctx, *submission); err != nil {
+			return err
+		}
+
+		result, err = a.AssignmentRepo.FindSubmission(ctx, submissionID)
+		if err != nil {
+			return err
+		}
+
+		// Completing the lesson joins the transaction, so a grade is never
+		// stored without it.
+		return a.Lessons.CompleteLesson(ctx, result.UserId, result.LessonId)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	body := fmt.Sprintf("You scored %d of %d points.", result.Score, assignment.MaxScore)
+	if result.Penalty > 0 {
+		body += fmt.Sprintf(" %d%% were taken off for submitting %d days late.", result.Penalty, result.DaysLate)
+	}
+
+	// The grade is stored, a lost notification must not fail the request.
+	err = a.Notifier.Notify(ctx, model.Notification{
+		UserId:  result.UserId,
+		Type:    constant.NotificationGrade,
+		Subject: fmt.Sprintf("%s was graded", assignment.Title),
+		Body:    body,
+		Link:    fmt.Sprintf("/submission/%d", result.Id),
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+	}
+
+	return result, nil
+}
+
+// gradebookEntry is the status of a student for the assignment at now,
+// given the submission if there is one.
+func gradebookEntry(assignment model.Assignment, submission *model.Submission, now time.Time) model.GradebookEntry {
+	entry := model.GradebookEntry{
+		LessonId: assignment.LessonId,
+	}
+
+	switch {
+	case submission == nil && now.After(assignment.DueAt):
+		entry.Status = constant.SubmissionMissing
+		return entry
+	case submission == nil:
+		entry.Status = constant.SubmissionOpen
+		return entry
+	case submission.GradedAt != nil:
+		entry.Status = constant.SubmissionGraded
+		score := submission.Score
+		entry.Score = &score
+	case submission.DaysLate > 0:
+		entry.Status = constant.SubmissionLate
+	default:
+		entry.Status = constant.SubmissionSubmitted
+	}
+
+	entry.SubmissionId = submission.Id
+	entry.SubmittedAt = &submission.SubmittedAt
+	entry.DaysLate = submission.DaysLate
+
+	return entry
+}
+
+// GetGradebook returns the status of every enrolled student for every
+// assignment of the course, students in enrollment order and assignments
+// in curriculum order.
+func (a *Assignment) GetGradebook(ctx context.Context, courseID int) (*model.Gradebook, error) {
+	ctx, span := tracer.Start(ctx, "Assignment.GetGradebook")
+	defer span.End()
+
+	if _, err := a.CourseRepo.FindOne(ctx, courseID); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	assignments, err := a.AssignmentRepo.FetchAssignment(ctx, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	roster, err := a.EnrollmentRepo.FetchByCourse(ctx, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	submissions, err := a.AssignmentRepo.FetchSubmission(ctx, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	type key struct{ userID, lessonID int }
+
+	byStudent := make(map[key]*model.Submission, len(submissions))
+	for i := range submissions {
+		byStudent[key{submissions[i].UserId, submissions[i].LessonId}] = &submissions[i]
+	}
+
+	now := time.Now().UTC()
+
+	result := &model.Gradebook{
+		CourseId:    courseID,
+		Assignments: assignments,
+		Students:    make([]model.GradebookRow, 0, len(roster)),
+	}
+
+	for _, r := range roster {
+		row := model.GradebookRow{
+			UserId:  r.UserId,
+			Name:    r.Name,
+			Email:   r.Email,
+			Entries: make([]model.GradebookEntry, 0, len(assignments)),
+		}
+
+		for _, v := range assignments {
+			row.Entries = append(row.Entries, gradebookEntry(v, byStudent[key{r.UserId, v.LessonId}], now))
+		}
+
+		result.Students = append(result.Students, row)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func TestDaysLate(t *testing.T) {
+	due := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{"early", due.Add(-time.Hour), 0},
+		{"on time", due, 0},
+		{"a second late", due.Add(time.Second), 1},
+		{"a day late", due.Add(24 * time.Hour), 1},
+		{"a day and a second late", due.Add(24*time.Hour + time.Second), 2},
+		{"thirty hours late", due.Add(30 * time.Hour), 2},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := daysLate(due, c.at); got != c.want {
+				t.Errorf("daysLate = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLatePenalty(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		assignment model.Assignment
+		days       int
+		want       int
+	}{
+		{"on time", model.Assignment{PenaltyPerDay: 10, MaxPenalty: 50}, 0, 0},
+		{"per day", model.Assignment{PenaltyPerDay: 10, MaxPenalty: 50}, 3, 30},
+		{"at the limit", model.Assignment{PenaltyPerDay: 10, MaxPenalty: 50}, 5, 50},
+		{"over the limit", model.Assignment{PenaltyPerDay: 10, MaxPenalty: 50}, 9, 50},
+		{"no limit caps at 100", model.Assignment{PenaltyPerDay: 30}, 4, 100},
+		{"no penalty", model.Assignment{MaxPenalty: 50}, 9, 0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := latePenalty(c.assignment, c.days); got != c.want {
+				t.Errorf("latePenalty(%d days) = %d, want %d", c.days, got, c.want)
+			}
+		})
+	}
+}
+
+type lessonCompleterFunc func(ctx context.Context, userID int, lessonID int) error
+
+func (f lessonCompleterFunc) CompleteLesson(ctx context.Context, userID int, lessonID int) error {
+	return f(ctx, userID, lessonID)
+}
+
+type notifierFunc func(ctx context.Context, notification model.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, notification model.Notification) error {
+	return f(ctx, notification)
+}
+
+func TestGradeSubmission(t *testing.T) {
+	ctx := context.Background()
+	errLocked := errors.New("database is locked")
+	due := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rubric := []model.Criterion{{Title: "Works", Points: 6}, {Title: "Style", Points: 4}}
+
+	for _, c := range []struct {
+		name        string
+		daysLate    int
+		scores      []model.CriterionScore
+		completeErr error
+		wantRaw     int
+		wantPenalty int
+		wantScore   int
+		wantErr     error
+	}{
+		{name: "on time", scores: []model.CriterionScore{{Criterion: 0, Points: 6}, {Criterion: 1, Points: 3}}, wantRaw: 9, wantScore: 9},
+		{name: "late rounds down", daysLate: 2, scores: []model.CriterionScore{{Criterion: 1, Points: 3}, {Criterion: 0, Points: 6}}, wantRaw: 9, wantPenalty: 20, wantScore: 7},
+		{name: "penalty limit", daysLate: 9, scores: []model.CriterionScore{{Criterion: 0, Points: 6}, {Criterion: 1, Points: 4}}, wantRaw: 10, wantPenalty: 50, wantScore: 5},
+		{name: "over the criterion points", scores: []model.CriterionScore{{Criterion: 0, Points: 7}, {Criterion: 1, Points: 4}}, wantErr: model.ErrBadParamInput},
+		{name: "missing criterion", scores: []model.CriterionScore{{Criterion: 0, Points: 6}}, wantErr: model.ErrBadParamInput},
+		{name: "lesson not completed", scores: []model.CriterionScore{{Criterion: 0, Points: 6}, {Criterion: 1, Points: 4}}, completeErr: errLocked, wantErr: errLocked},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			courseID := f.course(f.category("Programming", 0), "Go Basics", 0)
+			lessonID := f.lesson(courseID, constant.LessonAssignment)
+			ann := f.user("Ann", "ann@example.com")
+			f.enroll(ann, courseID)
+
+			if err := f.repos.Assignment.SaveAssignment(ctx, model.Assignment{LessonId: lessonID, DueAt: due, Rubric: rubric, MaxScore: 10, PenaltyPerDay: 10, MaxPenalty: 50}); err != nil {
+				t.Fatal(err)
+			}
+
+			id, err := f.repos.Assignment.StoreSubmission(ctx, model.Submission{UserId: ann, LessonId: lessonID, CourseId: courseID, SubmittedAt: due.Add(time.Duration(c.daysLate) * 24 * time.Hour), DaysLate: c.daysLate})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			completed, notified := false, false
+
+			lessons := lessonCompleterFunc(func(ctx context.Context, userID int, lessonID int) error {
+				completed = true
+				return c.completeErr
+			})
+
+			notifier := notifierFunc(func(ctx context.Context, notification model.Notification) error {
+				notified = true
+				return nil
+			})
+
+			uc := NewAssignment(f.repos.Assignment, f.repos.Course, f.repos.Enrollment, f.repos.Transactor, nil, 0, lessons, notifier)
+
+			submission, err := uc.GradeSubmission(ctx, 1, id, model.GradeRequest{Scores: c.scores, Comment: "Nice"})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("GradeSubmission returned %v, want %v", err, c.wantErr)
+				}
+
+				if completed != (c.completeErr != nil) || notified {
+					t.Errorf("GradeSubmission completed the lesson %v and notified %v without a grade", completed, notified)
+				}
+
+				stored, err := f.repos.Assignment.FindSubmission(ctx, id)
+				if err != nil || stored.GradedAt != nil {
+					t.Errorf("FindSubmission = %+v, %v, want no grade", stored, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if submission.RawScore != c.wantRaw || submission.Penalty != c.wantPenalty || submission.Score != c.wantScore || submission.GradedAt == nil {
+				t.Errorf("GradeSubmission = raw %d, penalty %d, score %d, want %d, %d, %d", submission.RawScore, submission.Penalty, submission.Score, c.wantRaw, c.wantPenalty, c.wantScore)
+			}
+
+			if !completed || !notified {
+				t.Errorf("GradeSubmission completed the lesson %v and notified %v", completed, notified)
+			}
+
+			stored, err := f.repos.Assignment.FindSubmission(ctx, id)
+			if err != nil || stored.Score != c.wantScore {
+				t.Errorf("FindSubmission = %+v, %v, want the stored score %d", stored, err, c.wantScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+func (c *Course) GetCategoryTree(ctx context.Context) ([]model.CategoryTree, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetCategoryTree")
+	defer span.End()
+
+	nodes, err := c.CourseRepo.FetchCategoryNode(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return buildCategoryTree(nodes), nil
+}
+
+func (c *Course) GetCourseByCategory(ctx context.Context, categoryID int) ([]model.Course, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetCourseByCategory")
+	defer span.End()
+
+	nodes, err := c.CourseRepo.FetchCategoryNode(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	categoryIDs := descendantCategoryIDs(nodes, categoryID)
+	if len(categoryIDs) == 0 {
+		return nil, model.ErrNotFound
+	}
+
+	course, err := c.CourseRepo.FetchByCategory(ctx, categoryIDs)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return course, nil
+}
+
+func (c *Course) CreateCategory(ctx context.Context, category model.Category) (*model.Category, error) {
+	ctx, span := tracer.Start(ctx, "Course.CreateCategory")
+	defer span.End()
+
+	if category.Name == "" {
+		return nil, model.ErrBadParamInput
+	}
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if category.ParentId != 0 {
+			nodes, err := c.CourseRepo.FetchCategoryNode(ctx)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := categoryIndex(nodes)[category.ParentId]; !ok {
+				return model.ErrBadParamInput
+			}
+		}
+
+		id, err := c.CourseRepo.StoreCategory(ctx, category)
+		if err != nil {
+			return err
+		}
+
+		category.Id = id
+
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func categoryIndex(nodes []model.CategoryNode) map[int]model.CategoryNode {
+	index := make(map[int]model.CategoryNode, len(nodes))
+	for _, n := range nodes {
+		index[n.Id] = n
+	}
+
+	return index
+}
+
+// categoryBreadcrumb returns the path from the root category down to
+// categoryID. A parent that is missing or already visited ends the walk, so
+// bad data can't loop forever.
+func categoryBreadcrumb(nodes []model.CategoryNode, categoryID int) []model.Category {
+	index := categoryIndex(nodes)
+	visited := map[int]bool{}
+	path := []model.Category{}
+
+	for id := categoryID; id != 0 && !visited[id]; {
+		n, ok := index[id]
+		if !ok {
+			break
+		}
+
+		visited[id] = true
+		path = append([]model.Category{{Id: n.Id, Name: n.Name, ParentId: n.ParentId}}, path...)
+		id = n.ParentId
+	}
+
+	return path
+}
+
+// descendantCategoryIDs returns categoryID followed by the ids of all of its
+// descendants, or nil when the category doesn't exist.
+func descendantCategoryIDs(nodes []model.CategoryNode, categoryID int) []int {
+	if _, ok := categoryIndex(nodes)[categoryID]; !ok {
+		return nil
+	}
+
+	children := map[int][]int{}
+	for _, n := range nodes {
+		children[n.ParentId] = append(children[n.ParentId], n.Id)
+	}
+
+	visited := map[int]bool{categoryID: true}
+	result := []int{categoryID}
+
+	for i := 0; i < len(result); i++ {
+		for _, child := range children[result[i]] {
+			if visited[child] {
+				continue
+			}
+
+			visited[child] = true
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+// buildCategoryTree nests the flat category list and sums course counts from
+// the leaves up. Categories whose parent doesn't exist are treated as roots,
+// and so is the lowest id of categories that are each other's parents.
+func buildCategoryTree(nodes []model.CategoryNode) []model.CategoryTree {
+	index := categoryIndex(nodes)
+	children := map[int][]model.CategoryNode{}
+	roots := []model.CategoryNode{}
+
+	for _, n := range nodes {
+		if _, ok := index[n.ParentId]; n.ParentId == 0 || !ok || n.ParentId == n.Id {
+			roots = append(roots, n)
+			continue
+		}
+
+		children[n.ParentId] = append(children[n.ParentId], n)
+	}
+
+	visited := map[int]bool{}
+
+	var build func(n model.CategoryNode) model.CategoryTree
+	build = func(n model.CategoryNode) model.CategoryTree {
+		visited[n.Id] = true
+
+		tree := model.CategoryTree{
+			Id:          n.Id,
+			Name:        n.Name,
+			ParentId:    n.ParentId,
+			CourseCount: n.CourseCount,
+			Children:    []model.CategoryTree{},
+		}
+
+		for _, child := range children[n.Id] {
+			if visited[child.Id] {
+				continue
+			}
+
+			sub := build(child)
+			tree.CourseCount += sub.CourseCount
+			tree.Children = append(tree.Children, sub)
+		}
+
+		sortCategoryTree(tree.Children)
+
+		return tree
+	}
+
+	result := []model.CategoryTree{}
+	for _, root := range roots {
+		result = append(result, build(root))
+	}
+
+	// Whatever is left hangs in a parent cycle that no root reaches.
+	sorted := append([]model.CategoryNode{}, nodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	for _, n := range sorted {
+		if !visited[n.Id] {
+			result = append(result, build(n))
+		}
+	}
+
+	sortCategoryTree(result)
+
+	return result
+}
+
+func sortCategoryTree(tree []model.CategoryTree) {
+	sort.Slice(tree, func(i, j int) bool {
+		return tree[i].Name < tree[j].Name
+	})
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/egaevan/online-learning/model"
+)
+
+// formatTree writes the tree as Name(count)[children...] to compare it in
+// one line.
+func formatTree(tree []model.CategoryTree) string {
+	parts := make([]string, 0, len(tree))
+
+	for _, n := range tree {
+		part := fmt.Sprintf("%s(%d)", n.Name, n.CourseCount)
+		if len(n.Children) > 0 {
+			part += "[" + formatTree(n.Children) + "]"
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// chain returns n categories each nested in the one before.
+func chain(n int) []model.CategoryNode {
+	nodes := make([]model.CategoryNode, 0, n)
+	for id := 1; id <= n; id++ {
+		nodes = append(nodes, model.CategoryNode{Id: id, Name: fmt.Sprintf("C%d", id), ParentId: id - 1, CourseCount: 1})
+	}
+
+	return nodes
+}
+
+var categoryTests = []struct {
+	name  string
+	nodes []model.CategoryNode
+	// tree is the formatTree of buildCategoryTree.
+	tree string
+	// of is the category of breadcrumb and descendants.
+	of          int
+	breadcrumb  []int
+	descendants []int
+}{
+	{
+		name: "nested",
+		nodes: []model.CategoryNode{
+			{Id: 1, Name: "Programming", CourseCount: 1},
+			{Id: 2, Name: "Go", ParentId: 1, CourseCount: 2},
+			{Id: 3, Name: "Concurrency", ParentId: 2, CourseCount: 3},
+			{Id: 4, Name: "Design", CourseCount: 4},
+			{Id: 5, Name: "Basics", ParentId: 1},
+		},
+		tree:        "Design(4) Programming(6)[Basics(0) Go(5)[Concurrency(3)]]",
+		of:          3,
+		breadcrumb:  []int{1, 2, 3},
+		descendants: []int{3},
+	},
+	{
+		name: "descendants",
+		nodes: []model.CategoryNode{
+			{Id: 1, Name: "Programming", CourseCount: 1},
+			{Id: 2, Name: "Go", ParentId: 1, CourseCount: 2},
+			{Id: 3, Name: "Concurrency", ParentId: 2, CourseCount: 3},
+			{Id: 4, Name: "Rust", ParentId: 1},
+		},
+		tree:        "Programming(6)[Go(5)[Concurrency(3)] Rust(0)]",
+		of:          1,
+		breadcrumb:  []int{1},
+		descendants: []int{1, 2, 4, 3},
+	},
+	{
+		name: "orphan parent is a root",
+		nodes: []model.CategoryNode{
+			{Id: 1, Name: "Programming", CourseCount: 1},
+			{Id: 2, Name: "Go", ParentId: 9, CourseCount: 2},
+			{Id: 3, Name: "Concurrency", ParentId: 2, CourseCount: 3},
+		},
+		tree:        "Go(5)[Concurrency(3)] Programming(1)",
+		of:          3,
+		breadcrumb:  []int{2, 3},
+		descendants: []int{3},
+	},
+	{
+		name: "own parent",
+		nodes: []model.CategoryNode{
+			{Id: 1, Name: "Programming", ParentId: 1, CourseCount: 1},
+			{Id: 2, Name: "Go", ParentId: 1, CourseCount: 2},
+		},
+		tree:        "Programming(3)[Go(2)]",
+		of:          2,
+		breadcrumb:  []int{1, 2},
+		descendants: []int{2},
+	},
+	{
+		name: "parent cycle",
+		nodes: []model.CategoryNode{
+			{Id: 1, Name: "Programming", CourseCount: 1},
+			{Id: 2, Name: "Go", ParentId: 3, CourseCount: 2},
+			{Id: 3, Name: "Concurrency", ParentId: 2, CourseCount: 3},
+			{Id: 4, Name: "Channels", ParentId: 3, CourseCount: 4},
+		},
+		tree:        "Go(9)[Concurrency(7)[Channels(4)]] Programming(1)",
+		of:          2,
+		breadcrumb:  []int{3, 2},
+		descendants: []int{2, 3, 4},
+	},
+	{
+		name:        "deep nesting",
+		nodes:       chain(200),
+		of:          200,
+		breadcrumb:  ids(1, 200),
+		descendants: []int{200},
+	},
+	{
+		name:  "unknown category",
+		nodes: []model.CategoryNode{{Id: 1, Name: "Programming"}},
+		tree:  "Programming(0)",
+		of:    9,
+	},
+}
+
+func ids(from int, to int) []int {
+	result := []int{}
+	for id := from; id <= to; id++ {
+		result = append(result, id)
+	}
+
+	return result
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	for _, c := range categoryTests {
+		t.Run(c.name, func(t *testing.T) {
+			tree := buildCategoryTree(c.nodes)
+
+			if c.tree != "" {
+				if got := formatTree(tree); got != c.tree {
+					t.Errorf("buildCategoryTree = %s, want %s", got, c.tree)
+				}
+			}
+
+			// Every category shows up once, whatever the parents.
+			seen := map[int]int{}
+
+			var walk func(tree []model.CategoryTree)
+			walk = func(tree []model.CategoryTree) {
+				for _, n := range tree {
+					seen[n.Id]++
+					walk(n.Children)
+				}
+			}
+
+			walk(tree)
+
+			for _, n := range c.nodes {
+				if seen[n.Id] != 1 {
+					t.Errorf("category %d is %d times in the tree, want once", n.Id, seen[n.Id])
+				}
+			}
+
+			total := 0
+			for _, n := range c.nodes {
+				total += n.CourseCount
+			}
+
+			sum := 0
+			for _, root := range tree {
+				sum += root.CourseCount
+			}
+
+			if sum != total {
+				t.Errorf("the roots count %d courses, want %d", sum, total)
+			}
+		})
+	}
+}
+
+func TestCategoryBreadcrumb(t *testing.T) {
+	for _, c := range categoryTests {
+		t.Run(c.name, func(t *testing.T) {
+			got := []int{}
+			for _, category := range categoryBreadcrumb(c.nodes, c.of) {
+				got = append(got, category.Id)
+			}
+
+			want := c.breadcrumb
+			if want == nil {
+				want = []int{}
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("categoryBreadcrumb(%d) = %v, want %v", c.of, got, want)
+			}
+		})
+	}
+}
+
+func TestDescendantCategoryIDs(t *testing.T) {
+	for _, c := range categoryTests {
+		t.Run(c.name, func(t *testing.T) {
+			if got := descendantCategoryIDs(c.nodes, c.of); !reflect.DeepEqual(got, c.descendants) {
+				t.Errorf("descendantCategoryIDs(%d) = %v, want %v", c.of, got, c.descendants)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+	"github.com/egaevan/online-learning/storage"
+)
+
+type Certificate struct {
+	CertificateRepo repository.CertificateRepository
+	UserRepo        repository.UserRepository
+	CourseRepo      repository.CourseRepository
+	EnrollmentRepo  repository.EnrollmentRepository
+	// Files keeps the issued PDFs, Renderer draws them.
+	Files    storage.FileStore
+	Renderer CertificateRenderer
+	// Notifier tells students their certificate is ready.
+	Notifier Notifier
+}
+
+func NewCertificate(certificateRepo repository.CertificateRepository, userRepo repository.UserRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, files storage.FileStore, renderer CertificateRenderer, notifier Notifier) CertificateUsecae {
+	return &Certificate{
+		CertificateRepo: certificateRepo,
+		UserRepo:        userRepo,
+		CourseRepo:      courseRepo,
+		EnrollmentRepo:  enrollmentRepo,
+		Files:           files,
+		Renderer:        renderer,
+		Notifier:        notifier,
+	}
+}
+
+// validCertificateID reports whether id looks like an issued certificate
+// id, 32 lowercase hex digits.
+func validCertificateID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+
+	_, err := hex.DecodeString(id)
+
+	return err == nil && strings.ToLower(id) == id
+}
+
+func certificateKey(id string) string {
+	return fmt.Sprintf("certificate/%s.pdf", id)
+}
+
+// findCertificate returns the certificate with the id. Ids that can't be
+// issued are not looked up.
+func (c *Certificate) findCertificate(ctx context.Context, certificateID string) (*model.Certificate, error) {
+	if !validCertificateID(certificateID) {
+		return nil, fmt.Errorf("%w: certificate %q", model.ErrNotFound, certificateID)
+	}
+
+	result, err := c.CertificateRepo.FindOne(ctx, certificateID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Issue issues the certificate of a completed enrollment: the PDF is
+// rendered and kept, and the student is notified. The names on it are the
+// ones at issue time. A user gets one certificate per course, issuing it
+// again returns the existing one.
+func (c *Certificate) Issue(ctx context.Context, enrollment model.Enrollment) (*model.Certificate, error) {
+	ctx, span := tracer.Start(ctx, "Certificate.Issue")
+	defer span.End()
+
+	if enrollment.CompletedAt == nil {
+		return nil, fmt.Errorf("%w: course %d is not completed", model.ErrBadParamInput, enrollment.CourseId)
+	}
+
+	existing, err := c.CertificateRepo.FindByCourse(ctx, enrollment.UserId, enrollment.CourseId)
+	if err == nil {
+		return existing, nil
+	}
+
+	if !errors.Is(err, model.ErrNotFound) {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	user, err := c.UserRepo.FindByID(ctx, enrollment.UserId)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	course, err := c.CourseRepo.FindOne(ctx, enrollment.CourseId)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	// 128 random bits, the id can't be guessed from other certificates.
+	id, err := randomKey()
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	result := model.Certificate{
+		Id:          id,
+		UserId:      enrollment.UserId,
+		CourseId:    enrollment.CourseId,
+		StudentName: user.Name,
+		CourseName:  course.Name,
+		CompletedAt: enrollment.CompletedAt.UTC().Truncate(time.Second),
+		IssuedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+
+	var pdf bytes.Buffer
+	if err := c.Renderer.Render(&pdf, result); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	sum := sha256.Sum256(pdf.Bytes())
+	result.Sha256 = hex.EncodeToString(sum[:])
+
+	if _, err := c.Files.Save(ctx, certificateKey(id), &pdf); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if err := c.CertificateRepo.Store(ctx, result); err != nil {
+		logger(ctx).Error(err)
+
+		if errDelete := c.Files.Delete(ctx, certificateKey(id)); errDelete != nil {
+			logger(ctx).Error(errDelete)
+		}
+
+		return nil, err
+	}
+
+	certificates.With("issued").Inc()
+
+	// The certificate is stored, a lost notification must not fail issuing.
+	err = c.Notifier.Notify(ctx, model.Notification{
+		UserId:  result.UserId,
+		Type:    constant.NotificationCertificate,
+		Subject: fmt.Sprintf("Your certificate for %s is ready", result.CourseName),
+		Body:    fmt.Sprintf("Certificate %s was issued for completing %s.", result.Id, result.CourseName),
+		Link:    fmt.Sprintf("/certificates/%s/pdf", result.Id),
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+	}
+
+	return &result, nil
+}
+
+// CourseCompleted is the CompletionHandler issuing certificates. Failures
+// are logged, the completion itself stands. GetMyCertificate issues the
+// certificates lost this way.
+func (c *Certificate) CourseCompleted(ctx context.Context, enrollment model.Enrollment) {
+	if _, err := c.Issue(ctx, enrollment); err != nil {
+		logger(ctx).WithField("course_id", enrollment.CourseId).Error(err)
+	}
+}
+
+// GetMyCertificate returns the certificates of the user, newest first,
+// revoked ones included. Completed courses without a certificate, when
+// issuing at completion failed, get it now.
+func (c *Certificate) GetMyCertificate(ctx context.Context, userID int) ([]model.Certificate, error) {
+	ctx, span := tracer.Start(ctx, "Certificate.GetMyCertificate")
+	defer span.End()
+
+	result, err := c.CertificateRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	// Failing again leaves them for the next listing.
+	if !c.issueMissing(ctx, userID, result) {
+		return result, nil
+	}
+
+	result, err = c.CertificateRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// issueMissing issues the certificates of the completed enrollments of the
+// user that have none and reports whether it issued any.
+func (c *Certificate) issueMissing(ctx context.Context, userID int, issued []model.Certificate) bool {
+	certified := make(map[int]bool, len(issued))
+	for _, certificate := range issued {
+		certified[certificate.CourseId] = true
+	}
+
+	courses, err := c.EnrollmentRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return false
+	}
+
+	issuedAny := false
+
+	for _, course := range courses {
+		if certified[course.Id] {
+			continue
+		}
+
+		enrollment, err := c.EnrollmentRepo.FindOne(ctx, userID, course.Id)
+		if err != nil {
+			logger(ctx).Error(err)
+			continue
+		}
+
+		if enrollment.CompletedAt == nil {
+			continue
+		}
+
+		if _, err := c.Issue(ctx, *enrollment); err != nil {
+			logger(ctx).WithField("course_id", course.Id).Error(err)
+			continue
+		}
+
+		issuedAny = true
+	}
+
+	return issuedAny
+}
+
+// OpenCertificate returns a certificate and its PDF to admins and to its
+// student. Students can't download revoked certificates. The caller closes
+// the content.
+func (c *Certificate) OpenCertificate(ctx context.Context, certificateID string, user *model.Token) (*model.Certificate, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "Certificate.OpenCertificate")
+	defer span.End()
+
+	result, err := c.findCertificate(ctx, certificateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.Role != constant.RoleAdmin {
+		if result.UserId != user.UserID {
+			return nil, nil, fmt.Errorf("%w: certificate %s of another user", model.ErrNotFound, certificateID)
+		}
+
+		if result.RevokedAt != nil {
+			return nil, nil, fmt.Errorf("%w: certificate %s is revoked", model.ErrForbidden, certificateID)
+		}
+	}
+
+	content, err := c.Files.Open(ctx, certificateKey(result.Id))
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, nil, err
+	}
+
+	return result, content, nil
+}
+
+// VerifyCertificate tells anyone holding a certificate id whether it was
+// issued here and is still valid.
+func (c *Certificate) VerifyCertificate(ctx context.Context, certificateID string) (*model.CertificateVerification, error) {
+	ctx, span := tracer.Start(ctx, "Certificate.VerifyCertificate")
+	defer span.End()
+
+	result, err := c.findCertificate(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CertificateVerification{
+		Valid:       result.RevokedAt == nil,
+		Certificate: *result,
+	}, nil
+}
+
+// RevokeCertificate revokes a certificate for the given reason. Revoking it
+// again keeps the first revocation.
+func (c *Certificate) RevokeCertificate(ctx context.Context, certificateID string, req model.RevokeRequest) (*model.Certificate, error) {
+	ctx, span := tracer.Start(ctx, "Certificate.RevokeCertificate")
+	defer span.End()
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > 255 {
+		return nil, fmt.Errorf("%w: revoke reason must have 1 to 255 characters", model.ErrBadParamInput)
+	}
+
+	if !validCertificateID(certificateID) {
+		return nil, fmt.Errorf("%w: certificate %q", model.ErrNotFound, certificateID)
+	}
+
+	done, err := c.CertificateRepo.Revoke(ctx, certificateID, time.Now().UTC().Truncate(time.Second), req.Reason)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if done {
+		certificates.With("revoked").Inc()
+	}
+
+	return c.findCertificate(ctx, certificateID)
+}
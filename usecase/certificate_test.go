@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/storage"
+)
+
+type rendererFunc func(w io.Writer, certificate model.Certificate) error
+
+func (f rendererFunc) Render(w io.Writer, certificate model.Certificate) error {
+	return f(w, certificate)
+}
+
+func TestGetMyCertificateIssuesMissing(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	courseID := f.course(f.category("Programming", 0), "Go Basics", 0)
+	otherID := f.course(f.category("Design", 0), "Color", 0)
+	ann := f.user("Ann", "ann@example.com")
+	f.enroll(ann, courseID)
+	f.enroll(ann, otherID)
+
+	completedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := f.repos.Enrollment.Complete(ctx, ann, courseID, completedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	enrollment, err := f.repos.Enrollment.FindOne(ctx, ann, courseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderErr := errors.New("template is broken")
+
+	renderer := rendererFunc(func(w io.Writer, certificate model.Certificate) error {
+		if renderErr != nil {
+			return renderErr
+		}
+
+		_, err := io.WriteString(w, "%PDF-1.4")
+		return err
+	})
+
+	notifier := notifierFunc(func(ctx context.Context, notification model.Notification) error {
+		return nil
+	})
+
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uc := NewCertificate(f.repos.Certificate, f.repos.User, f.repos.Course, f.repos.Enrollment, files, renderer, notifier)
+
+	uc.(*Certificate).CourseCompleted(ctx, *enrollment)
+
+	result, err := uc.GetMyCertificate(ctx, ann)
+	if err != nil || len(result) != 0 {
+		t.Fatalf("GetMyCertificate = %+v, %v, want no certificate while rendering fails", result, err)
+	}
+
+	renderErr = nil
+
+	result, err = uc.GetMyCertificate(ctx, ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].CourseId != courseID || !result[0].CompletedAt.Equal(completedAt) {
+		t.Fatalf("GetMyCertificate = %+v, want the certificate of course %d", result, courseID)
+	}
+
+	again, err := uc.GetMyCertificate(ctx, ann)
+	if err != nil || len(again) != 1 || again[0].Id != result[0].Id {
+		t.Errorf("GetMyCertificate again = %+v, %v, want the same certificate", again, err)
+	}
+}
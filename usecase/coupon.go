@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+// couponCode is the shape of coupon codes, which are kept upper case.
+var couponCode = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+type Coupon struct {
+	CouponRepo repository.CouponRepository
+	CourseRepo repository.CourseRepository
+	Transactor repository.Transactor
+}
+
+func NewCoupon(couponRepo repository.CouponRepository, courseRepo repository.CourseRepository, transactor repository.Transactor) CouponUsecae {
+	return &Coupon{
+		CouponRepo: couponRepo,
+		CourseRepo: courseRepo,
+		Transactor: transactor,
+	}
+}
+
+// normalizeCode returns the code as it is stored, codes are case
+// insensitive.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// uniqueIDs returns the ids sorted without duplicates.
+func uniqueIDs(ids []int) []int {
+	seen := map[int]bool{}
+	result := []int{}
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	sort.Ints(result)
+
+	return result
+}
+
+// couponFromRequest checks the settings of a coupon request.
+func couponFromRequest(req model.CouponRequest) (model.Coupon, error) {
+	coupon := model.Coupon{
+		Code:           normalizeCode(req.Code),
+		Type:           req.Type,
+		Value:          req.Value,
+		CourseIds:      uniqueIDs(req.CourseIds),
+		CategoryIds:    uniqueIDs(req.CategoryIds),
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		MinAmount:      req.MinAmount,
+	}
+
+	if !couponCode.MatchString(coupon.Code) {
+		return coupon, fmt.Errorf("%w: code must be 3 to 32 letters, digits, - or _", model.ErrBadParamInput)
+	}
+
+	switch coupon.Type {
+	case constant.CouponPercent:
+		if coupon.Value < 1 || coupon.Value > 100 {
+			return coupon, fmt.Errorf("%w: percentage %d out of 1 to 100", model.ErrBadParamInput, coupon.Value)
+		}
+	case constant.CouponFixed:
+		if coupon.Value < 1 {
+			return coupon, fmt.Errorf("%w: amount %d must be positive", model.ErrBadParamInput, coupon.Value)
+		}
+	default:
+		return coupon, fmt.Errorf("%w: type must be %s or %s", model.ErrBadParamInput, constant.CouponPercent, constant.CouponFixed)
+	}
+
+	if coupon.MaxUses < 0 || coupon.MaxUsesPerUser < 0 || coupon.MinAmount < 0 {
+		return coupon, fmt.Errorf("%w: limits can't be negative", model.ErrBadParamInput)
+	}
+
+	if req.StartsAt != nil {
+		at := req.StartsAt.UTC().Truncate(time.Second)
+		coupon.StartsAt = &at
+	}
+
+	if req.EndsAt != nil {
+		at := req.EndsAt.UTC().Truncate(time.Second)
+		coupon.EndsAt = &at
+	}
+
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt) {
+		return coupon, fmt.Errorf("%w: ends_at must be after starts_at", model.ErrBadParamInput)
+	}
+
+	return coupon, nil
+}
+
+// checkCoupon makes sure the courses and categories of the coupon exist and
+// no other active coupon has its code.
+func (c *Coupon) checkCoupon(ctx context.Context, coupon model.Coupon) error {
+	for _, courseID := range coupon.CourseIds {
+		_, err := c.CourseRepo.FindOne(ctx, courseID)
+		if errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("%w: course %d does not exist", model.ErrBadParamInput, courseID)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(coupon.CategoryIds) > 0 {
+		nodes, err := c.CourseRepo.FetchCategoryNode(ctx)
+		if err != nil {
+			return err
+		}
+
+		index := categoryIndex(nodes)
+		for _, categoryID := range coupon.CategoryIds {
+			if _, ok := index[categoryID]; !ok {
+				return fmt.Errorf("%w: category %d does not exist", model.ErrBadParamInput, categoryID)
+			}
+		}
+	}
+
+	other, err := c.CouponRepo.FindByCode(ctx, coupon.Code)
+	if err == nil && other.Id != coupon.Id {
+		return fmt.Errorf("%w: code %s is in use", model.ErrBadParamInput, coupon.Code)
+	}
+
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// GetCoupon returns the coupons, newest first.
+func (c *Coupon) GetCoupon(ctx context.Context) ([]model.Coupon, error) {
+	ctx, span := tracer.Start(ctx, "Coupon.GetCoupon")
+	defer span.End()
+
+	result, err := c.CouponRepo.Fetch(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Coupon) GetDetailCoupon(ctx context.Context, couponID int) (*model.Coupon, error) {
+	ctx, span := tracer.Start(ctx, "Coupon.GetDetailCoupon")
+	defer span.End()
+
+	result, err := c.CouponRepo.FindOne(ctx, couponID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Coupon) CreateCoupon(ctx context.Context, req model.CouponRequest) (*model.Coupon, error) {
+	ctx, span := tracer.Start(ctx, "Coupon.CreateCoupon")
+	defer span.End()
+
+	coupon, err := couponFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.Coupon
+
+	err = c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.checkCoupon(ctx, coupon); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		coupon.CreatedAt = now
+		coupon.UpdatedAt = now
+
+		id, err := c.CouponRepo.Store(ctx, coupon)
+		if err != nil {
+			return err
+		}
+
+		result, err = c.CouponRepo.FindOne(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateCoupon replaces the settings of a coupon. Its redemptions so far
+// keep counting against the new limits.
+func (c *Coupon) UpdateCoupon(ctx context.Context, couponID int, req model.CouponRequest) (*model.Coupon, error) {
+	ctx, span := tracer.Start(ctx, "Coupon.UpdateCoupon")
+	defer span.End()
+
+	coupon, err := couponFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon.Id = couponID
+
+	var result *model.Coupon
+
+	err = c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := c.CouponRepo.FindOne(ctx, couponID); err != nil {
+			return err
+		}
+
+		if err := c.checkCoupon(ctx, coupon); err != nil {
+			return err
+		}
+
+		coupon.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+		if err := c.CouponRepo.Update(ctx, coupon); err != nil {
+			return err
+		}
+
+		result, err = c.CouponRepo.FindOne(ctx, couponID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteCoupon stops a coupon from being applied. Orders that redeemed it
+// keep their discount.
+func (c *Coupon) DeleteCoupon(ctx context.Context, couponID int) error {
+	ctx, span := tracer.Start(ctx, "Coupon.DeleteCoupon")
+	defer span.End()
+
+	if _, err := c.CouponRepo.FindOne(ctx, couponID); err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if err := c.CouponRepo.Delete(ctx, couponID); err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// applyCoupon checks that the coupon with the code can be applied to the
+// cart of the user and takes its discount off the cart.
+func (o *Order) applyCoupon(ctx context.Context, userID int, cart *model.Cart, code string) (*model.Coupon, error) {
+	coupon, err := o.CouponRepo.FindByCode(ctx, normalizeCode(code))
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown code %q", model.ErrCoupon, code)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if coupon.StartsAt != nil && now.Before(*coupon.StartsAt) {
+		return nil, fmt.Errorf("%w: %s is valid from %s", model.ErrCoupon, coupon.Code, coupon.StartsAt.Format(time.RFC3339))
+	}
+
+	if coupon.EndsAt != nil && !now.Before(*coupon.EndsAt) {
+		return nil, fmt.Errorf("%w: %s expired", model.ErrCoupon, coupon.Code)
+	}
+
+	if coupon.MaxUses > 0 && coupon.UsedCount >= coupon.MaxUses {
+		return nil, fmt.Errorf("%w: %s is used up", model.ErrCoupon, coupon.Code)
+	}
+
+	if coupon.MaxUsesPerUser > 0 {
+		count, err := o.CouponRepo.CountRedemption(ctx, coupon.Id, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if count >= coupon.MaxUsesPerUser {
+			return nil, fmt.Errorf("%w: %s was used already", model.ErrCoupon, coupon.Code)
+		}
+	}
+
+	if cart.Subtotal.Amount < coupon.MinAmount {
+		return nil, fmt.Errorf("%w: %s needs an order of at least %d %s", model.ErrCoupon, coupon.Code, coupon.MinAmount, cart.Subtotal.Currency)
+	}
+
+	categories := map[int]bool{}
+
+	if len(coupon.CategoryIds) > 0 {
+		nodes, err := o.CourseRepo.FetchCategoryNode(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, categoryID := range coupon.CategoryIds {
+			for _, id := range descendantCategoryIDs(nodes, categoryID) {
+				categories[id] = true
+			}
+		}
+	}
+
+	courses := map[int]bool{}
+	for _, courseID := range coupon.CourseIds {
+		courses[courseID] = true
+	}
+
+	covered := []int{}
+
+	for i, item := range cart.Items {
+		everything := len(coupon.CourseIds) == 0 && len(coupon.CategoryIds) == 0
+		if everything || courses[item.CourseId] || categories[item.CategoryId] {
+			covered = append(covered, i)
+		}
+	}
+
+	if len(covered) == 0 {
+		return nil, fmt.Errorf("%w: %s covers none of the courses in the cart", model.ErrCoupon, coupon.Code)
+	}
+
+	discountItems(cart, coupon, covered)
+
+	return coupon, nil
+}
+
+// discountItems spreads the discount of the coupon over the covered items
+// of the cart. A percentage is taken off every item, rounded down. A fixed
+// amount is split in proportion to the prices, the cents lost to rounding
+// go to the first items, and never exceeds the covered prices.
+func discountItems(cart *model.Cart, coupon *model.Coupon, covered []int) {
+	items := cart.Items
+
+	switch coupon.Type {
+	case constant.CouponPercent:
+		for _, i := range covered {
+			items[i].Discount.Amount = items[i].Price.Amount * coupon.Value / 100
+		}
+	case constant.CouponFixed:
+		sum := 0
+		for _, i := range covered {
+			sum += items[i].Price.Amount
+		}
+
+		total := coupon.Value
+		if total > sum {
+			total = sum
+		}
+
+		left := total
+
+		for _, i := range covered {
+			items[i].Discount.Amount = total * items[i].Price.Amount / sum
+			left -= items[i].Discount.Amount
+		}
+
+		for _, i := range covered {
+			for left > 0 && items[i].Discount.Amount < items[i].Price.Amount {
+				items[i].Discount.Amount++
+				left--
+			}
+		}
+	}
+
+	cart.Coupon = coupon.Code
+	cart.Discount.Amount = 0
+
+	for _, item := range items {
+		cart.Discount.Amount += item.Discount.Amount
+	}
+
+	cart.Total.Amount = cart.Subtotal.Amount - cart.Discount.Amount
+}
+
+// redeem records the use of the coupon by the order. Redeem checks the
+// limits again, as concurrent checkouts may have used the coupon up since
+// it was applied.
+func (o *Order) redeem(ctx context.Context, coupon *model.Coupon, order *model.Order) error {
+	done, err := o.CouponRepo.Redeem(ctx, model.CouponRedemption{
+		CouponId:  coupon.Id,
+		UserId:    order.UserId,
+		OrderId:   order.Id,
+		Discount:  order.Discount,
+		CreatedAt: order.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !done {
+		return fmt.Errorf("%w: %s is used up", model.ErrCoupon, coupon.Code)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func TestDiscountItems(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		couponType string
+		value      int
+		prices     []int
+		covered    []int
+		want       []int
+	}{
+		{"percent rounds down", constant.CouponPercent, 10, []int{1000, 333}, []int{0, 1}, []int{100, 33}},
+		{"percent of covered items", constant.CouponPercent, 50, []int{1000, 333}, []int{1}, []int{0, 166}},
+		{"percent 100", constant.CouponPercent, 100, []int{1000, 333}, []int{0, 1}, []int{1000, 333}},
+		{"fixed in proportion", constant.CouponFixed, 500, []int{1000, 333}, []int{0, 1}, []int{376, 124}},
+		{"fixed cents to the first items", constant.CouponFixed, 2, []int{1000, 1000, 1000}, []int{0, 1, 2}, []int{2, 0, 0}},
+		{"fixed over the covered prices", constant.CouponFixed, 2000, []int{1000, 333}, []int{0, 1}, []int{1000, 333}},
+		{"fixed over one covered item", constant.CouponFixed, 500, []int{1000, 333}, []int{1}, []int{0, 333}},
+		{"fixed skips used up items", constant.CouponFixed, 1334, []int{1, 1000, 333}, []int{0, 1, 2}, []int{1, 1000, 333}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cart := &model.Cart{}
+			for _, price := range c.prices {
+				cart.Items = append(cart.Items, model.CartItem{Price: model.Money{Amount: price}})
+				cart.Subtotal.Amount += price
+			}
+
+			discountItems(cart, &model.Coupon{Code: "SALE", Type: c.couponType, Value: c.value}, c.covered)
+
+			got, sum := []int{}, 0
+			for _, item := range cart.Items {
+				got = append(got, item.Discount.Amount)
+				sum += item.Discount.Amount
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("discounts = %v, want %v", got, c.want)
+			}
+
+			if cart.Coupon != "SALE" || cart.Discount.Amount != sum || cart.Total.Amount != cart.Subtotal.Amount-sum {
+				t.Errorf("cart = %s, discount %d, total %d of %d, want the sum %d of the discounts taken off", cart.Coupon, cart.Discount.Amount, cart.Total.Amount, cart.Subtotal.Amount, sum)
+			}
+		})
+	}
+}
+
+func TestApplyCoupon(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	programming := f.category("Programming", 0)
+	golang := f.category("Go", programming)
+	design := f.category("Design", 0)
+
+	goCourse := f.course(golang, "Go Basics", 1500)
+	rustCourse := f.course(programming, "Rust Basics", 2500)
+	figmaCourse := f.course(design, "Figma Basics", 1000)
+	otherCourse := f.course(design, "Sketch Basics", 1000)
+
+	ann := f.user("Ann", "ann@example.com")
+
+	orders := f.orders(nil)
+	coupons := NewCoupon(f.repos.Coupon, f.repos.Course, f.repos.Transactor)
+
+	for _, id := range []int{goCourse, rustCourse, figmaCourse} {
+		if _, err := orders.AddToCart(ctx, ann, model.CartRequest{CourseId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	for _, c := range []struct {
+		name    string
+		coupon  model.CouponRequest
+		code    string
+		want    map[int]int
+		wantErr error
+	}{
+		{name: "everything", coupon: model.CouponRequest{Code: "ALL10", Type: constant.CouponPercent, Value: 10}, want: map[int]int{goCourse: 150, rustCourse: 250, figmaCourse: 100}},
+		{name: "code in lower case", coupon: model.CouponRequest{Code: "LOWER", Type: constant.CouponPercent, Value: 10}, code: " lower ", want: map[int]int{goCourse: 150, rustCourse: 250, figmaCourse: 100}},
+		{name: "course", coupon: model.CouponRequest{Code: "RUST", Type: constant.CouponFixed, Value: 1000, CourseIds: []int{rustCourse}}, want: map[int]int{rustCourse: 1000}},
+		{name: "category with its subcategories", coupon: model.CouponRequest{Code: "CODE", Type: constant.CouponPercent, Value: 20, CategoryIds: []int{programming}}, want: map[int]int{goCourse: 300, rustCourse: 500}},
+		{name: "subcategory only", coupon: model.CouponRequest{Code: "GOLANG", Type: constant.CouponPercent, Value: 20, CategoryIds: []int{golang}}, want: map[int]int{goCourse: 300}},
+		{name: "course or category", coupon: model.CouponRequest{Code: "MIX", Type: constant.CouponFixed, Value: 250, CourseIds: []int{figmaCourse}, CategoryIds: []int{golang}}, want: map[int]int{goCourse: 150, figmaCourse: 100}},
+		{name: "covers nothing in the cart", coupon: model.CouponRequest{Code: "SKETCH", Type: constant.CouponPercent, Value: 10, CourseIds: []int{otherCourse}}, wantErr: model.ErrCoupon},
+		{name: "min amount reached", coupon: model.CouponRequest{Code: "BIG", Type: constant.CouponFixed, Value: 500, MinAmount: 5000}, want: map[int]int{goCourse: 150, rustCourse: 250, figmaCourse: 100}},
+		{name: "min amount missed", coupon: model.CouponRequest{Code: "BIGGER", Type: constant.CouponFixed, Value: 500, MinAmount: 5001}, wantErr: model.ErrCoupon},
+		{name: "not started", coupon: model.CouponRequest{Code: "SOON", Type: constant.CouponPercent, Value: 10, StartsAt: &future}, wantErr: model.ErrCoupon},
+		{name: "started", coupon: model.CouponRequest{Code: "NOW", Type: constant.CouponPercent, Value: 10, StartsAt: &past, EndsAt: &future}, want: map[int]int{goCourse: 150, rustCourse: 250, figmaCourse: 100}},
+		{name: "expired", coupon: model.CouponRequest{Code: "OLD", Type: constant.CouponPercent, Value: 10, EndsAt: &past}, wantErr: model.ErrCoupon},
+		{name: "unknown code", code: "NOPE", wantErr: model.ErrCoupon},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if c.coupon.Code != "" {
+				if _, err := coupons.CreateCoupon(ctx, c.coupon); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			code := c.code
+			if code == "" {
+				code = c.coupon.Code
+			}
+
+			cart, err := orders.GetCart(ctx, ann, code)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("GetCart returned %v, want %v", err, c.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, sum := map[int]int{}, 0
+			for _, item := range cart.Items {
+				if item.Discount.Amount > 0 {
+					got[item.CourseId] = item.Discount.Amount
+				}
+
+				sum += item.Discount.Amount
+			}
+
+			if !reflect.DeepEqual(got, c.want) || cart.Discount.Amount != sum || cart.Total.Amount != 5000-sum {
+				t.Errorf("GetCart discounts = %v, discount %d, total %d, want %v", got, cart.Discount.Amount, cart.Total.Amount, c.want)
+			}
+		})
+	}
+}
+
+// couponStep checks a new course out with the coupon as the user, then
+// fails the order when fail is set.
+type couponStep struct {
+	user    string
+	fail    bool
+	wantErr error
+}
+
+func TestCouponUsageLimits(t *testing.T) {
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		name   string
+		coupon model.CouponRequest
+		steps  []couponStep
+	}{
+		{
+			name:   "max uses",
+			coupon: model.CouponRequest{Code: "ONCE", Type: constant.CouponPercent, Value: 10, MaxUses: 1},
+			steps:  []couponStep{{user: "ann"}, {user: "bob", wantErr: model.ErrCoupon}},
+		},
+		{
+			name:   "failed order gives the use back",
+			coupon: model.CouponRequest{Code: "ONCE", Type: constant.CouponPercent, Value: 10, MaxUses: 1},
+			steps:  []couponStep{{user: "ann", fail: true}, {user: "bob"}, {user: "ann", wantErr: model.ErrCoupon}},
+		},
+		{
+			name:   "max uses per user",
+			coupon: model.CouponRequest{Code: "EACH", Type: constant.CouponPercent, Value: 10, MaxUses: 3, MaxUsesPerUser: 1},
+			steps:  []couponStep{{user: "ann"}, {user: "ann", wantErr: model.ErrCoupon}, {user: "bob"}},
+		},
+		{
+			name:   "unlimited",
+			coupon: model.CouponRequest{Code: "FREE4ALL", Type: constant.CouponPercent, Value: 10},
+			steps:  []couponStep{{user: "ann"}, {user: "ann"}, {user: "bob"}},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			category := f.category("Programming", 0)
+			users := map[string]int{
+				"ann": f.user("Ann", "ann@example.com"),
+				"bob": f.user("Bob", "bob@example.com"),
+			}
+
+			orders := f.orders(nil)
+
+			if _, err := NewCoupon(f.repos.Coupon, f.repos.Course, f.repos.Transactor).CreateCoupon(ctx, c.coupon); err != nil {
+				t.Fatal(err)
+			}
+
+			used := 0
+
+			for i, step := range c.steps {
+				course := f.course(category, "Course", 1000+i)
+
+				order, err := f.checkout(orders, users[step.user], c.coupon.Code, course)
+				if step.wantErr != nil {
+					if !errors.Is(err, step.wantErr) {
+						t.Fatalf("step %d: Checkout returned %v, want %v", i, err, step.wantErr)
+					}
+
+					if err := orders.CartRepo.Clear(ctx, users[step.user]); err != nil {
+						t.Fatal(err)
+					}
+
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+
+				if order.Coupon != c.coupon.Code || order.Discount.Amount != (1000+i)/10 {
+					t.Errorf("step %d: order with %q and discount %d, want the coupon", i, order.Coupon, order.Discount.Amount)
+				}
+
+				used++
+
+				if step.fail {
+					if _, err := orders.UpdateStatus(ctx, order.Id, model.StatusRequest{Status: constant.OrderFailed}); err != nil {
+						t.Fatal(err)
+					}
+
+					used--
+				}
+			}
+
+			coupon, err := f.repos.Coupon.FindByCode(ctx, c.coupon.Code)
+			if err != nil || coupon.UsedCount != used {
+				t.Errorf("FindByCode = %+v, %v, want %d uses", coupon, err, used)
+			}
+		})
+	}
+}
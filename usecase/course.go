@@ -2,17 +2,15 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/egaevan/online-learning/constant"
 	"github.com/egaevan/online-learning/model"
 	"github.com/egaevan/online-learning/repository"
-	log "github.com/sirupsen/logrus"
-)
-
-const (
-	lowPrice  = "ORDER BY price ASC"
-	highPrice = "ORDER BY price DESC"
-	freePrice = "AND price = 0"
 )
 
 type responseError struct {
@@ -20,31 +18,77 @@ type responseError struct {
 }
 
 type Course struct {
-	CourseRepo repository.CourseRepository
+	CourseRepo     repository.CourseRepository
+	EnrollmentRepo repository.EnrollmentRepository
+	ReviewRepo     repository.ReviewRepository
+	Transactor     repository.Transactor
 }
 
-func NewCourse(courseRepo repository.CourseRepository) CourseUsecae {
+func NewCourse(courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, reviewRepo repository.ReviewRepository, transactor repository.Transactor) CourseUsecae {
 	return &Course{
-		CourseRepo: courseRepo,
+		CourseRepo:     courseRepo,
+		EnrollmentRepo: enrollmentRepo,
+		ReviewRepo:     reviewRepo,
+		Transactor:     transactor,
 	}
 }
 
-func (c *Course) GetDetailCourse(ctx context.Context, CourseID int) (*model.CourseDetail, error) {
+// GetDetailCourse returns the course with its curriculum and rating.
+// userID, 0 for anonymous users, selects the enrollment shown with the
+// course.
+func (c *Course) GetDetailCourse(ctx context.Context, CourseID int, userID int) (*model.CourseDetail, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetDetailCourse")
+	defer span.End()
 
 	prod, err := c.CourseRepo.FindOne(ctx, CourseID)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	nodes, err := c.CourseRepo.FetchCategoryNode(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	prod.Breadcrumb = categoryBreadcrumb(nodes, prod.Category.Id)
+	if len(prod.Breadcrumb) > 0 {
+		prod.Category = prod.Breadcrumb[len(prod.Breadcrumb)-1]
+	}
+
+	curriculum, err := c.CourseRepo.FetchCurriculum(ctx, CourseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	prod.Curriculum = outline(curriculum)
+
+	prod.Rating.Distribution, err = c.ReviewRepo.Distribution(ctx, CourseID)
+	if err != nil {
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
+	if userID != 0 {
+		prod.Enrollment, err = c.EnrollmentRepo.FindOne(ctx, userID, CourseID)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+	}
+
 	return prod, nil
 }
 
 func (c *Course) GetCourse(ctx context.Context) ([]model.Course, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetCourse")
+	defer span.End()
 
 	course, err := c.CourseRepo.Fetch(ctx)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -54,9 +98,12 @@ func (c *Course) GetCourse(ctx context.Context) ([]model.Course, error) {
 		var course model.Course
 
 		course.Id = v.Id
+		course.CategoryId = v.CategoryId
 		course.Name = v.Name
 		course.Price = v.Price
-		course.Count = v.Count
+		course.EnrollmentCount = v.EnrollmentCount
+		course.RatingAverage = v.RatingAverage
+		course.RatingCount = v.RatingCount
 
 		courseList = append(courseList, course)
 	}
@@ -65,10 +112,12 @@ func (c *Course) GetCourse(ctx context.Context) ([]model.Course, error) {
 }
 
 func (c *Course) SearchCourse(ctx context.Context, search string) ([]model.Course, error) {
+	ctx, span := tracer.Start(ctx, "Course.SearchCourse")
+	defer span.End()
 
 	course, err := c.CourseRepo.Search(ctx, search)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -78,9 +127,12 @@ func (c *Course) SearchCourse(ctx context.Context, search string) ([]model.Cours
 		var course model.Course
 
 		course.Id = v.Id
+		course.CategoryId = v.CategoryId
 		course.Name = v.Name
 		course.Price = v.Price
-		course.Count = v.Count
+		course.EnrollmentCount = v.EnrollmentCount
+		course.RatingAverage = v.RatingAverage
+		course.RatingCount = v.RatingCount
 
 		courseList = append(courseList, course)
 	}
@@ -89,19 +141,16 @@ func (c *Course) SearchCourse(ctx context.Context, search string) ([]model.Cours
 }
 
 func (c *Course) SortCourse(ctx context.Context, sort string) ([]model.Course, error) {
-	if sort == "high" {
-		sort = highPrice
-	} else if sort == "low" {
-		sort = lowPrice
-	} else if sort == "free" {
-		sort = freePrice
-	} else {
-		return nil, fmt.Errorf("internal error")
+	ctx, span := tracer.Start(ctx, "Course.SortCourse")
+	defer span.End()
+
+	if sort != constant.SortHighPrice && sort != constant.SortLowPrice && sort != constant.SortFree && sort != constant.SortRating {
+		return nil, model.ErrBadParamInput
 	}
 
 	course, err := c.CourseRepo.Sort(ctx, sort)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -111,9 +160,12 @@ func (c *Course) SortCourse(ctx context.Context, sort string) ([]model.Course, e
 		var course model.Course
 
 		course.Id = v.Id
+		course.CategoryId = v.CategoryId
 		course.Name = v.Name
 		course.Price = v.Price
-		course.Count = v.Count
+		course.EnrollmentCount = v.EnrollmentCount
+		course.RatingAverage = v.RatingAverage
+		course.RatingCount = v.RatingCount
 
 		courseList = append(courseList, course)
 	}
@@ -122,32 +174,113 @@ func (c *Course) SortCourse(ctx context.Context, sort string) ([]model.Course, e
 }
 
 func (c *Course) SendCourse(ctx context.Context, course model.Course) (*model.Course, error) {
+	ctx, span := tracer.Start(ctx, "Course.SendCourse")
+	defer span.End()
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		id, err := c.CourseRepo.Store(ctx, course)
+		if err != nil {
+			return err
+		}
+
+		course.Id = id
 
-	err := c.CourseRepo.Store(ctx, course)
+		return c.CourseRepo.RecomputeCategoryCount(ctx, []int{course.CategoryId})
+	})
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
 	return &course, nil
 }
 
-func (c *Course) UpdateCourse(ctx context.Context, course model.CourseUpdate, courseID int) (*model.CourseUpdate, error) {
-
-	err := c.CourseRepo.Update(ctx, course, courseID)
+// UpdateCourse applies a JSON Merge Patch to the course. Fields missing from
+// the patch keep their value. ifMatch is the version the client expects, 0
+// skips the check. A version mismatch, before or during the update, returns
+// model.ErrConflict.
+func (c *Course) UpdateCourse(ctx context.Context, patch []byte, courseID int, ifMatch int) (*model.CourseDetail, error) {
+	ctx, span := tracer.Start(ctx, "Course.UpdateCourse")
+	defer span.End()
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindOne(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		if ifMatch != 0 && current.Version != ifMatch {
+			return model.ErrConflict
+		}
+
+		target, err := json.Marshal(model.CourseUpdate{
+			CategoryId: current.Category.Id,
+			Name:       current.Name,
+			Price:      current.Price,
+		})
+		if err != nil {
+			return err
+		}
+
+		merged, err := mergePatch(target, patch)
+		if err != nil {
+			return fmt.Errorf("%w: %s", model.ErrBadParamInput, err.Error())
+		}
+
+		update := model.CourseUpdate{}
+		if err := decodeStrict(merged, &update); err != nil {
+			return fmt.Errorf("%w: %s", model.ErrBadParamInput, err.Error())
+		}
+
+		if update.Name == "" || update.Price < 0 || update.CategoryId <= 0 {
+			return model.ErrBadParamInput
+		}
+
+		update.Version = current.Version
+
+		if err := c.CourseRepo.Update(ctx, update, courseID); err != nil {
+			return err
+		}
+
+		// The course may have moved, both categories are counted again.
+		return c.CourseRepo.RecomputeCategoryCount(ctx, []int{current.Category.Id, update.CategoryId})
+	})
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
-	return &course, nil
+	return c.GetDetailCourse(ctx, courseID, 0)
 }
 
-func (c *Course) DeleteCourse(ctx context.Context, courseID int) error {
+// DeleteCourse soft deletes the course. ifMatch works like in UpdateCourse.
+func (c *Course) DeleteCourse(ctx context.Context, courseID int, ifMatch int) error {
+	ctx, span := tracer.Start(ctx, "Course.DeleteCourse")
+	defer span.End()
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindOne(ctx, courseID)
+		if errors.Is(err, model.ErrNotFound) && ifMatch == 0 {
+			// Deleted already, there is nothing to count again.
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if ifMatch != 0 && current.Version != ifMatch {
+			return model.ErrConflict
+		}
+
+		if err := c.CourseRepo.Delete(ctx, courseID); err != nil {
+			return err
+		}
 
-	err := c.CourseRepo.Delete(ctx, courseID)
+		return c.CourseRepo.RecomputeCategoryCount(ctx, []int{current.Category.Id})
+	})
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return err
 	}
 
@@ -155,10 +288,21 @@ func (c *Course) DeleteCourse(ctx context.Context, courseID int) error {
 }
 
 func (c *Course) GetStatistic(ctx context.Context) (*model.StatisticResponse, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetStatistic")
+	defer span.End()
 
-	stat, err := c.CourseRepo.Statistic(ctx)
+	var stat *model.StatisticResponse
+
+	// Read committed would take a snapshot per total, repeatable read takes
+	// one for all three.
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+	err := c.Transactor.WithinTransactionOptions(ctx, opts, func(ctx context.Context) (err error) {
+		stat, err = c.CourseRepo.Statistic(ctx)
+		return err
+	})
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -166,10 +310,12 @@ func (c *Course) GetStatistic(ctx context.Context) (*model.StatisticResponse, er
 }
 
 func (c *Course) GetCategory(ctx context.Context) ([]model.CategoryDetail, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetCategory")
+	defer span.End()
 
 	category, err := c.CourseRepo.FetchCategory(ctx)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -180,7 +326,9 @@ func (c *Course) GetCategory(ctx context.Context) ([]model.CategoryDetail, error
 
 		category.Id = v.Id
 		category.Name = v.Name
-		category.Count = v.Count
+		category.ParentId = v.ParentId
+		category.CourseCount = v.CourseCount
+		category.EnrollmentCount = v.EnrollmentCount
 
 		categoryList = append(categoryList, category)
 	}
@@ -188,11 +336,17 @@ func (c *Course) GetCategory(ctx context.Context) ([]model.CategoryDetail, error
 	return categoryList, nil
 }
 
-func (c *Course) GetPopularCategory(ctx context.Context, limit int) ([]model.CategoryDetail, error) {
+func (c *Course) GetPopularCategory(ctx context.Context, limit int, window time.Duration) ([]model.CategoryDetail, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetPopularCategory")
+	defer span.End()
+
+	if limit <= 0 || window <= 0 {
+		return nil, model.ErrBadParamInput
+	}
 
-	category, err := c.CourseRepo.FetchPopularCategory(ctx, limit)
+	category, err := c.CourseRepo.FetchPopularCategory(ctx, limit, time.Now().Add(-window))
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return nil, err
 	}
 
@@ -203,10 +357,40 @@ func (c *Course) GetPopularCategory(ctx context.Context, limit int) ([]model.Cat
 
 		category.Id = v.Id
 		category.Name = v.Name
-		category.Count = v.Count
+		category.ParentId = v.ParentId
+		category.CourseCount = v.CourseCount
+		category.EnrollmentCount = v.EnrollmentCount
 
 		categoryList = append(categoryList, category)
 	}
 
 	return categoryList, nil
 }
+
+func (c *Course) RecomputeCount(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Course.RecomputeCount")
+	defer span.End()
+
+	err := c.CourseRepo.RecomputeCount(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// RunCountRecompute refreshes the counters every interval until ctx is done.
+func (c *Course) RunCountRecompute(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RecomputeCount(ctx)
+		}
+	}
+}
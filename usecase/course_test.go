@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func TestCourseListingRating(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	category := f.category("Programming", 0)
+	golang := f.course(category, "Go Basics", 1500)
+	rust := f.course(category, "Rust Basics", 2500)
+	elixir := f.course(category, "Elixir Basics", 2000)
+
+	ann := f.user("Ann", "ann@example.com")
+	bob := f.user("Bob", "bob@example.com")
+
+	f.review(ann, golang, 3)
+	f.review(ann, rust, 5)
+	f.review(bob, rust, 4)
+
+	type rating struct {
+		id      int
+		average float64
+		count   int
+	}
+
+	byRating := []rating{{rust, 4.5, 2}, {golang, 3, 1}, {elixir, 0, 0}}
+
+	uc := NewCourse(f.repos.Course, f.repos.Enrollment, f.repos.Review, f.repos.Transactor)
+
+	for _, c := range []struct {
+		name string
+		list func() ([]model.Course, error)
+	}{
+		{"SortCourse", func() ([]model.Course, error) { return uc.SortCourse(ctx, constant.SortRating) }},
+		{"GetCourse", func() ([]model.Course, error) { return uc.GetCourse(ctx) }},
+		{"SearchCourse", func() ([]model.Course, error) { return uc.SearchCourse(ctx, "basics") }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			course, err := c.list()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[int]rating{}
+			for _, v := range course {
+				got[v.Id] = rating{v.Id, v.RatingAverage, v.RatingCount}
+			}
+
+			for _, want := range byRating {
+				if got[want.id] != want {
+					t.Errorf("course %d = %+v, want %+v", want.id, got[want.id], want)
+				}
+			}
+
+			if c.name != "SortCourse" {
+				return
+			}
+
+			for i, want := range byRating {
+				if i >= len(course) || course[i].Id != want.id {
+					t.Errorf("course %d sorted by rating = %+v, want %d", i, course, want.id)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCourseCategoryCount(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	programming := f.category("Programming", 0)
+	design := f.category("Design", 0)
+
+	uc := NewCourse(f.repos.Course, f.repos.Enrollment, f.repos.Review, f.repos.Transactor)
+
+	count := func() map[int]int {
+		t.Helper()
+
+		category, err := uc.GetCategory(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := map[int]int{}
+		for _, c := range category {
+			result[c.Id] = c.CourseCount
+		}
+
+		return result
+	}
+
+	golang, err := uc.SendCourse(ctx, model.Course{CategoryId: programming, Name: "Go Basics", Price: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.SendCourse(ctx, model.Course{CategoryId: programming, Name: "Rust Basics", Price: 2500}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		name string
+		run  func() error
+		want map[int]int
+	}{
+		{"SendCourse", func() error { return nil }, map[int]int{programming: 2, design: 0}},
+		{"UpdateCourse", func() error {
+			_, err := uc.UpdateCourse(ctx, []byte(fmt.Sprintf(`{"category_id": %d}`, design)), golang.Id, 0)
+			return err
+		}, map[int]int{programming: 1, design: 1}},
+		{"DeleteCourse", func() error { return uc.DeleteCourse(ctx, golang.Id, 0) }, map[int]int{programming: 1, design: 0}},
+		{"DeleteCourse again", func() error { return uc.DeleteCourse(ctx, golang.Id, 0) }, map[int]int{programming: 1, design: 0}},
+	} {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if got := count(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("course count after %s = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestUpdateCourse(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		patch   string
+		ifMatch int
+		want    model.CourseUpdate
+		wantErr error
+	}{
+		{name: "partial patch", patch: `{"price": 2000}`, want: model.CourseUpdate{Name: "Go Basics", Price: 2000}},
+		{name: "current version", patch: `{"name": "Go"}`, ifMatch: 1, want: model.CourseUpdate{Name: "Go", Price: 1500}},
+		{name: "stale version", patch: `{"name": "Go"}`, ifMatch: 2, wantErr: model.ErrConflict},
+		{name: "required field removed", patch: `{"name": null}`, wantErr: model.ErrBadParamInput},
+		{name: "unknown field", patch: `{"title": "Go"}`, wantErr: model.ErrBadParamInput},
+		{name: "negative price", patch: `{"price": -1}`, wantErr: model.ErrBadParamInput},
+		{name: "malformed patch", patch: `{"price":`, wantErr: model.ErrBadParamInput},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+			ctx := context.Background()
+
+			category := f.category("Programming", 0)
+			id := f.course(category, "Go Basics", 1500)
+
+			uc := NewCourse(f.repos.Course, f.repos.Enrollment, f.repos.Review, f.repos.Transactor)
+
+			detail, err := uc.UpdateCourse(ctx, []byte(c.patch), id, c.ifMatch)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("UpdateCourse returned %v, want %v", err, c.wantErr)
+				}
+
+				detail, err = uc.GetDetailCourse(ctx, id, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if detail.Version != 1 || detail.Name != "Go Basics" || detail.Price != 1500 {
+					t.Errorf("GetDetailCourse after a failed update = %+v, want it unchanged", detail)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if detail.Name != c.want.Name || detail.Price != c.want.Price || detail.Category.Id != category || detail.Version != 2 {
+				t.Errorf("UpdateCourse = %+v, want %+v in version 2", detail, c.want)
+			}
+		})
+	}
+}
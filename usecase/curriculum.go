@@ -0,0 +1,454 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+// sectionUpdate and lessonUpdate are the fields a merge patch may change.
+type sectionUpdate struct {
+	Title string `json:"title"`
+}
+
+type lessonUpdate struct {
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Content     string `json:"content"`
+	Duration    int    `json:"duration"`
+	FreePreview bool   `json:"free_preview"`
+	Optional    bool   `json:"optional"`
+}
+
+// validLesson checks the lesson type and that every type but text, quiz and
+// assignment points at an http(s) URL.
+func validLesson(l model.Lesson) bool {
+	if l.Title == "" || l.Content == "" || l.Duration < 0 {
+		return false
+	}
+
+	switch l.Type {
+	case constant.LessonText, constant.LessonQuiz, constant.LessonAssignment:
+		return true
+	case constant.LessonVideo, constant.LessonFile, constant.LessonLink:
+		u, err := url.Parse(l.Content)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	default:
+		return false
+	}
+}
+
+// outline drops the lesson content from the curriculum, the content is
+// served lesson by lesson to the users allowed to see it.
+func outline(curriculum []model.Section) []model.Section {
+	for i := range curriculum {
+		for j := range curriculum[i].Lessons {
+			curriculum[i].Lessons[j].Content = ""
+		}
+	}
+
+	return curriculum
+}
+
+// samePermutation reports whether ids lists every id of current exactly once.
+func samePermutation(ids []int, current []int) bool {
+	if len(ids) != len(current) {
+		return false
+	}
+
+	seen := map[int]bool{}
+	for _, id := range current {
+		seen[id] = true
+	}
+
+	for _, id := range ids {
+		if !seen[id] {
+			return false
+		}
+
+		delete(seen, id)
+	}
+
+	return true
+}
+
+// GetLesson returns a lesson with its content. Free preview lessons are
+// public, the other lessons are served to admins and enrolled users.
+func (c *Course) GetLesson(ctx context.Context, lessonID int, user *model.Token) (*model.Lesson, error) {
+	ctx, span := tracer.Start(ctx, "Course.GetLesson")
+	defer span.End()
+
+	lesson, err := c.CourseRepo.FindLesson(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if lesson.FreePreview || (user != nil && user.Role == constant.RoleAdmin) {
+		return lesson, nil
+	}
+
+	if user == nil {
+		return nil, model.ErrForbidden
+	}
+
+	_, err = c.EnrollmentRepo.FindOne(ctx, user.UserID, lesson.CourseId)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, model.ErrForbidden
+	}
+
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return lesson, nil
+}
+
+func (c *Course) CreateSection(ctx context.Context, courseID int, section model.Section) (*model.Section, error) {
+	ctx, span := tracer.Start(ctx, "Course.CreateSection")
+	defer span.End()
+
+	if section.Title == "" {
+		return nil, model.ErrBadParamInput
+	}
+
+	var result *model.Section
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := c.CourseRepo.FindOne(ctx, courseID); err != nil {
+			return err
+		}
+
+		section.CourseId = courseID
+
+		id, err := c.CourseRepo.StoreSection(ctx, section)
+		if err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, courseID); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FindSection(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateSection applies a JSON Merge Patch to the section.
+func (c *Course) UpdateSection(ctx context.Context, patch []byte, sectionID int) (*model.Section, error) {
+	ctx, span := tracer.Start(ctx, "Course.UpdateSection")
+	defer span.End()
+
+	var result *model.Section
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindSection(ctx, sectionID)
+		if err != nil {
+			return err
+		}
+
+		update := sectionUpdate{Title: current.Title}
+		if err := applyPatch(&update, patch); err != nil {
+			return err
+		}
+
+		if update.Title == "" {
+			return model.ErrBadParamInput
+		}
+
+		current.Title = update.Title
+
+		if err := c.CourseRepo.UpdateSection(ctx, *current); err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, current.CourseId); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FindSection(ctx, sectionID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteSection soft deletes the section and its lessons.
+func (c *Course) DeleteSection(ctx context.Context, sectionID int) error {
+	ctx, span := tracer.Start(ctx, "Course.DeleteSection")
+	defer span.End()
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindSection(ctx, sectionID)
+		if err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.DeleteSection(ctx, sectionID); err != nil {
+			return err
+		}
+
+		return c.CourseRepo.BumpVersion(ctx, current.CourseId)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ReorderSection puts the sections of the course in the order of
+// sectionIDs, which must list every section of the course once.
+func (c *Course) ReorderSection(ctx context.Context, courseID int, sectionIDs []int) ([]model.Section, error) {
+	ctx, span := tracer.Start(ctx, "Course.ReorderSection")
+	defer span.End()
+
+	var result []model.Section
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := c.CourseRepo.FindOne(ctx, courseID); err != nil {
+			return err
+		}
+
+		curriculum, err := c.CourseRepo.FetchCurriculum(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		current := make([]int, 0, len(curriculum))
+		for _, s := range curriculum {
+			current = append(current, s.Id)
+		}
+
+		if !samePermutation(sectionIDs, current) {
+			return model.ErrBadParamInput
+		}
+
+		if err := c.CourseRepo.ReorderSection(ctx, courseID, sectionIDs); err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, courseID); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FetchCurriculum(ctx, courseID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return outline(result), nil
+}
+
+func (c *Course) CreateLesson(ctx context.Context, sectionID int, lesson model.Lesson) (*model.Lesson, error) {
+	ctx, span := tracer.Start(ctx, "Course.CreateLesson")
+	defer span.End()
+
+	if !validLesson(lesson) {
+		return nil, model.ErrBadParamInput
+	}
+
+	var result *model.Lesson
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		section, err := c.CourseRepo.FindSection(ctx, sectionID)
+		if err != nil {
+			return err
+		}
+
+		lesson.SectionId = sectionID
+
+		id, err := c.CourseRepo.StoreLesson(ctx, lesson)
+		if err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, section.CourseId); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FindLesson(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateLesson applies a JSON Merge Patch to the lesson.
+func (c *Course) UpdateLesson(ctx context.Context, patch []byte, lessonID int) (*model.Lesson, error) {
+	ctx, span := tracer.Start(ctx, "Course.UpdateLesson")
+	defer span.End()
+
+	var result *model.Lesson
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindLesson(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		update := lessonUpdate{
+			Title:       current.Title,
+			Type:        current.Type,
+			Content:     current.Content,
+			Duration:    current.Duration,
+			FreePreview: current.FreePreview,
+			Optional:    current.Optional,
+		}
+		if err := applyPatch(&update, patch); err != nil {
+			return err
+		}
+
+		current.Title = update.Title
+		current.Type = update.Type
+		current.Content = update.Content
+		current.Duration = update.Duration
+		current.FreePreview = update.FreePreview
+		current.Optional = update.Optional
+
+		if !validLesson(*current) {
+			return model.ErrBadParamInput
+		}
+
+		if err := c.CourseRepo.UpdateLesson(ctx, *current); err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, current.CourseId); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FindLesson(ctx, lessonID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Course) DeleteLesson(ctx context.Context, lessonID int) error {
+	ctx, span := tracer.Start(ctx, "Course.DeleteLesson")
+	defer span.End()
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := c.CourseRepo.FindLesson(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.DeleteLesson(ctx, lessonID); err != nil {
+			return err
+		}
+
+		return c.CourseRepo.BumpVersion(ctx, current.CourseId)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ReorderLesson puts the lessons of the section in the order of lessonIDs,
+// which must list every lesson of the section once.
+func (c *Course) ReorderLesson(ctx context.Context, sectionID int, lessonIDs []int) (*model.Section, error) {
+	ctx, span := tracer.Start(ctx, "Course.ReorderLesson")
+	defer span.End()
+
+	var result *model.Section
+
+	err := c.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		section, err := c.CourseRepo.FindSection(ctx, sectionID)
+		if err != nil {
+			return err
+		}
+
+		current := make([]int, 0, len(section.Lessons))
+		for _, l := range section.Lessons {
+			current = append(current, l.Id)
+		}
+
+		if !samePermutation(lessonIDs, current) {
+			return model.ErrBadParamInput
+		}
+
+		if err := c.CourseRepo.ReorderLesson(ctx, sectionID, lessonIDs); err != nil {
+			return err
+		}
+
+		if err := c.CourseRepo.BumpVersion(ctx, section.CourseId); err != nil {
+			return err
+		}
+
+		result, err = c.CourseRepo.FindSection(ctx, sectionID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	outline([]model.Section{*result})
+
+	return result, nil
+}
+
+// applyPatch merges patch into the JSON form of target and decodes the
+// result back into target, rejecting unknown fields. target is zeroed before
+// decoding, so members the patch removes with null don't keep their value.
+func applyPatch(target interface{}, patch []byte) error {
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	merged, err := mergePatch(doc, patch)
+	if err != nil {
+		return fmt.Errorf("%w: %s", model.ErrBadParamInput, err.Error())
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	if err := decodeStrict(merged, target); err != nil {
+		return fmt.Errorf("%w: %s", model.ErrBadParamInput, err.Error())
+	}
+
+	return nil
+}
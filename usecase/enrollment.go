@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Enrollment struct {
+	EnrollmentRepo repository.EnrollmentRepository
+	ProgressRepo   repository.ProgressRepository
+	CourseRepo     repository.CourseRepository
+	Transactor     repository.Transactor
+	// Purchases is asked before enrolling in a paid course. Without it
+	// paid courses can't be enrolled in.
+	Purchases PurchaseChecker
+
+	completionMu sync.RWMutex
+	completion   []CompletionHandler
+}
+
+func NewEnrollment(enrollmentRepo repository.EnrollmentRepository, progressRepo repository.ProgressRepository, courseRepo repository.CourseRepository, transactor repository.Transactor, purchases PurchaseChecker) EnrollmentUsecae {
+	return &Enrollment{
+		EnrollmentRepo: enrollmentRepo,
+		ProgressRepo:   progressRepo,
+		CourseRepo:     courseRepo,
+		Transactor:     transactor,
+		Purchases:      purchases,
+	}
+}
+
+// Enroll enrolls the user in the course. Free courses are open to everyone,
+// paid courses need a purchase, otherwise model.ErrPaymentNeeded is
+// returned. Enrolling twice returns the existing enrollment.
+func (e *Enrollment) Enroll(ctx context.Context, userID int, courseID int) (*model.Enrollment, error) {
+	ctx, span := tracer.Start(ctx, "Enrollment.Enroll")
+	defer span.End()
+
+	var result *model.Enrollment
+
+	err := e.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		course, err := e.CourseRepo.FindOne(ctx, courseID)
+		if err != nil {
+			return err
+		}
+
+		result, err = e.EnrollmentRepo.FindOne(ctx, userID, courseID)
+		if err == nil || !errors.Is(err, model.ErrNotFound) {
+			return err
+		}
+
+		if course.Price > 0 {
+			if e.Purchases == nil {
+				return model.ErrPaymentNeeded
+			}
+
+			paid, err := e.Purchases.HasPurchased(ctx, userID, courseID)
+			if err != nil {
+				return err
+			}
+
+			if !paid {
+				return model.ErrPaymentNeeded
+			}
+		}
+
+		_, err = e.EnrollmentRepo.Store(ctx, model.Enrollment{
+			UserId:    userID,
+			CourseId:  courseID,
+			CreatedAt: time.Now().UTC().Truncate(time.Second),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = e.EnrollmentRepo.FindOne(ctx, userID, courseID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (e *Enrollment) Unenroll(ctx context.Context, userID int, courseID int) error {
+	ctx, span := tracer.Start(ctx, "Enrollment.Unenroll")
+	defer span.End()
+
+	err := e.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := e.EnrollmentRepo.FindOne(ctx, userID, courseID); err != nil {
+			return err
+		}
+
+		return e.EnrollmentRepo.Delete(ctx, userID, courseID)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (e *Enrollment) GetMyCourse(ctx context.Context, userID int) ([]model.EnrolledCourse, error) {
+	ctx, span := tracer.Start(ctx, "Enrollment.GetMyCourse")
+	defer span.End()
+
+	course, err := e.EnrollmentRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	summary, err := e.ProgressRepo.Summary(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	progress, err := e.ProgressRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	byCourse := map[int]*model.CourseProgress{}
+	for i := range summary {
+		byCourse[summary[i].CourseId] = &summary[i]
+	}
+
+	// progress is sorted by update time, the first row of a course is the
+	// lesson to resume.
+	for i := range progress {
+		if s, ok := byCourse[progress[i].CourseId]; ok && s.Resume == nil {
+			s.Resume = &progress[i]
+		}
+	}
+
+	for i := range course {
+		if s, ok := byCourse[course[i].Id]; ok {
+			s.Percentage = percentage(s.CompletedLessons, s.RequiredLessons)
+			course[i].Progress = s
+		}
+	}
+
+	return course, nil
+}
+
+func (e *Enrollment) GetRoster(ctx context.Context, courseID int) ([]model.RosterEntry, error) {
+	ctx, span := tracer.Start(ctx, "Enrollment.GetRoster")
+	defer span.End()
+
+	if _, err := e.CourseRepo.FindOne(ctx, courseID); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	roster, err := e.EnrollmentRepo.FetchByCourse(ctx, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return roster, nil
+}
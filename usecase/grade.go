@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"math"
+	"strings"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+// validQuiz checks the quiz settings and fills in the default score policy.
+func validQuiz(q *model.Quiz) bool {
+	if q.ScorePolicy == "" {
+		q.ScorePolicy = constant.ScoreBest
+	}
+
+	if q.ScorePolicy != constant.ScoreBest && q.ScorePolicy != constant.ScoreLatest {
+		return false
+	}
+
+	return q.TimeLimit >= 0 && q.MaxAttempts >= 0 && q.PassScore >= 0 && q.PassScore <= 100
+}
+
+// validChoices reports whether choices are distinct indexes into n options.
+func validChoices(choices []int, n int) bool {
+	seen := map[int]bool{}
+
+	for _, c := range choices {
+		if c < 0 || c >= n || seen[c] {
+			return false
+		}
+
+		seen[c] = true
+	}
+
+	return true
+}
+
+// validQuestion checks that the question has the options and the answer key
+// its type needs.
+func validQuestion(q model.Question) bool {
+	if q.Prompt == "" || q.Points <= 0 || q.Answer == nil {
+		return false
+	}
+
+	key := q.Answer
+
+	switch q.Type {
+	case constant.QuestionSingleChoice:
+		return len(q.Options) >= 2 && len(key.Choices) == 1 && validChoices(key.Choices, len(q.Options))
+	case constant.QuestionMultipleChoice:
+		return len(q.Options) >= 2 && len(key.Choices) >= 1 && validChoices(key.Choices, len(q.Options))
+	case constant.QuestionTrueFalse:
+		return len(q.Options) == 0 && key.Truth != nil
+	case constant.QuestionShortAnswer:
+		if len(q.Options) != 0 || len(key.Texts) == 0 {
+			return false
+		}
+
+		for _, t := range key.Texts {
+			if normalizeText(t) == "" {
+				return false
+			}
+		}
+
+		return true
+	case constant.QuestionNumeric:
+		return len(q.Options) == 0 && key.Number != nil && key.Tolerance >= 0 &&
+			!math.IsNaN(*key.Number) && !math.IsInf(*key.Number, 0)
+	default:
+		return false
+	}
+}
+
+// normalizeText makes short answers comparable: case, surrounding and
+// repeated spaces don't matter.
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// sameChoices reports whether both lists hold the same set of indexes.
+func sameChoices(a []int, b []int) bool {
+	set := map[int]bool{}
+	for _, c := range a {
+		set[c] = true
+	}
+
+	other := map[int]bool{}
+	for _, c := range b {
+		if !set[c] {
+			return false
+		}
+
+		other[c] = true
+	}
+
+	return len(set) == len(other)
+}
+
+// correct grades one response against the answer key of its question.
+// Multiple choice questions are all or nothing.
+func correct(q model.Question, r model.Response) bool {
+	key := q.Answer
+
+	switch q.Type {
+	case constant.QuestionSingleChoice:
+		return len(r.Choices) == 1 && r.Choices[0] == key.Choices[0]
+	case constant.QuestionMultipleChoice:
+		return sameChoices(key.Choices, r.Choices)
+	case constant.QuestionTrueFalse:
+		return r.Truth != nil && *r.Truth == *key.Truth
+	case constant.QuestionShortAnswer:
+		answer := normalizeText(r.Text)
+		for _, t := range key.Texts {
+			if answer != "" && answer == normalizeText(t) {
+				return true
+			}
+		}
+
+		return false
+	case constant.QuestionNumeric:
+		return r.Number != nil && math.Abs(*r.Number-*key.Number) <= key.Tolerance
+	default:
+		return false
+	}
+}
+
+// grade scores the responses against the questions of the quiz and fills in
+// the score and results of the attempt. Questions without a response count
+// as wrong, responses to unknown questions are dropped.
+func grade(quiz model.Quiz, attempt *model.QuizAttempt, responses []model.Response) {
+	byQuestion := map[int]model.Response{}
+	for _, r := range responses {
+		byQuestion[r.QuestionId] = r
+	}
+
+	attempt.Score, attempt.MaxScore = 0, 0
+	attempt.Responses = make([]model.Response, 0, len(responses))
+	attempt.Results = make([]model.QuestionResult, 0, len(quiz.Questions))
+
+	for _, q := range quiz.Questions {
+		result := model.QuestionResult{
+			QuestionId: q.Id,
+			MaxPoints:  q.Points,
+			Feedback:   q.Feedback,
+		}
+
+		if r, ok := byQuestion[q.Id]; ok {
+			attempt.Responses = append(attempt.Responses, r)
+			result.Correct = correct(q, r)
+		}
+
+		if result.Correct {
+			result.Points = q.Points
+		}
+
+		attempt.Score += result.Points
+		attempt.MaxScore += result.MaxPoints
+		attempt.Results = append(attempt.Results, result)
+	}
+
+	attempt.Percentage = percentage(attempt.Score, attempt.MaxScore)
+	attempt.Passed = attempt.Percentage >= quiz.PassScore
+}
+
+// policyScore returns the percentage counted by the score policy from the
+// attempts, oldest first, and false while none is submitted.
+func policyScore(policy string, attempts []model.QuizAttempt) (int, bool) {
+	score, found := 0, false
+
+	for _, a := range attempts {
+		if a.SubmittedAt == nil {
+			continue
+		}
+
+		if policy == constant.ScoreLatest || !found || a.Percentage > score {
+			score = a.Percentage
+		}
+
+		found = true
+	}
+
+	return score, found
+}
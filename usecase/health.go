@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+// HealthChecker reports whether a dependency of the service is usable.
+type HealthChecker interface {
+	Check(context.Context) error
+}
+
+// HealthCheckerFunc adapts a function such as (*sql.DB).PingContext to
+// HealthChecker.
+type HealthCheckerFunc func(context.Context) error
+
+func (f HealthCheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type healthCheck struct {
+	name    string
+	checker HealthChecker
+}
+
+// RegisterCheck adds a readiness check.
+func (m *Monitor) RegisterCheck(name string, checker HealthChecker) {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	m.checks = append(m.checks, healthCheck{name: name, checker: checker})
+}
+
+// Readiness runs every registered check concurrently, each bounded by
+// constant.HealthCheckTimeout. The results are ordered by name.
+func (m *Monitor) Readiness(ctx context.Context) model.HealthReport {
+	ctx, span := tracer.Start(ctx, "Monitor.Readiness")
+	defer span.End()
+
+	m.checkMu.RLock()
+	checks := append([]healthCheck(nil), m.checks...)
+	m.checkMu.RUnlock()
+
+	report := model.HealthReport{
+		Status: model.HealthUp,
+		Checks: make([]model.HealthCheck, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, constant.HealthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.checker.Check(ctx)
+
+			result := model.HealthCheck{
+				Name:    check.name,
+				Status:  model.HealthUp,
+				Latency: model.Duration(time.Since(start)),
+			}
+
+			if err != nil {
+				logger(ctx).Warnf("health check %s: %v", check.name, err)
+				result.Status = model.HealthDown
+				result.Error = err.Error()
+			}
+
+			report.Checks[i] = result
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+
+	for _, check := range report.Checks {
+		if check.Status != model.HealthUp {
+			report.Status = model.HealthDown
+		}
+	}
+
+	return report
+}
@@ -2,21 +2,37 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/egaevan/online-learning/model"
 )
 
 type CourseUsecae interface {
-	GetDetailCourse(context.Context, int) (*model.CourseDetail, error)
+	GetDetailCourse(context.Context, int, int) (*model.CourseDetail, error)
 	GetCourse(context.Context) ([]model.Course, error)
 	SendCourse(context.Context, model.Course) (*model.Course, error)
-	UpdateCourse(context.Context, model.CourseUpdate, int) (*model.CourseUpdate, error)
-	DeleteCourse(context.Context, int) error
+	UpdateCourse(context.Context, []byte, int, int) (*model.CourseDetail, error)
+	DeleteCourse(context.Context, int, int) error
 	SearchCourse(context.Context, string) ([]model.Course, error)
 	SortCourse(context.Context, string) ([]model.Course, error)
 	GetStatistic(ctx context.Context) (*model.StatisticResponse, error)
 	GetCategory(context.Context) ([]model.CategoryDetail, error)
-	GetPopularCategory(context.Context, int) ([]model.CategoryDetail, error)
+	GetPopularCategory(context.Context, int, time.Duration) ([]model.CategoryDetail, error)
+	GetCategoryTree(context.Context) ([]model.CategoryTree, error)
+	GetCourseByCategory(context.Context, int) ([]model.Course, error)
+	CreateCategory(context.Context, model.Category) (*model.Category, error)
+	RecomputeCount(context.Context) error
+	RunCountRecompute(context.Context, time.Duration)
+	GetLesson(context.Context, int, *model.Token) (*model.Lesson, error)
+	CreateSection(context.Context, int, model.Section) (*model.Section, error)
+	UpdateSection(context.Context, []byte, int) (*model.Section, error)
+	DeleteSection(context.Context, int) error
+	ReorderSection(context.Context, int, []int) ([]model.Section, error)
+	CreateLesson(context.Context, int, model.Lesson) (*model.Lesson, error)
+	UpdateLesson(context.Context, []byte, int) (*model.Lesson, error)
+	DeleteLesson(context.Context, int) error
+	ReorderLesson(context.Context, int, []int) (*model.Section, error)
 }
 
 type UserUsecae interface {
@@ -24,3 +40,143 @@ type UserUsecae interface {
 	CreateUser(ctx context.Context, user model.User) error
 	DeleteUser(context.Context, int) error
 }
+
+type EnrollmentUsecae interface {
+	Enroll(context.Context, int, int) (*model.Enrollment, error)
+	Unenroll(context.Context, int, int) error
+	GetMyCourse(context.Context, int) ([]model.EnrolledCourse, error)
+	GetRoster(context.Context, int) ([]model.RosterEntry, error)
+	SaveProgress(context.Context, int, int, model.ProgressUpdate) error
+	GetCourseProgress(context.Context, int, int) (*model.CourseProgress, error)
+	OnCourseCompleted(CompletionHandler)
+	CompleteLesson(context.Context, int, int) error
+}
+
+type QuizUsecae interface {
+	GetQuiz(context.Context, int, *model.Token) (*model.Quiz, error)
+	SaveQuiz(context.Context, int, model.Quiz) (*model.Quiz, error)
+	CreateQuestion(context.Context, int, model.Question) (*model.Question, error)
+	UpdateQuestion(context.Context, []byte, int) (*model.Question, error)
+	DeleteQuestion(context.Context, int) error
+	ReorderQuestion(context.Context, int, []int) (*model.Quiz, error)
+	StartAttempt(context.Context, int, int) (*model.QuizAttempt, error)
+	SubmitAttempt(context.Context, int, int, model.QuizSubmission) (*model.QuizAttempt, error)
+	GetQuizStatus(context.Context, int, int) (*model.QuizStatus, error)
+}
+
+type AssignmentUsecae interface {
+	GetAssignment(context.Context, int, *model.Token) (*model.Assignment, error)
+	SaveAssignment(context.Context, int, model.Assignment) (*model.Assignment, error)
+	Submit(context.Context, int, int, []model.Upload) (*model.Submission, error)
+	GetMySubmission(context.Context, int, int) (*model.Submission, error)
+	GetSubmission(context.Context, int, *model.Token) (*model.Submission, error)
+	OpenFile(context.Context, int, int, *model.Token) (*model.SubmissionFile, io.ReadCloser, error)
+	GradeSubmission(context.Context, int, int, model.GradeRequest) (*model.Submission, error)
+	GetGradebook(context.Context, int) (*model.Gradebook, error)
+}
+
+type NotificationUsecae interface {
+	GetNotification(context.Context, int) ([]model.Notification, error)
+	ReadNotification(context.Context, int, int) error
+	Notify(context.Context, model.Notification) error
+}
+
+type CertificateUsecae interface {
+	Issue(context.Context, model.Enrollment) (*model.Certificate, error)
+	CourseCompleted(context.Context, model.Enrollment)
+	GetMyCertificate(context.Context, int) ([]model.Certificate, error)
+	OpenCertificate(context.Context, string, *model.Token) (*model.Certificate, io.ReadCloser, error)
+	VerifyCertificate(context.Context, string) (*model.CertificateVerification, error)
+	RevokeCertificate(context.Context, string, model.RevokeRequest) (*model.Certificate, error)
+}
+
+type ReviewUsecae interface {
+	GetReview(context.Context, int, *model.Token) ([]model.Review, error)
+	SaveReview(context.Context, int, int, model.ReviewRequest) (*model.Review, error)
+	DeleteReview(context.Context, int, int) error
+	ReplyReview(context.Context, int, int, model.ReplyRequest) (*model.Review, error)
+	DeleteReply(context.Context, int) error
+	VoteReview(context.Context, *model.Token, int, bool) (*model.Review, error)
+	FlagReview(context.Context, *model.Token, int, model.FlagRequest) error
+	ModerateReview(context.Context, int, model.ModerationRequest) (*model.Review, error)
+	GetFlaggedReview(context.Context) ([]model.Review, error)
+}
+
+type OrderUsecae interface {
+	GetCart(context.Context, int, string) (*model.Cart, error)
+	AddToCart(context.Context, int, model.CartRequest) (*model.Cart, error)
+	RemoveFromCart(context.Context, int, int) (*model.Cart, error)
+	Checkout(context.Context, int, model.CheckoutRequest) (*model.Order, error)
+	GetMyOrder(context.Context, int) ([]model.Order, error)
+	GetOrder(context.Context, int, *model.Token) (*model.Order, error)
+	UpdateStatus(context.Context, int, model.StatusRequest) (*model.Order, error)
+	Pay(context.Context, int, int) (*model.PaymentIntent, error)
+	Refund(context.Context, int) (*model.Order, error)
+	HandleWebhook(context.Context, []byte, string) error
+	SimulatePayment(context.Context, int, string, bool) error
+	HasPurchased(context.Context, int, int) (bool, error)
+}
+
+type CouponUsecae interface {
+	GetCoupon(context.Context) ([]model.Coupon, error)
+	GetDetailCoupon(context.Context, int) (*model.Coupon, error)
+	CreateCoupon(context.Context, model.CouponRequest) (*model.Coupon, error)
+	UpdateCoupon(context.Context, int, model.CouponRequest) (*model.Coupon, error)
+	DeleteCoupon(context.Context, int) error
+}
+
+// CertificateRenderer writes a certificate as a PDF document.
+type CertificateRenderer interface {
+	Render(w io.Writer, certificate model.Certificate) error
+}
+
+// PaymentGateway is a payment provider. The customer authorizes a payment
+// intent with the provider, which is then captured. The provider reports
+// every step with a signed webhook event.
+type PaymentGateway interface {
+	// CreateIntent starts a payment of amount for the order.
+	CreateIntent(ctx context.Context, orderID int, amount model.Money) (*model.PaymentIntent, error)
+	// Capture collects an authorized payment.
+	Capture(ctx context.Context, paymentID string) error
+	// Refund pays amount of a captured payment back.
+	Refund(ctx context.Context, paymentID string, amount model.Money) error
+	// VerifyWebhook checks the signature of a webhook body and returns
+	// the event in it.
+	VerifyWebhook(payload []byte, signature string) (*model.PaymentEvent, error)
+}
+
+// PaymentSimulator is a payment provider whose customer can be played in
+// development, like the fake gateway.
+type PaymentSimulator interface {
+	// Confirm authorizes a payment as the customer would.
+	Confirm(paymentID string) error
+	// Decline fails a payment as a declined card would.
+	Decline(paymentID string) error
+}
+
+// Notifier delivers a notification to its user.
+type Notifier interface {
+	Notify(ctx context.Context, notification model.Notification) error
+}
+
+// LessonCompleter completes a lesson for a user, and the course with it
+// when it was the last required lesson.
+type LessonCompleter interface {
+	CompleteLesson(ctx context.Context, userID int, lessonID int) error
+}
+
+// CompletionHandler is called once for an enrollment when its course is
+// completed, after the completion is stored.
+type CompletionHandler func(ctx context.Context, enrollment model.Enrollment)
+
+// PurchaseChecker tells whether a user has paid for a course.
+type PurchaseChecker interface {
+	HasPurchased(ctx context.Context, userID int, courseID int) (bool, error)
+}
+
+type MonitorUsecae interface {
+	GetPoolStats(context.Context) (*model.PoolStats, error)
+	RegisterCheck(string, HealthChecker)
+	Readiness(context.Context) model.HealthReport
+	CollectMetrics(context.Context) error
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// mergePatch applies a JSON Merge Patch (RFC 7396) to the target document.
+func mergePatch(target, patch []byte) ([]byte, error) {
+	var targetDoc, patchDoc interface{}
+
+	if err := json.Unmarshal(target, &targetDoc); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValue(targetDoc, patchDoc))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// decodeStrict decodes data into v and rejects fields v doesn't have.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import "github.com/egaevan/online-learning/metrics"
+
+var (
+	loginTotal = metrics.NewCounterVec(
+		"online_learning_login_total",
+		"Login attempts by result.",
+		"result",
+	)
+
+	activeCourses = metrics.NewGaugeVec(
+		"online_learning_active_courses",
+		"Courses that are not deleted.",
+	)
+
+	registeredUsers = metrics.NewGaugeVec(
+		"online_learning_registered_users",
+		"Users that are not deleted.",
+	)
+
+	courseCompletions = metrics.NewCounterVec(
+		"online_learning_course_completions_total",
+		"Enrollments whose course got completed.",
+	)
+
+	quizAttempts = metrics.NewCounterVec(
+		"online_learning_quiz_attempts_total",
+		"Submitted quiz attempts by result.",
+		"result",
+	)
+
+	submissions = metrics.NewCounterVec(
+		"online_learning_assignment_submissions_total",
+		"Assignment submissions by timing.",
+		"timing",
+	)
+
+	certificates = metrics.NewCounterVec(
+		"online_learning_certificates_total",
+		"Certificates by event, issued or revoked.",
+		"event",
+	)
+
+	reviewFlags = metrics.NewCounterVec(
+		"online_learning_review_flags_total",
+		"Reviews flagged for moderation.",
+	)
+
+	orders = metrics.NewCounterVec(
+		"online_learning_orders_total",
+		"Orders by the status they moved to, pending at checkout.",
+		"status",
+	)
+
+	paymentEvents = metrics.NewCounterVec(
+		"online_learning_payment_events_total",
+		"Payment webhook events by result: processed, duplicate, ignored or invalid.",
+		"result",
+	)
+
+	couponRedemptions = metrics.NewCounterVec(
+		"online_learning_coupon_redemptions_total",
+		"Coupons redeemed at checkout by type, percent or fixed.",
+		"type",
+	)
+)
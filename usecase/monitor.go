@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Monitor struct {
+	StatsRepo repository.StatsRepository
+
+	checkMu sync.RWMutex
+	checks  []healthCheck
+}
+
+func NewMonitor(statsRepo repository.StatsRepository) MonitorUsecae {
+	return &Monitor{
+		StatsRepo: statsRepo,
+	}
+}
+
+func (m *Monitor) GetPoolStats(ctx context.Context) (*model.PoolStats, error) {
+	ctx, span := tracer.Start(ctx, "Monitor.GetPoolStats")
+	defer span.End()
+
+	res, err := m.StatsRepo.PoolStats(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// CollectMetrics refreshes the business gauges before a scrape.
+func (m *Monitor) CollectMetrics(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Monitor.CollectMetrics")
+	defer span.End()
+
+	stats, err := m.StatsRepo.BusinessStats(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	activeCourses.With().Set(float64(stats.ActiveCourses))
+	registeredUsers.With().Set(float64(stats.RegisteredUsers))
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Notification struct {
+	NotificationRepo repository.NotificationRepository
+}
+
+func NewNotification(notificationRepo repository.NotificationRepository) NotificationUsecae {
+	return &Notification{
+		NotificationRepo: notificationRepo,
+	}
+}
+
+// Notify stores the notification for its user to read.
+func (n *Notification) Notify(ctx context.Context, notification model.Notification) error {
+	ctx, span := tracer.Start(ctx, "Notification.Notify")
+	defer span.End()
+
+	notification.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	if _, err := n.NotificationRepo.Store(ctx, notification); err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetNotification returns the notifications of the user, newest first.
+func (n *Notification) GetNotification(ctx context.Context, userID int) ([]model.Notification, error) {
+	ctx, span := tracer.Start(ctx, "Notification.GetNotification")
+	defer span.End()
+
+	result, err := n.NotificationRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReadNotification marks a notification of the user as read. Marking it
+// again changes nothing.
+func (n *Notification) ReadNotification(ctx context.Context, userID int, notificationID int) error {
+	ctx, span := tracer.Start(ctx, "Notification.ReadNotification")
+	defer span.End()
+
+	done, err := n.NotificationRepo.MarkRead(ctx, userID, notificationID, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if done {
+		return nil
+	}
+
+	notifications, err := n.NotificationRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	for _, v := range notifications {
+		if v.Id == notificationID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: notification %d", model.ErrNotFound, notificationID)
+}
@@ -0,0 +1,421 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+// orderTransitions lists the statuses an order can move to from each
+// status. Failed and refunded orders are final.
+var orderTransitions = map[string][]string{
+	constant.OrderPending: {constant.OrderPaid, constant.OrderFailed},
+	constant.OrderPaid:    {constant.OrderRefunded},
+}
+
+type Order struct {
+	CartRepo       repository.CartRepository
+	OrderRepo      repository.OrderRepository
+	PaymentRepo    repository.PaymentRepository
+	CouponRepo     repository.CouponRepository
+	CourseRepo     repository.CourseRepository
+	EnrollmentRepo repository.EnrollmentRepository
+	Transactor     repository.Transactor
+	// Gateway is the payment provider collecting the order totals.
+	Gateway PaymentGateway
+	// Currency is the ISO 4217 code of the course prices.
+	Currency string
+}
+
+func NewOrder(cartRepo repository.CartRepository, orderRepo repository.OrderRepository, paymentRepo repository.PaymentRepository, couponRepo repository.CouponRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, transactor repository.Transactor, gateway PaymentGateway, currency string) OrderUsecae {
+	return &Order{
+		CartRepo:       cartRepo,
+		OrderRepo:      orderRepo,
+		PaymentRepo:    paymentRepo,
+		CouponRepo:     couponRepo,
+		CourseRepo:     courseRepo,
+		EnrollmentRepo: enrollmentRepo,
+		Transactor:     transactor,
+		Gateway:        gateway,
+		Currency:       currency,
+	}
+}
+
+// cart returns the cart of the user priced in the catalog currency.
+func (o *Order) cart(ctx context.Context, userID int) (*model.Cart, error) {
+	items, err := o.CartRepo.Fetch(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Cart{
+		Items:    items,
+		Subtotal: model.Money{Currency: o.Currency},
+		Discount: model.Money{Currency: o.Currency},
+		Total:    model.Money{Currency: o.Currency},
+	}
+
+	for i := range result.Items {
+		result.Items[i].Price.Currency = o.Currency
+		result.Items[i].Discount.Currency = o.Currency
+		result.Subtotal.Amount += result.Items[i].Price.Amount
+	}
+
+	result.Total.Amount = result.Subtotal.Amount
+
+	return result, nil
+}
+
+// enrolled reports whether the user is enrolled in the course.
+func (o *Order) enrolled(ctx context.Context, userID int, courseID int) (bool, error) {
+	_, err := o.EnrollmentRepo.FindOne(ctx, userID, courseID)
+	if errors.Is(err, model.ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// GetCart returns the cart of the user, with the discount of the coupon
+// with the code when it is set.
+func (o *Order) GetCart(ctx context.Context, userID int, code string) (*model.Cart, error) {
+	ctx, span := tracer.Start(ctx, "Order.GetCart")
+	defer span.End()
+
+	result, err := o.cart(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if code != "" && len(result.Items) > 0 {
+		if _, err := o.applyCoupon(ctx, userID, result, code); err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// AddToCart puts a paid course the user is not enrolled in into the cart.
+// Free courses are enrolled in directly. Adding a course twice keeps it
+// once.
+func (o *Order) AddToCart(ctx context.Context, userID int, req model.CartRequest) (*model.Cart, error) {
+	ctx, span := tracer.Start(ctx, "Order.AddToCart")
+	defer span.End()
+
+	course, err := o.CourseRepo.FindOne(ctx, req.CourseId)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if course.Price <= 0 {
+		return nil, fmt.Errorf("%w: course %d is free, enroll in it instead", model.ErrBadParamInput, course.Id)
+	}
+
+	enrolled, err := o.enrolled(ctx, userID, course.Id)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if enrolled {
+		return nil, fmt.Errorf("%w: user %d is enrolled in course %d already", model.ErrBadParamInput, userID, course.Id)
+	}
+
+	if err := o.CartRepo.Add(ctx, userID, course.Id, time.Now().UTC().Truncate(time.Second)); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return o.GetCart(ctx, userID, "")
+}
+
+func (o *Order) RemoveFromCart(ctx context.Context, userID int, courseID int) (*model.Cart, error) {
+	ctx, span := tracer.Start(ctx, "Order.RemoveFromCart")
+	defer span.End()
+
+	if err := o.CartRepo.Remove(ctx, userID, courseID); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return o.GetCart(ctx, userID, "")
+}
+
+// Checkout turns the cart of the user into a pending order at the current
+// prices and empties the cart. The coupon of the request is redeemed with
+// the order, an order it makes free is paid right away. Courses the user
+// got enrolled in since adding them fail the checkout.
+func (o *Order) Checkout(ctx context.Context, userID int, req model.CheckoutRequest) (*model.Order, error) {
+	ctx, span := tracer.Start(ctx, "Order.Checkout")
+	defer span.End()
+
+	var (
+		result *model.Order
+		coupon *model.Coupon
+	)
+
+	err := o.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		cart, err := o.cart(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if len(cart.Items) == 0 {
+			return fmt.Errorf("%w: the cart is empty", model.ErrBadParamInput)
+		}
+
+		coupon = nil
+
+		if req.Coupon != "" {
+			coupon, err = o.applyCoupon(ctx, userID, cart, req.Coupon)
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		order := model.Order{
+			UserId:    userID,
+			Status:    constant.OrderPending,
+			Coupon:    cart.Coupon,
+			Subtotal:  cart.Subtotal,
+			Discount:  cart.Discount,
+			Total:     cart.Total,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		for _, item := range cart.Items {
+			enrolled, err := o.enrolled(ctx, userID, item.CourseId)
+			if err != nil {
+				return err
+			}
+
+			if enrolled {
+				return fmt.Errorf("%w: user %d is enrolled in course %d already", model.ErrBadParamInput, userID, item.CourseId)
+			}
+
+			order.Items = append(order.Items, model.OrderItem{
+				CourseId:   item.CourseId,
+				CourseName: item.CourseName,
+				Price:      item.Price,
+				Discount:   item.Discount,
+			})
+		}
+
+		id, err := o.OrderRepo.Store(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		order.Id = id
+
+		if coupon != nil {
+			if err := o.redeem(ctx, coupon, &order); err != nil {
+				return err
+			}
+		}
+
+		if err := o.CartRepo.Clear(ctx, userID); err != nil {
+			return err
+		}
+
+		result, err = o.OrderRepo.FindOne(ctx, id)
+		if err != nil || result.Total.Amount > 0 {
+			return err
+		}
+
+		// Nothing is left to pay.
+		if err := o.transition(ctx, result, constant.OrderPaid); err != nil {
+			return err
+		}
+
+		result, err = o.OrderRepo.FindOne(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	orders.With(constant.OrderPending).Inc()
+
+	if result.Status == constant.OrderPaid {
+		orders.With(constant.OrderPaid).Inc()
+	}
+
+	if coupon != nil {
+		couponRedemptions.With(coupon.Type).Inc()
+	}
+
+	return result, nil
+}
+
+// GetMyOrder returns the orders of the user, newest first.
+func (o *Order) GetMyOrder(ctx context.Context, userID int) ([]model.Order, error) {
+	ctx, span := tracer.Start(ctx, "Order.GetMyOrder")
+	defer span.End()
+
+	result, err := o.OrderRepo.FetchByUser(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetOrder returns an order to its buyer and to admins.
+func (o *Order) GetOrder(ctx context.Context, orderID int, user *model.Token) (*model.Order, error) {
+	ctx, span := tracer.Start(ctx, "Order.GetOrder")
+	defer span.End()
+
+	result, err := o.OrderRepo.FindOne(ctx, orderID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if user.Role != constant.RoleAdmin && result.UserId != user.UserID {
+		return nil, fmt.Errorf("%w: order %d of another user", model.ErrNotFound, orderID)
+	}
+
+	return result, nil
+}
+
+// UpdateStatus moves an order to another status. Paying an order enrolls
+// its buyer in every course of it, refunding it takes back the enrollments
+// no other paid order covers.
+func (o *Order) UpdateStatus(ctx context.Context, orderID int, req model.StatusRequest) (*model.Order, error) {
+	ctx, span := tracer.Start(ctx, "Order.UpdateStatus")
+	defer span.End()
+
+	var result *model.Order
+
+	err := o.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := o.OrderRepo.FindOne(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, to := range orderTransitions[order.Status] {
+			allowed = allowed || to == req.Status
+		}
+
+		if !allowed {
+			return fmt.Errorf("%w: order %d can't move from %s to %q", model.ErrBadParamInput, orderID, order.Status, req.Status)
+		}
+
+		if err := o.transition(ctx, order, req.Status); err != nil {
+			return err
+		}
+
+		result, err = o.OrderRepo.FindOne(ctx, orderID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	orders.With(req.Status).Inc()
+
+	return result, nil
+}
+
+// transition moves the order to the status, enrolling its buyer when it
+// is paid and taking the enrollments back when it is refunded. A failed
+// order gives its coupon use back.
+func (o *Order) transition(ctx context.Context, order *model.Order, status string) error {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	done, err := o.OrderRepo.UpdateStatus(ctx, order.Id, order.Status, status, now)
+	if err != nil {
+		return err
+	}
+
+	if !done {
+		return fmt.Errorf("%w: order %d changed meanwhile", model.ErrConflict, order.Id)
+	}
+
+	switch status {
+	case constant.OrderPaid:
+		return o.grant(ctx, order, now)
+	case constant.OrderRefunded:
+		return o.revoke(ctx, order)
+	case constant.OrderFailed:
+		return o.CouponRepo.Release(ctx, order.Id)
+	}
+
+	return nil
+}
+
+// grant enrolls the buyer of a paid order in its courses.
+func (o *Order) grant(ctx context.Context, order *model.Order, at time.Time) error {
+	for _, item := range order.Items {
+		enrolled, err := o.enrolled(ctx, order.UserId, item.CourseId)
+		if err != nil {
+			return err
+		}
+
+		if enrolled {
+			continue
+		}
+
+		_, err = o.EnrollmentRepo.Store(ctx, model.Enrollment{
+			UserId:    order.UserId,
+			CourseId:  item.CourseId,
+			CreatedAt: at,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revoke takes back the enrollments of a refunded order, unless another
+// paid order of the buyer has the course too.
+func (o *Order) revoke(ctx context.Context, order *model.Order) error {
+	for _, item := range order.Items {
+		paid, err := o.OrderRepo.HasPaidCourse(ctx, order.UserId, item.CourseId)
+		if err != nil {
+			return err
+		}
+
+		enrolled, err := o.enrolled(ctx, order.UserId, item.CourseId)
+		if err != nil {
+			return err
+		}
+
+		if paid || !enrolled {
+			continue
+		}
+
+		if err := o.EnrollmentRepo.Delete(ctx, order.UserId, item.CourseId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasPurchased is the PurchaseChecker of enrollments, a course is
+// purchased once an order with it is paid.
+func (o *Order) HasPurchased(ctx context.Context, userID int, courseID int) (bool, error) {
+	return o.OrderRepo.HasPaidCourse(ctx, userID, courseID)
+}
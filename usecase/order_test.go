@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+func TestOrderUpdateStatus(t *testing.T) {
+	for _, c := range []struct {
+		name         string
+		steps        []string
+		wantErr      error
+		wantStatus   string
+		wantEnrolled bool
+	}{
+		{name: "paid", steps: []string{constant.OrderPaid}, wantStatus: constant.OrderPaid, wantEnrolled: true},
+		{name: "failed", steps: []string{constant.OrderFailed}, wantStatus: constant.OrderFailed},
+		{name: "refunded revokes the enrollment", steps: []string{constant.OrderPaid, constant.OrderRefunded}, wantStatus: constant.OrderRefunded},
+		{name: "pending to refunded", steps: []string{constant.OrderRefunded}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderPending},
+		{name: "paid twice", steps: []string{constant.OrderPaid, constant.OrderPaid}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderPaid, wantEnrolled: true},
+		{name: "paid to failed", steps: []string{constant.OrderPaid, constant.OrderFailed}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderPaid, wantEnrolled: true},
+		{name: "paid to pending", steps: []string{constant.OrderPaid, constant.OrderPending}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderPaid, wantEnrolled: true},
+		{name: "failed is final", steps: []string{constant.OrderFailed, constant.OrderPaid}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderFailed},
+		{name: "refunded is final", steps: []string{constant.OrderPaid, constant.OrderRefunded, constant.OrderPaid}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderRefunded},
+		{name: "unknown status", steps: []string{"shipped"}, wantErr: model.ErrBadParamInput, wantStatus: constant.OrderPending},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+			ctx := context.Background()
+
+			category := f.category("Programming", 0)
+			golang := f.course(category, "Go Basics", 1500)
+			rust := f.course(category, "Rust Basics", 2500)
+			ann := f.user("Ann", "ann@example.com")
+
+			uc := f.orders(nil)
+
+			order, err := f.checkout(uc, ann, "", golang, rust)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, status := range c.steps {
+				_, err = uc.UpdateStatus(ctx, order.Id, model.StatusRequest{Status: status})
+				if i < len(c.steps)-1 && err != nil {
+					t.Fatalf("UpdateStatus(%s): %v", status, err)
+				}
+			}
+
+			if c.wantErr == nil && err != nil || c.wantErr != nil && !errors.Is(err, c.wantErr) {
+				t.Errorf("UpdateStatus returned %v, want %v", err, c.wantErr)
+			}
+
+			got, err := uc.OrderRepo.FindOne(ctx, order.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Status != c.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, c.wantStatus)
+			}
+
+			for _, id := range []int{golang, rust} {
+				if f.enrolled(ann, id) != c.wantEnrolled {
+					t.Errorf("enrolled in course %d = %v, want %v", id, !c.wantEnrolled, c.wantEnrolled)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRefundKeepsOtherPaidCourses(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	category := f.category("Programming", 0)
+	golang := f.course(category, "Go Basics", 1500)
+	rust := f.course(category, "Rust Basics", 2500)
+	ann := f.user("Ann", "ann@example.com")
+
+	uc := f.orders(nil)
+
+	first, err := f.checkout(uc, ann, "", golang, rust)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.UpdateStatus(ctx, first.Id, model.StatusRequest{Status: constant.OrderPaid}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An admin sold Go Basics again, e.g. paid by invoice.
+	second, err := f.repos.Order.Store(ctx, model.Order{UserId: ann, Status: constant.OrderPending, Items: []model.OrderItem{{CourseId: golang, CourseName: "Go Basics"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.UpdateStatus(ctx, second, model.StatusRequest{Status: constant.OrderPaid}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.UpdateStatus(ctx, first.Id, model.StatusRequest{Status: constant.OrderRefunded}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !f.enrolled(ann, golang) {
+		t.Error("refunding the first order revoked Go Basics, the second order paid for it")
+	}
+
+	if f.enrolled(ann, rust) {
+		t.Error("refunding the first order kept Rust Basics")
+	}
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+// eventStatus is the payment status each webhook event reports.
+var eventStatus = map[string]string{
+	constant.EventPaymentAuthorized: constant.PaymentAuthorized,
+	constant.EventPaymentSucceeded:  constant.PaymentSucceeded,
+	constant.EventPaymentFailed:     constant.PaymentFailed,
+	constant.EventPaymentRefunded:   constant.PaymentRefunded,
+}
+
+// paymentRank orders the payment statuses. Providers don't promise to send
+// events in order, so an event never moves a payment to a lower rank.
+var paymentRank = map[string]int{
+	constant.PaymentCreated:    0,
+	constant.PaymentAuthorized: 1,
+	constant.PaymentFailed:     2,
+	constant.PaymentSucceeded:  3,
+	constant.PaymentRefunded:   4,
+}
+
+// paymentOrderStatus is the order status a payment status moves its order
+// to.
+var paymentOrderStatus = map[string]string{
+	constant.PaymentSucceeded: constant.OrderPaid,
+	constant.PaymentFailed:    constant.OrderFailed,
+	constant.PaymentRefunded:  constant.OrderRefunded,
+}
+
+// Pay starts a payment of a pending order of the user. The client completes
+// it with the provider, the order is paid once the provider reports so on
+// the payment webhook.
+func (o *Order) Pay(ctx context.Context, userID int, orderID int) (*model.PaymentIntent, error) {
+	ctx, span := tracer.Start(ctx, "Order.Pay")
+	defer span.End()
+
+	order, err := o.OrderRepo.FindOne(ctx, orderID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if order.UserId != userID {
+		return nil, fmt.Errorf("%w: order %d of another user", model.ErrNotFound, orderID)
+	}
+
+	if order.Status != constant.OrderPending {
+		return nil, fmt.Errorf("%w: order %d is %s", model.ErrBadParamInput, orderID, order.Status)
+	}
+
+	payments, err := o.PaymentRepo.FetchByOrder(ctx, orderID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	// A second payment could charge the customer twice.
+	for _, p := range payments {
+		if p.Status == constant.PaymentAuthorized || p.Status == constant.PaymentSucceeded {
+			return nil, fmt.Errorf("%w: order %d is being paid", model.ErrConflict, orderID)
+		}
+	}
+
+	intent, err := o.Gateway.CreateIntent(ctx, order.Id, order.Total)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	err = o.PaymentRepo.Store(ctx, model.Payment{
+		Id:        intent.Id,
+		OrderId:   order.Id,
+		Amount:    intent.Amount,
+		Status:    constant.PaymentCreated,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return intent, nil
+}
+
+// Refund pays a paid order back through the provider. The order is
+// refunded once the provider reports so on the payment webhook.
+func (o *Order) Refund(ctx context.Context, orderID int) (*model.Order, error) {
+	ctx, span := tracer.Start(ctx, "Order.Refund")
+	defer span.End()
+
+	order, err := o.OrderRepo.FindOne(ctx, orderID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if order.Status != constant.OrderPaid {
+		return nil, fmt.Errorf("%w: order %d is %s", model.ErrBadParamInput, orderID, order.Status)
+	}
+
+	payments, err := o.PaymentRepo.FetchByOrder(ctx, orderID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	for _, p := range payments {
+		if p.Status != constant.PaymentSucceeded {
+			continue
+		}
+
+		if err := o.Gateway.Refund(ctx, p.Id, p.Amount); err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+
+		return order, nil
+	}
+
+	// Orders paid by an admin have no payment to refund.
+	return nil, fmt.Errorf("%w: order %d has no captured payment", model.ErrBadParamInput, orderID)
+}
+
+// SimulatePayment confirms or declines a payment of an order of the user
+// as the customer would at the provider. Only providers implementing
+// PaymentSimulator support it, it is meant for development.
+func (o *Order) SimulatePayment(ctx context.Context, userID int, paymentID string, confirm bool) error {
+	ctx, span := tracer.Start(ctx, "Order.SimulatePayment")
+	defer span.End()
+
+	simulator, ok := o.Gateway.(PaymentSimulator)
+	if !ok {
+		return fmt.Errorf("%w: the payment provider can't simulate payments", model.ErrBadParamInput)
+	}
+
+	payment, err := o.PaymentRepo.FindOne(ctx, paymentID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	order, err := o.OrderRepo.FindOne(ctx, payment.OrderId)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if order.UserId != userID {
+		return fmt.Errorf("%w: payment %s of another user", model.ErrNotFound, paymentID)
+	}
+
+	if confirm {
+		err = simulator.Confirm(paymentID)
+	} else {
+		err = simulator.Decline(paymentID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", model.ErrBadParamInput, err)
+	}
+
+	return nil
+}
+
+// HandleWebhook applies a signed event of the payment provider. Events
+// seen before are acknowledged without applying them again, and events
+// arriving after a later one are ignored, so the provider may resend and
+// reorder them. Errors ask the provider to send the event again.
+func (o *Order) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	ctx, span := tracer.Start(ctx, "Order.HandleWebhook")
+	defer span.End()
+
+	event, err := o.Gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		paymentEvents.With("invalid").Inc()
+		logger(ctx).Warn(err)
+		return fmt.Errorf("%w: %v", model.ErrBadParamInput, err)
+	}
+
+	var (
+		result  string
+		capture bool
+		moved   string
+	)
+
+	err = o.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		result, capture, moved = "processed", false, ""
+
+		// Unknown payments may still be stored by Pay, the provider
+		// retries until they are.
+		payment, err := o.PaymentRepo.FindOne(ctx, event.PaymentId)
+		if err != nil {
+			return err
+		}
+
+		order, err := o.OrderRepo.FindOne(ctx, payment.OrderId)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		stored, err := o.PaymentRepo.StoreEvent(ctx, *event, now)
+		if err != nil {
+			return err
+		}
+
+		status, known := eventStatus[event.Type]
+
+		switch {
+		case !stored:
+			result = "duplicate"
+		case !known || paymentRank[status] <= paymentRank[payment.Status]:
+			result = "ignored"
+		default:
+			if err := o.PaymentRepo.UpdateStatus(ctx, payment.Id, status, now); err != nil {
+				return err
+			}
+
+			payment.Status = status
+
+			moved, err = o.settle(ctx, order, payment)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Authorized payments of pending orders are captured, also on a
+		// duplicate in case capturing failed the first time.
+		capture = event.Type == constant.EventPaymentAuthorized &&
+			payment.Status == constant.PaymentAuthorized && order.Status == constant.OrderPending
+
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	paymentEvents.With(result).Inc()
+
+	if moved != "" {
+		orders.With(moved).Inc()
+	}
+
+	// Capturing after the commit keeps the event of the capture from
+	// racing the authorization.
+	if capture {
+		if err := o.Gateway.Capture(ctx, event.PaymentId); err != nil {
+			logger(ctx).Error(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settle moves the order of a payment to the status the payment reached,
+// and returns that status when it did. Orders only move forward: a payment
+// failing after another one succeeded leaves the order paid.
+func (o *Order) settle(ctx context.Context, order *model.Order, payment *model.Payment) (string, error) {
+	to, ok := paymentOrderStatus[payment.Status]
+	if !ok {
+		return "", nil
+	}
+
+	// Another payment of the order may still go through.
+	if to == constant.OrderFailed {
+		others, err := o.PaymentRepo.FetchByOrder(ctx, order.Id)
+		if err != nil {
+			return "", err
+		}
+
+		for _, p := range others {
+			if p.Id != payment.Id && p.Status != constant.PaymentFailed {
+				return "", nil
+			}
+		}
+	}
+
+	// A refund reported before its capture passes the order through paid,
+	// the refund then takes the enrollments back.
+	if to == constant.OrderRefunded && order.Status == constant.OrderPending {
+		if err := o.transition(ctx, order, constant.OrderPaid); err != nil {
+			return "", err
+		}
+
+		order.Status = constant.OrderPaid
+	}
+
+	for _, next := range orderTransitions[order.Status] {
+		if next == to {
+			return to, o.transition(ctx, order, to)
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/payment"
+)
+
+// capturingGateway counts the captures of HandleWebhook. The events of the
+// tests are made up, the fake provider never saw them authorized.
+type capturingGateway struct {
+	*payment.Fake
+	captured int
+}
+
+func (g *capturingGateway) Capture(ctx context.Context, paymentID string) error {
+	g.captured++
+	return nil
+}
+
+func TestHandleWebhook(t *testing.T) {
+	type event struct {
+		id        string
+		eventType string
+	}
+
+	authorized := event{"evt_1", constant.EventPaymentAuthorized}
+	succeeded := event{"evt_2", constant.EventPaymentSucceeded}
+	failed := event{"evt_3", constant.EventPaymentFailed}
+	refunded := event{"evt_4", constant.EventPaymentRefunded}
+
+	for _, c := range []struct {
+		name         string
+		events       []event
+		wantPayment  string
+		wantOrder    string
+		wantEnrolled bool
+		wantCaptured int
+	}{
+		{"authorized is captured", []event{authorized}, constant.PaymentAuthorized, constant.OrderPending, false, 1},
+		{"succeeded", []event{authorized, succeeded}, constant.PaymentSucceeded, constant.OrderPaid, true, 1},
+		{"failed", []event{failed}, constant.PaymentFailed, constant.OrderFailed, false, 0},
+		{"refunded", []event{authorized, succeeded, refunded}, constant.PaymentRefunded, constant.OrderRefunded, false, 1},
+		{"duplicate authorized captures again", []event{authorized, authorized}, constant.PaymentAuthorized, constant.OrderPending, false, 2},
+		{"duplicate succeeded", []event{authorized, succeeded, succeeded}, constant.PaymentSucceeded, constant.OrderPaid, true, 1},
+		{"duplicate refunded", []event{succeeded, refunded, refunded}, constant.PaymentRefunded, constant.OrderRefunded, false, 0},
+		{"succeeded before authorized", []event{succeeded, authorized}, constant.PaymentSucceeded, constant.OrderPaid, true, 0},
+		{"failed after succeeded", []event{succeeded, failed}, constant.PaymentSucceeded, constant.OrderPaid, true, 0},
+		{"succeeded after refunded", []event{succeeded, refunded, succeeded}, constant.PaymentRefunded, constant.OrderRefunded, false, 0},
+		{"refunded before succeeded", []event{authorized, refunded, succeeded}, constant.PaymentRefunded, constant.OrderRefunded, false, 1},
+		{"unknown event", []event{{"evt_5", "payment.disputed"}}, constant.PaymentCreated, constant.OrderPending, false, 0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+			ctx := context.Background()
+
+			golang := f.course(f.category("Programming", 0), "Go Basics", 1500)
+			ann := f.user("Ann", "ann@example.com")
+
+			fake, err := payment.NewFake("secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gateway := &capturingGateway{Fake: fake}
+			uc := f.orders(gateway)
+
+			order, err := f.checkout(uc, ann, "", golang)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			intent, err := uc.Pay(ctx, ann, order.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range c.events {
+				payload, err := json.Marshal(model.PaymentEvent{Id: e.id, Type: e.eventType, PaymentId: intent.Id, Amount: intent.Amount, CreatedAt: time.Now().UTC()})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := uc.HandleWebhook(ctx, payload, fake.Sign(payload, time.Now())); err != nil {
+					t.Fatalf("HandleWebhook(%s): %v", e.eventType, err)
+				}
+			}
+
+			paid, err := f.repos.Payment.FindOne(ctx, intent.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := f.repos.Order.FindOne(ctx, order.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if paid.Status != c.wantPayment || got.Status != c.wantOrder {
+				t.Errorf("payment %s and order %s, want %s and %s", paid.Status, got.Status, c.wantPayment, c.wantOrder)
+			}
+
+			if f.enrolled(ann, golang) != c.wantEnrolled {
+				t.Errorf("enrolled = %v, want %v", !c.wantEnrolled, c.wantEnrolled)
+			}
+
+			if gateway.captured != c.wantCaptured {
+				t.Errorf("captured %d times, want %d", gateway.captured, c.wantCaptured)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRejected(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	golang := f.course(f.category("Programming", 0), "Go Basics", 1500)
+	ann := f.user("Ann", "ann@example.com")
+
+	fake, err := payment.NewFake("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := payment.NewFake("other secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uc := f.orders(fake)
+
+	order, err := f.checkout(uc, ann, "", golang)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intent, err := uc.Pay(ctx, ann, order.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(paymentID string) []byte {
+		payload, err := json.Marshal(model.PaymentEvent{Id: "evt_1", Type: constant.EventPaymentSucceeded, PaymentId: paymentID, Amount: intent.Amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return payload
+	}
+
+	for _, c := range []struct {
+		name      string
+		payload   []byte
+		signature string
+		wantErr   error
+	}{
+		{"other secret", sign(intent.Id), other.Sign(sign(intent.Id), time.Now()), model.ErrBadParamInput},
+		{"expired signature", sign(intent.Id), fake.Sign(sign(intent.Id), time.Now().Add(-time.Hour)), model.ErrBadParamInput},
+		{"changed payload", sign(intent.Id), fake.Sign(sign("pi_other"), time.Now()), model.ErrBadParamInput},
+		{"unknown payment is retried", sign("pi_other"), fake.Sign(sign("pi_other"), time.Now()), model.ErrNotFound},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if err := uc.HandleWebhook(ctx, c.payload, c.signature); !errors.Is(err, c.wantErr) {
+				t.Errorf("HandleWebhook returned %v, want %v", err, c.wantErr)
+			}
+		})
+	}
+
+	// The unknown payment event was not stored, it applies once Pay stored
+	// the payment.
+	got, err := f.repos.Order.FindOne(ctx, order.Id)
+	if err != nil || got.Status != constant.OrderPending {
+		t.Errorf("FindOne = %+v, %v, want the order pending", got, err)
+	}
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+)
+
+// percentage is the share of completed lessons, rounded down.
+func percentage(completed int, required int) int {
+	if required <= 0 {
+		return 0
+	}
+
+	if completed >= required {
+		return 100
+	}
+
+	return completed * 100 / required
+}
+
+func (e *Enrollment) OnCourseCompleted(handler CompletionHandler) {
+	e.completionMu.Lock()
+	defer e.completionMu.Unlock()
+
+	e.completion = append(e.completion, handler)
+}
+
+// SaveProgress records the progress of an enrolled user in a lesson. Clients
+// send it often, so a plain position update is a single write. Completing a
+// lesson also checks whether the course is now complete.
+func (e *Enrollment) SaveProgress(ctx context.Context, userID int, lessonID int, update model.ProgressUpdate) error {
+	ctx, span := tracer.Start(ctx, "Enrollment.SaveProgress")
+	defer span.End()
+
+	if update.Position < 0 {
+		return model.ErrBadParamInput
+	}
+
+	lesson, err := e.CourseRepo.FindLesson(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	// Quiz lessons are completed by passing the quiz, assignment lessons
+	// by the grade of the submission.
+	if update.Completed && (lesson.Type == constant.LessonQuiz || lesson.Type == constant.LessonAssignment) {
+		return model.ErrBadParamInput
+	}
+
+	_, err = e.EnrollmentRepo.FindOne(ctx, userID, lesson.CourseId)
+	if errors.Is(err, model.ErrNotFound) {
+		return model.ErrForbidden
+	}
+
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	progress := model.LessonProgress{
+		UserId:    userID,
+		LessonId:  lessonID,
+		CourseId:  lesson.CourseId,
+		Position:  update.Position,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+
+	if !update.Completed {
+		err := e.ProgressRepo.Save(ctx, progress)
+		if err != nil {
+			logger(ctx).Error(err)
+			return err
+		}
+
+		return nil
+	}
+
+	progress.CompletedAt = &now
+
+	return e.completeLesson(ctx, progress)
+}
+
+// CompleteLesson completes a lesson for an enrolled user, e.g. once the user
+// passed the quiz of the lesson.
+func (e *Enrollment) CompleteLesson(ctx context.Context, userID int, lessonID int) error {
+	ctx, span := tracer.Start(ctx, "Enrollment.CompleteLesson")
+	defer span.End()
+
+	lesson, err := e.CourseRepo.FindLesson(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return e.completeLesson(ctx, model.LessonProgress{
+		UserId:      userID,
+		LessonId:    lessonID,
+		CourseId:    lesson.CourseId,
+		StartedAt:   now,
+		CompletedAt: &now,
+		UpdatedAt:   now,
+	})
+}
+
+// completeLesson stores a completed lesson and completes the course when it
+// was the last required lesson.
+func (e *Enrollment) completeLesson(ctx context.Context, progress model.LessonProgress) error {
+	var completed *model.Enrollment
+
+	err := e.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := e.ProgressRepo.Save(ctx, progress); err != nil {
+			return err
+		}
+
+		summary, err := e.ProgressRepo.Summary(ctx, progress.UserId)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range summary {
+			if s.CourseId != progress.CourseId || s.RequiredLessons == 0 || s.CompletedLessons < s.RequiredLessons {
+				continue
+			}
+
+			done, err := e.EnrollmentRepo.Complete(ctx, progress.UserId, progress.CourseId, progress.UpdatedAt)
+			if err != nil || !done {
+				return err
+			}
+
+			completed, err = e.EnrollmentRepo.FindOne(ctx, progress.UserId, progress.CourseId)
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if completed != nil {
+		e.courseCompleted(ctx, *completed)
+	}
+
+	return nil
+}
+
+func (e *Enrollment) courseCompleted(ctx context.Context, enrollment model.Enrollment) {
+	courseCompletions.With().Inc()
+
+	logger(ctx).WithField("course_id", enrollment.CourseId).Info("course completed")
+
+	e.completionMu.RLock()
+	handlers := append([]CompletionHandler(nil), e.completion...)
+	e.completionMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, enrollment)
+	}
+}
+
+// GetCourseProgress returns the progress of an enrolled user in the course,
+// with every lesson the user started.
+func (e *Enrollment) GetCourseProgress(ctx context.Context, userID int, courseID int) (*model.CourseProgress, error) {
+	ctx, span := tracer.Start(ctx, "Enrollment.GetCourseProgress")
+	defer span.End()
+
+	summary, err := e.ProgressRepo.Summary(ctx, userID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	var result *model.CourseProgress
+	for i := range summary {
+		if summary[i].CourseId == courseID {
+			result = &summary[i]
+		}
+	}
+
+	if result == nil {
+		return nil, model.ErrNotFound
+	}
+
+	result.Lessons, err = e.ProgressRepo.FetchByCourse(ctx, userID, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if len(result.Lessons) > 0 {
+		result.Resume = &result.Lessons[0]
+	}
+
+	result.Percentage = percentage(result.CompletedLessons, result.RequiredLessons)
+
+	return result, nil
+}
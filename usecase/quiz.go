@@ -0,0 +1,513 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Quiz struct {
+	QuizRepo       repository.QuizRepository
+	CourseRepo     repository.CourseRepository
+	EnrollmentRepo repository.EnrollmentRepository
+	Transactor     repository.Transactor
+	// Lessons completes the quiz lesson once the user passes the quiz.
+	Lessons LessonCompleter
+}
+
+func NewQuiz(quizRepo repository.QuizRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, transactor repository.Transactor, lessons LessonCompleter) QuizUsecae {
+	return &Quiz{
+		QuizRepo:       quizRepo,
+		CourseRepo:     courseRepo,
+		EnrollmentRepo: enrollmentRepo,
+		Transactor:     transactor,
+		Lessons:        lessons,
+	}
+}
+
+// questionUpdate is the fields of a question a merge patch may change.
+type questionUpdate struct {
+	Type     string           `json:"type"`
+	Prompt   string           `json:"prompt"`
+	Options  []string         `json:"options"`
+	Answer   *model.AnswerKey `json:"answer"`
+	Points   int              `json:"points"`
+	Feedback string           `json:"feedback"`
+}
+
+// hideAnswers copies the questions without their answer key and feedback.
+func hideAnswers(questions []model.Question) []model.Question {
+	result := make([]model.Question, 0, len(questions))
+
+	for _, q := range questions {
+		q.Answer = nil
+		q.Feedback = ""
+		result = append(result, q)
+	}
+
+	return result
+}
+
+// expired reports whether the time of the attempt, with the grace period,
+// is over at now.
+func expired(attempt model.QuizAttempt, now time.Time) bool {
+	return attempt.ExpiresAt != nil && now.After(attempt.ExpiresAt.Add(constant.QuizSubmitGrace))
+}
+
+// enrolled returns model.ErrForbidden unless the user is enrolled in the
+// course.
+func (q *Quiz) enrolled(ctx context.Context, userID int, courseID int) error {
+	_, err := q.EnrollmentRepo.FindOne(ctx, userID, courseID)
+	if errors.Is(err, model.ErrNotFound) {
+		return model.ErrForbidden
+	}
+
+	return err
+}
+
+// GetQuiz returns the quiz of a lesson. Admins get the questions with their
+// answer keys, other users only the settings, the questions come with a
+// started attempt. Like lessons, quizzes of free previews are public.
+func (q *Quiz) GetQuiz(ctx context.Context, lessonID int, user *model.Token) (*model.Quiz, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.GetQuiz")
+	defer span.End()
+
+	lesson, err := q.CourseRepo.FindLesson(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	quiz, err := q.QuizRepo.FindQuiz(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if user != nil && user.Role == constant.RoleAdmin {
+		return quiz, nil
+	}
+
+	if !lesson.FreePreview {
+		if user == nil {
+			return nil, model.ErrForbidden
+		}
+
+		if err := q.enrolled(ctx, user.UserID, lesson.CourseId); err != nil {
+			return nil, err
+		}
+	}
+
+	quiz.Questions = nil
+
+	return quiz, nil
+}
+
+// SaveQuiz sets the quiz settings of a lesson of type quiz, creating the
+// quiz on first use.
+func (q *Quiz) SaveQuiz(ctx context.Context, lessonID int, quiz model.Quiz) (*model.Quiz, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.SaveQuiz")
+	defer span.End()
+
+	if !validQuiz(&quiz) {
+		return nil, model.ErrBadParamInput
+	}
+
+	var result *model.Quiz
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		lesson, err := q.CourseRepo.FindLesson(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		if lesson.Type != constant.LessonQuiz {
+			return fmt.Errorf("%w: lesson %d is no quiz", model.ErrBadParamInput, lessonID)
+		}
+
+		quiz.LessonId = lessonID
+
+		if err := q.QuizRepo.SaveQuiz(ctx, quiz); err != nil {
+			return err
+		}
+
+		result, err = q.QuizRepo.FindQuiz(ctx, lessonID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateQuestion appends a question to the quiz. Points default to 1.
+func (q *Quiz) CreateQuestion(ctx context.Context, lessonID int, question model.Question) (*model.Question, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.CreateQuestion")
+	defer span.End()
+
+	if question.Points == 0 {
+		question.Points = 1
+	}
+
+	if !validQuestion(question) {
+		return nil, model.ErrBadParamInput
+	}
+
+	var result *model.Question
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := q.QuizRepo.FindQuiz(ctx, lessonID); err != nil {
+			return err
+		}
+
+		question.LessonId = lessonID
+
+		id, err := q.QuizRepo.StoreQuestion(ctx, question)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.QuizRepo.FindQuestion(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateQuestion applies a JSON Merge Patch to the question. Attempts
+// submitted before keep their grade.
+func (q *Quiz) UpdateQuestion(ctx context.Context, patch []byte, questionID int) (*model.Question, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.UpdateQuestion")
+	defer span.End()
+
+	var result *model.Question
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := q.QuizRepo.FindQuestion(ctx, questionID)
+		if err != nil {
+			return err
+		}
+
+		update := questionUpdate{
+			Type:     current.Type,
+			Prompt:   current.Prompt,
+			Options:  current.Options,
+			Answer:   current.Answer,
+			Points:   current.Points,
+			Feedback: current.Feedback,
+		}
+		if err := applyPatch(&update, patch); err != nil {
+			return err
+		}
+
+		current.Type = update.Type
+		current.Prompt = update.Prompt
+		current.Options = update.Options
+		current.Answer = update.Answer
+		current.Points = update.Points
+		current.Feedback = update.Feedback
+
+		if !validQuestion(*current) {
+			return model.ErrBadParamInput
+		}
+
+		if err := q.QuizRepo.UpdateQuestion(ctx, *current); err != nil {
+			return err
+		}
+
+		result, err = q.QuizRepo.FindQuestion(ctx, questionID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (q *Quiz) DeleteQuestion(ctx context.Context, questionID int) error {
+	ctx, span := tracer.Start(ctx, "Quiz.DeleteQuestion")
+	defer span.End()
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := q.QuizRepo.FindQuestion(ctx, questionID); err != nil {
+			return err
+		}
+
+		return q.QuizRepo.DeleteQuestion(ctx, questionID)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ReorderQuestion puts the questions of the quiz in the order of
+// questionIDs, which must list every question of the quiz once.
+func (q *Quiz) ReorderQuestion(ctx context.Context, lessonID int, questionIDs []int) (*model.Quiz, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.ReorderQuestion")
+	defer span.End()
+
+	var result *model.Quiz
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		quiz, err := q.QuizRepo.FindQuiz(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		current := make([]int, 0, len(quiz.Questions))
+		for _, v := range quiz.Questions {
+			current = append(current, v.Id)
+		}
+
+		if !samePermutation(questionIDs, current) {
+			return model.ErrBadParamInput
+		}
+
+		if err := q.QuizRepo.ReorderQuestion(ctx, lessonID, questionIDs); err != nil {
+			return err
+		}
+
+		result, err = q.QuizRepo.FindQuiz(ctx, lessonID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// StartAttempt starts an attempt of an enrolled user at the quiz, or
+// returns the attempt the user has not submitted yet while there is time
+// left. Attempts left open past their time limit count towards the limit.
+func (q *Quiz) StartAttempt(ctx context.Context, userID int, lessonID int) (*model.QuizAttempt, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.StartAttempt")
+	defer span.End()
+
+	quiz, result, err := q.startAttempt(ctx, userID, lessonID)
+	if errors.Is(err, model.ErrConflict) {
+		// A concurrent start took the attempt number. Trying again returns
+		// the attempt it started, or finds the limit reached.
+		quiz, result, err = q.startAttempt(ctx, userID, lessonID)
+	}
+
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	result.Questions = hideAnswers(quiz.Questions)
+
+	return result, nil
+}
+
+// startAttempt returns the open attempt of the user or stores the next
+// one. The attempt number is unique per user and quiz, so of concurrent
+// starts only one stores an attempt and the others get model.ErrConflict.
+func (q *Quiz) startAttempt(ctx context.Context, userID int, lessonID int) (quiz *model.Quiz, result *model.QuizAttempt, err error) {
+	err = q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		quiz, err = q.QuizRepo.FindQuiz(ctx, lessonID)
+		if err != nil {
+			return err
+		}
+
+		if err := q.enrolled(ctx, userID, quiz.CourseId); err != nil {
+			return err
+		}
+
+		if len(quiz.Questions) == 0 {
+			return fmt.Errorf("%w: quiz %d has no questions", model.ErrBadParamInput, lessonID)
+		}
+
+		attempts, err := q.QuizRepo.FetchAttempt(ctx, userID, lessonID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		for i := range attempts {
+			if attempts[i].SubmittedAt == nil && !expired(attempts[i], now) {
+				result = &attempts[i]
+				return nil
+			}
+		}
+
+		if quiz.MaxAttempts > 0 && len(attempts) >= quiz.MaxAttempts {
+			return model.ErrNoAttemptLeft
+		}
+
+		attempt := model.QuizAttempt{
+			UserId:    userID,
+			LessonId:  lessonID,
+			CourseId:  quiz.CourseId,
+			Number:    len(attempts) + 1,
+			StartedAt: now,
+		}
+
+		if quiz.TimeLimit > 0 {
+			expiresAt := now.Add(time.Duration(quiz.TimeLimit) * time.Second)
+			attempt.ExpiresAt = &expiresAt
+		}
+
+		id, err := q.QuizRepo.StoreAttempt(ctx, attempt)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.QuizRepo.FindAttempt(ctx, id)
+
+		return err
+	})
+
+	return quiz, result, err
+}
+
+// SubmitAttempt grades the responses of the attempt on the server and
+// returns the result of every question. Submissions after the time limit
+// and the grace period are graded without responses. Submitting again
+// returns the graded attempt. Once the score counted by the score policy
+// reaches the pass score, the quiz lesson is completed.
+func (q *Quiz) SubmitAttempt(ctx context.Context, userID int, attemptID int, submission model.QuizSubmission) (*model.QuizAttempt, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.SubmitAttempt")
+	defer span.End()
+
+	var (
+		result *model.QuizAttempt
+		graded bool
+		passed bool
+	)
+
+	err := q.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		attempt, err := q.QuizRepo.FindAttempt(ctx, attemptID)
+		if err != nil {
+			return err
+		}
+
+		if attempt.UserId != userID {
+			return fmt.Errorf("%w: attempt %d of another user", model.ErrNotFound, attemptID)
+		}
+
+		result = attempt
+
+		if attempt.SubmittedAt != nil {
+			return nil
+		}
+
+		quiz, err := q.QuizRepo.FindQuiz(ctx, attempt.LessonId)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		responses := submission.Responses
+		if expired(*attempt, now) {
+			responses = nil
+		}
+
+		grade(*quiz, attempt, responses)
+		attempt.SubmittedAt = &now
+
+		done, err := q.QuizRepo.SubmitAttempt(ctx, *attempt)
+		if err != nil {
+			return err
+		}
+
+		if !done {
+			// Submitted concurrently, return that grade.
+			result, err = q.QuizRepo.FindAttempt(ctx, attemptID)
+			return err
+		}
+
+		attempts, err := q.QuizRepo.FetchAttempt(ctx, userID, attempt.LessonId)
+		if err != nil {
+			return err
+		}
+
+		score, _ := policyScore(quiz.ScorePolicy, attempts)
+		graded, passed = true, score >= quiz.PassScore
+
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if !graded {
+		return result, nil
+	}
+
+	if result.Passed {
+		quizAttempts.With("passed").Inc()
+	} else {
+		quizAttempts.With("failed").Inc()
+	}
+
+	if passed {
+		if err := q.Lessons.CompleteLesson(ctx, userID, result.LessonId); err != nil {
+			logger(ctx).Error(err)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetQuizStatus returns the attempts of the user at the quiz, oldest first,
+// and the score counted by the score policy.
+func (q *Quiz) GetQuizStatus(ctx context.Context, userID int, lessonID int) (*model.QuizStatus, error) {
+	ctx, span := tracer.Start(ctx, "Quiz.GetQuizStatus")
+	defer span.End()
+
+	quiz, err := q.QuizRepo.FindQuiz(ctx, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	attempts, err := q.QuizRepo.FetchAttempt(ctx, userID, lessonID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	result := &model.QuizStatus{
+		LessonId:    lessonID,
+		ScorePolicy: quiz.ScorePolicy,
+		PassScore:   quiz.PassScore,
+		MaxAttempts: quiz.MaxAttempts,
+		Attempts:    attempts,
+	}
+
+	if score, ok := policyScore(quiz.ScorePolicy, attempts); ok {
+		result.Score = &score
+		result.Passed = score >= quiz.PassScore
+	}
+
+	return result, nil
+}
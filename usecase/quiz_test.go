@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+// racingQuizRepo plays a concurrent start of the same user that stores
+// its attempt first.
+type racingQuizRepo struct {
+	repository.QuizRepository
+	stored  int
+	started *model.QuizAttempt
+}
+
+func (r *racingQuizRepo) StoreAttempt(ctx context.Context, attempt model.QuizAttempt) (int, error) {
+	r.stored++
+
+	if r.started == nil {
+		r.started = &model.QuizAttempt{Id: 99, UserId: attempt.UserId, LessonId: attempt.LessonId, CourseId: attempt.CourseId, Number: attempt.Number, StartedAt: attempt.StartedAt}
+		return 0, model.ErrConflict
+	}
+
+	return r.QuizRepository.StoreAttempt(ctx, attempt)
+}
+
+func (r *racingQuizRepo) FetchAttempt(ctx context.Context, userID int, lessonID int) ([]model.QuizAttempt, error) {
+	attempts, err := r.QuizRepository.FetchAttempt(ctx, userID, lessonID)
+	if err == nil && r.started != nil {
+		attempts = append(attempts, *r.started)
+	}
+
+	return attempts, err
+}
+
+func TestStartAttempt(t *testing.T) {
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		name        string
+		maxAttempts int
+		submitted   int
+		racing      bool
+		wantNumber  int
+		wantErr     error
+	}{
+		{name: "first", maxAttempts: 2, wantNumber: 1},
+		{name: "next", maxAttempts: 2, submitted: 1, wantNumber: 2},
+		{name: "no attempt left", maxAttempts: 2, submitted: 2, wantErr: model.ErrNoAttemptLeft},
+		{name: "unlimited", submitted: 5, wantNumber: 6},
+		{name: "concurrent start", maxAttempts: 1, racing: true, wantNumber: 1},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			courseID := f.course(f.category("Programming", 0), "Go Basics", 0)
+			lessonID := f.lesson(courseID, constant.LessonQuiz)
+			ann := f.user("Ann", "ann@example.com")
+			f.enroll(ann, courseID)
+
+			if err := f.repos.Quiz.SaveQuiz(ctx, model.Quiz{LessonId: lessonID, MaxAttempts: c.maxAttempts, PassScore: 100, ScorePolicy: constant.ScoreBest}); err != nil {
+				t.Fatal(err)
+			}
+
+			truth := true
+			if _, err := f.repos.Quiz.StoreQuestion(ctx, model.Question{LessonId: lessonID, Type: constant.QuestionTrueFalse, Prompt: "Go has generics.", Answer: &model.AnswerKey{Truth: &truth}, Points: 1}); err != nil {
+				t.Fatal(err)
+			}
+
+			at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+			for i := 1; i <= c.submitted; i++ {
+				id, err := f.repos.Quiz.StoreAttempt(ctx, model.QuizAttempt{UserId: ann, LessonId: lessonID, CourseId: courseID, Number: i, StartedAt: at})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := f.repos.Quiz.SubmitAttempt(ctx, model.QuizAttempt{Id: id, SubmittedAt: &at}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			racing := &racingQuizRepo{QuizRepository: f.repos.Quiz}
+
+			quizRepo := f.repos.Quiz
+			if c.racing {
+				quizRepo = racing
+			}
+
+			uc := NewQuiz(quizRepo, f.repos.Course, f.repos.Enrollment, f.repos.Transactor, nil)
+
+			attempt, err := uc.StartAttempt(ctx, ann, lessonID)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("StartAttempt returned %v, want %v", err, c.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if attempt.Number != c.wantNumber || len(attempt.Questions) != 1 || attempt.Questions[0].Answer != nil {
+				t.Errorf("StartAttempt = %+v, want attempt %d with the questions without answers", attempt, c.wantNumber)
+			}
+
+			// The concurrent start stored the only attempt allowed, which
+			// is returned instead of storing a second one.
+			if c.racing && (racing.stored != 1 || attempt.Id != racing.started.Id) {
+				t.Errorf("StartAttempt stored %d attempts and returned %+v, want the concurrent attempt %d", racing.stored, attempt, racing.started.Id)
+			}
+
+			again, err := uc.StartAttempt(ctx, ann, lessonID)
+			if err != nil || again.Id != attempt.Id {
+				t.Errorf("StartAttempt again = %+v, %v, want the open attempt %d", again, err, attempt.Id)
+			}
+		})
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	yes, no := true, false
+	pi, near, e := 3.14, 3.145, 2.71
+
+	single := model.Question{Type: constant.QuestionSingleChoice, Options: []string{"a", "b", "c"}, Answer: &model.AnswerKey{Choices: []int{1}}}
+	multiple := model.Question{Type: constant.QuestionMultipleChoice, Options: []string{"a", "b", "c"}, Answer: &model.AnswerKey{Choices: []int{0, 2}}}
+	truth := model.Question{Type: constant.QuestionTrueFalse, Answer: &model.AnswerKey{Truth: &yes}}
+	short := model.Question{Type: constant.QuestionShortAnswer, Answer: &model.AnswerKey{Texts: []string{"Go Routine", "goroutine"}}}
+	numeric := model.Question{Type: constant.QuestionNumeric, Answer: &model.AnswerKey{Number: &pi, Tolerance: 0.01}}
+
+	for _, c := range []struct {
+		name     string
+		question model.Question
+		response model.Response
+		want     bool
+	}{
+		{"single choice", single, model.Response{Choices: []int{1}}, true},
+		{"single choice wrong", single, model.Response{Choices: []int{0}}, false},
+		{"single choice two picks", single, model.Response{Choices: []int{1, 0}}, false},
+		{"single choice none", single, model.Response{}, false},
+		{"multiple choice any order", multiple, model.Response{Choices: []int{2, 0}}, true},
+		{"multiple choice partly", multiple, model.Response{Choices: []int{0}}, false},
+		{"multiple choice extra", multiple, model.Response{Choices: []int{0, 1, 2}}, false},
+		{"true false", truth, model.Response{Truth: &yes}, true},
+		{"true false wrong", truth, model.Response{Truth: &no}, false},
+		{"true false none", truth, model.Response{}, false},
+		{"short answer case and spaces", short, model.Response{Text: "  go   ROUTINE "}, true},
+		{"short answer other text", short, model.Response{Text: "GOROUTINE"}, true},
+		{"short answer wrong", short, model.Response{Text: "thread"}, false},
+		{"short answer empty", short, model.Response{Text: "   "}, false},
+		{"numeric within tolerance", numeric, model.Response{Number: &near}, true},
+		{"numeric outside tolerance", numeric, model.Response{Number: &e}, false},
+		{"numeric none", numeric, model.Response{}, false},
+		{"unknown type", model.Question{Type: "essay", Answer: &model.AnswerKey{}}, model.Response{Text: "a"}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := correct(c.question, c.response); got != c.want {
+				t.Errorf("correct(%+v) = %v, want %v", c.response, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPolicyScore(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	submitted := func(percentage int) model.QuizAttempt {
+		return model.QuizAttempt{Percentage: percentage, SubmittedAt: &at}
+	}
+
+	open := model.QuizAttempt{Percentage: 100}
+
+	for _, c := range []struct {
+		name      string
+		policy    string
+		attempts  []model.QuizAttempt
+		wantScore int
+		wantFound bool
+	}{
+		{"best none", constant.ScoreBest, nil, 0, false},
+		{"best only open", constant.ScoreBest, []model.QuizAttempt{open}, 0, false},
+		{"best", constant.ScoreBest, []model.QuizAttempt{submitted(40), submitted(80), submitted(60)}, 80, true},
+		{"best zero", constant.ScoreBest, []model.QuizAttempt{submitted(0)}, 0, true},
+		{"best skips open", constant.ScoreBest, []model.QuizAttempt{submitted(40), open}, 40, true},
+		{"latest", constant.ScoreLatest, []model.QuizAttempt{submitted(80), submitted(40)}, 40, true},
+		{"latest skips open", constant.ScoreLatest, []model.QuizAttempt{submitted(80), open}, 80, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			score, found := policyScore(c.policy, c.attempts)
+			if score != c.wantScore || found != c.wantFound {
+				t.Errorf("policyScore = %d, %v, want %d, %v", score, found, c.wantScore, c.wantFound)
+			}
+		})
+	}
+}
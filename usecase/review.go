@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/egaevan/online-learning/constant"
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository"
+)
+
+type Review struct {
+	ReviewRepo     repository.ReviewRepository
+	CourseRepo     repository.CourseRepository
+	EnrollmentRepo repository.EnrollmentRepository
+	Transactor     repository.Transactor
+	// Notifier tells students about replies to their reviews.
+	Notifier Notifier
+}
+
+func NewReview(reviewRepo repository.ReviewRepository, courseRepo repository.CourseRepository, enrollmentRepo repository.EnrollmentRepository, transactor repository.Transactor, notifier Notifier) ReviewUsecae {
+	return &Review{
+		ReviewRepo:     reviewRepo,
+		CourseRepo:     courseRepo,
+		EnrollmentRepo: enrollmentRepo,
+		Transactor:     transactor,
+		Notifier:       notifier,
+	}
+}
+
+// reviewText trims the text of a review or reply and reports whether it
+// is short enough.
+func reviewText(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+
+	return s, utf8.RuneCountInString(s) <= constant.MaxReviewLength
+}
+
+// visibleReview returns the review unless it is hidden from the user.
+// Only admins see the flags.
+func visibleReview(review *model.Review, user *model.Token) (*model.Review, error) {
+	if user != nil && user.Role == constant.RoleAdmin {
+		return review, nil
+	}
+
+	if review.Hidden && (user == nil || user.UserID != review.UserId) {
+		return nil, fmt.Errorf("%w: review %d is hidden", model.ErrNotFound, review.Id)
+	}
+
+	review.FlagCount = 0
+
+	return review, nil
+}
+
+// GetReview returns the reviews of the course, newest first. Hidden
+// reviews are left out, except for admins and for their author.
+func (r *Review) GetReview(ctx context.Context, courseID int, user *model.Token) ([]model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.GetReview")
+	defer span.End()
+
+	if _, err := r.CourseRepo.FindOne(ctx, courseID); err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	reviews, err := r.ReviewRepo.FetchByCourse(ctx, courseID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	result := make([]model.Review, 0, len(reviews))
+
+	for i := range reviews {
+		if v, err := visibleReview(&reviews[i], user); err == nil {
+			result = append(result, *v)
+		}
+	}
+
+	return result, nil
+}
+
+// SaveReview creates the review of an enrolled user for the course or
+// edits it, and updates the course rating.
+func (r *Review) SaveReview(ctx context.Context, userID int, courseID int, req model.ReviewRequest) (*model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.SaveReview")
+	defer span.End()
+
+	body, ok := reviewText(req.Body)
+	if !ok || req.Rating < constant.MinRating || req.Rating > constant.MaxRating {
+		return nil, fmt.Errorf("%w: rating %d, review of %d characters", model.ErrBadParamInput, req.Rating, utf8.RuneCountInString(body))
+	}
+
+	var result *model.Review
+
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.CourseRepo.FindOne(ctx, courseID); err != nil {
+			return err
+		}
+
+		if _, err := r.EnrollmentRepo.FindOne(ctx, userID, courseID); err != nil {
+			if errors.Is(err, model.ErrNotFound) {
+				return fmt.Errorf("%w: user %d is not enrolled in course %d", model.ErrForbidden, userID, courseID)
+			}
+
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+
+		existing, err := r.ReviewRepo.FindByUser(ctx, userID, courseID)
+		switch {
+		case err == nil:
+			existing.Rating = req.Rating
+			existing.Body = body
+			existing.UpdatedAt = now
+			err = r.ReviewRepo.Update(ctx, *existing)
+		case errors.Is(err, model.ErrNotFound):
+			_, err = r.ReviewRepo.Store(ctx, model.Review{
+				CourseId:  courseID,
+				UserId:    userID,
+				Rating:    req.Rating,
+				Body:      body,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := r.ReviewRepo.RecomputeRating(ctx, courseID); err != nil {
+			return err
+		}
+
+		result, err = r.ReviewRepo.FindByUser(ctx, userID, courseID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	result.FlagCount = 0
+
+	return result, nil
+}
+
+// DeleteReview removes the review of the user for the course and updates
+// the course rating.
+func (r *Review) DeleteReview(ctx context.Context, userID int, courseID int) error {
+	ctx, span := tracer.Start(ctx, "Review.DeleteReview")
+	defer span.End()
+
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		review, err := r.ReviewRepo.FindByUser(ctx, userID, courseID)
+		if err != nil {
+			return err
+		}
+
+		if err := r.ReviewRepo.Delete(ctx, review.Id); err != nil {
+			return err
+		}
+
+		return r.ReviewRepo.RecomputeRating(ctx, courseID)
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ReplyReview sets the reply of the instructor to a review, replying again
+// replaces it. The author of the review is notified.
+func (r *Review) ReplyReview(ctx context.Context, userID int, reviewID int, req model.ReplyRequest) (*model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.ReplyReview")
+	defer span.End()
+
+	body, ok := reviewText(req.Body)
+	if !ok || body == "" {
+		return nil, fmt.Errorf("%w: reply of %d characters", model.ErrBadParamInput, utf8.RuneCountInString(body))
+	}
+
+	var result *model.Review
+
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.ReviewRepo.FindOne(ctx, reviewID); err != nil {
+			return err
+		}
+
+		err := r.ReviewRepo.SaveReply(ctx, reviewID, &model.ReviewReply{
+			Body:      body,
+			RepliedBy: userID,
+			RepliedAt: time.Now().UTC().Truncate(time.Second),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = r.ReviewRepo.FindOne(ctx, reviewID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	// The reply is stored, a lost notification must not fail the request.
+	err = r.Notifier.Notify(ctx, model.Notification{
+		UserId:  result.UserId,
+		Type:    constant.NotificationReviewReply,
+		Subject: "The instructor replied to your review",
+		Body:    body,
+		Link:    fmt.Sprintf("/course/%d/review", result.CourseId),
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+	}
+
+	return result, nil
+}
+
+// DeleteReply removes the reply to a review.
+func (r *Review) DeleteReply(ctx context.Context, reviewID int) error {
+	ctx, span := tracer.Start(ctx, "Review.DeleteReply")
+	defer span.End()
+
+	if _, err := r.ReviewRepo.FindOne(ctx, reviewID); err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if err := r.ReviewRepo.SaveReply(ctx, reviewID, nil); err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// VoteReview marks a review of another user as helpful, or takes the vote
+// back. Voting twice counts once.
+func (r *Review) VoteReview(ctx context.Context, user *model.Token, reviewID int, helpful bool) (*model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.VoteReview")
+	defer span.End()
+
+	review, err := r.ReviewRepo.FindOne(ctx, reviewID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	if _, err := visibleReview(review, user); err != nil {
+		return nil, err
+	}
+
+	if review.UserId == user.UserID {
+		return nil, fmt.Errorf("%w: users can't vote for their own review", model.ErrForbidden)
+	}
+
+	if helpful {
+		err = r.ReviewRepo.Vote(ctx, reviewID, user.UserID, time.Now().UTC().Truncate(time.Second))
+	} else {
+		err = r.ReviewRepo.Unvote(ctx, reviewID, user.UserID)
+	}
+
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	review, err = r.ReviewRepo.FindOne(ctx, reviewID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return visibleReview(review, user)
+}
+
+// FlagReview reports a review of another user to the moderators. Flagging
+// it again replaces the reason.
+func (r *Review) FlagReview(ctx context.Context, user *model.Token, reviewID int, req model.FlagRequest) error {
+	ctx, span := tracer.Start(ctx, "Review.FlagReview")
+	defer span.End()
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > 255 {
+		return fmt.Errorf("%w: flag reason must have 1 to 255 characters", model.ErrBadParamInput)
+	}
+
+	review, err := r.ReviewRepo.FindOne(ctx, reviewID)
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	if _, err := visibleReview(review, user); err != nil {
+		return err
+	}
+
+	if review.UserId == user.UserID {
+		return fmt.Errorf("%w: users can't flag their own review", model.ErrForbidden)
+	}
+
+	err = r.ReviewRepo.Flag(ctx, model.ReviewFlag{
+		ReviewId:  reviewID,
+		UserId:    user.UserID,
+		Reason:    req.Reason,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return err
+	}
+
+	reviewFlags.With().Inc()
+
+	return nil
+}
+
+// ModerateReview hides a review or shows it again, which resolves its
+// flags, and updates the course rating.
+func (r *Review) ModerateReview(ctx context.Context, reviewID int, req model.ModerationRequest) (*model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.ModerateReview")
+	defer span.End()
+
+	var result *model.Review
+
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		review, err := r.ReviewRepo.FindOne(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		if err := r.ReviewRepo.SetHidden(ctx, reviewID, req.Hidden); err != nil {
+			return err
+		}
+
+		if err := r.ReviewRepo.RecomputeRating(ctx, review.CourseId); err != nil {
+			return err
+		}
+
+		result, err = r.ReviewRepo.FindOne(ctx, reviewID)
+
+		return err
+	})
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetFlaggedReview returns the reviews waiting for moderation, most
+// flagged first.
+func (r *Review) GetFlaggedReview(ctx context.Context) ([]model.Review, error) {
+	ctx, span := tracer.Start(ctx, "Review.GetFlaggedReview")
+	defer span.End()
+
+	result, err := r.ReviewRepo.FetchFlagged(ctx)
+	if err != nil {
+		logger(ctx).Error(err)
+		return nil, err
+	}
+
+	return result, nil
+}
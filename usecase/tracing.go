@@ -0,0 +1,6 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts one span per usecase call, named Type.Method.
+var tracer = otel.Tracer("github.com/egaevan/online-learning/usecase")
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egaevan/online-learning/model"
+	"github.com/egaevan/online-learning/repository/repotest"
+)
+
+// fixture seeds in-memory repositories for the usecase tests.
+type fixture struct {
+	t     *testing.T
+	repos repotest.Repositories
+}
+
+func newFixture(t *testing.T) *fixture {
+	return &fixture{t: t, repos: repotest.Memory(t)}
+}
+
+func (f *fixture) category(name string, parentID int) int {
+	f.t.Helper()
+
+	id, err := f.repos.Course.StoreCategory(context.Background(), model.Category{Name: name, ParentId: parentID})
+	if err != nil {
+		f.t.Fatalf("StoreCategory(%s): %v", name, err)
+	}
+
+	return id
+}
+
+func (f *fixture) course(categoryID int, name string, price int) int {
+	f.t.Helper()
+
+	id, err := f.repos.Course.Store(context.Background(), model.Course{CategoryId: categoryID, Name: name, Price: price})
+	if err != nil {
+		f.t.Fatalf("Store(%s): %v", name, err)
+	}
+
+	return id
+}
+
+func (f *fixture) user(name string, email string) int {
+	f.t.Helper()
+
+	ctx := context.Background()
+
+	if err := f.repos.User.Store(ctx, model.User{Name: name, Email: email, Password: "secret", Role: 2}); err != nil {
+		f.t.Fatalf("Store(%s): %v", email, err)
+	}
+
+	user, err := f.repos.User.FindOne(ctx, email)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return user.Id
+}
+
+// review stores a visible review and recomputes the rating of its course.
+func (f *fixture) review(userID int, courseID int, rating int) {
+	f.t.Helper()
+
+	ctx := context.Background()
+
+	if _, err := f.repos.Review.Store(ctx, model.Review{UserId: userID, CourseId: courseID, Rating: rating}); err != nil {
+		f.t.Fatal(err)
+	}
+
+	if err := f.repos.Review.RecomputeRating(ctx, courseID); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// lesson stores a lesson of the given type in a new section of the course.
+func (f *fixture) lesson(courseID int, lessonType string) int {
+	f.t.Helper()
+
+	ctx := context.Background()
+
+	sectionID, err := f.repos.Course.StoreSection(ctx, model.Section{CourseId: courseID, Title: "Intro"})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	id, err := f.repos.Course.StoreLesson(ctx, model.Lesson{SectionId: sectionID, Title: lessonType, Type: lessonType, Content: "Content"})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return id
+}
+
+func (f *fixture) enroll(userID int, courseID int) {
+	f.t.Helper()
+
+	if _, err := f.repos.Enrollment.Store(context.Background(), model.Enrollment{UserId: userID, CourseId: courseID, CreatedAt: time.Now().UTC()}); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// enrolled reports whether the user is enrolled in the course.
+func (f *fixture) enrolled(userID int, courseID int) bool {
+	f.t.Helper()
+
+	_, err := f.repos.Enrollment.FindOne(context.Background(), userID, courseID)
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		f.t.Fatal(err)
+	}
+
+	return err == nil
+}
+
+func (f *fixture) orders(gateway PaymentGateway) *Order {
+	return NewOrder(f.repos.Cart, f.repos.Order, f.repos.Payment, f.repos.Coupon, f.repos.Course, f.repos.Enrollment, f.repos.Transactor, gateway, "USD").(*Order)
+}
+
+// checkout puts the courses into the cart of the user and checks it out
+// with the coupon code.
+func (f *fixture) checkout(uc *Order, userID int, coupon string, courseIDs ...int) (*model.Order, error) {
+	f.t.Helper()
+
+	ctx := context.Background()
+
+	for _, id := range courseIDs {
+		if _, err := uc.AddToCart(ctx, userID, model.CartRequest{CourseId: id}); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+
+	return uc.Checkout(ctx, userID, model.CheckoutRequest{Coupon: coupon})
+}
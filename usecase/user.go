@@ -2,36 +2,82 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
 
 	"github.com/egaevan/online-learning/model"
 	"github.com/egaevan/online-learning/repository"
-	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
 	UserRepo repository.UserRepository
+	JWT      model.JWTConfig
 }
 
-func NewUser(userRepo repository.UserRepository) UserUsecae {
+func NewUser(userRepo repository.UserRepository, jwtConfig model.JWTConfig) UserUsecae {
 	return &User{
 		UserRepo: userRepo,
+		JWT:      jwtConfig,
 	}
 }
 
 func (u *User) Login(ctx context.Context, user model.User) (model.User, error) {
-	user, err := u.UserRepo.FindOne(ctx, user.Email, user.Password)
+	ctx, span := tracer.Start(ctx, "User.Login")
+	defer span.End()
+
+	password := user.Password
+
+	user, err := u.UserRepo.FindOne(ctx, user.Email)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
+		loginTotal.With("failure").Inc()
 		return user, err
 	}
 
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil { //Password does not match!
+		loginTotal.With("failure").Inc()
+		return model.User{}, errors.New("invalid password")
+	}
+
+	expiresAt := time.Now().Add(time.Duration(u.JWT.Expiration)).Unix()
+
+	tk := &model.Token{
+		UserID: user.Id,
+		Name:   user.Name,
+		Email:  user.Email,
+		Role:   user.Role,
+		StandardClaims: &jwt.StandardClaims{
+			ExpiresAt: expiresAt,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tk)
+
+	tokenString, err := token.SignedString([]byte(u.JWT.Secret.Value()))
+	if err != nil {
+		logger(ctx).Error(err)
+		return model.User{}, err
+	}
+
+	user.Password = ""
+	user.Token = tokenString
+
+	loginTotal.With("success").Inc()
+
 	return user, nil
 }
 
 func (u *User) CreateUser(ctx context.Context, user model.User) error {
+	ctx, span := tracer.Start(ctx, "User.CreateUser")
+	defer span.End()
+
 	err := u.UserRepo.Store(ctx, user)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return err
 	}
 
@@ -39,10 +85,12 @@ func (u *User) CreateUser(ctx context.Context, user model.User) error {
 }
 
 func (u *User) DeleteUser(ctx context.Context, userID int) error {
+	ctx, span := tracer.Start(ctx, "User.DeleteUser")
+	defer span.End()
 
 	err := u.UserRepo.Delete(ctx, userID)
 	if err != nil {
-		log.Error(err)
+		logger(ctx).Error(err)
 		return err
 	}
 